@@ -10,16 +10,20 @@ import (
 	"strconv"
 	"syscall"
 	"text/tabwriter"
+	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-version"
 	"github.com/ktr0731/evans/adapter/cli"
 	"github.com/ktr0731/evans/adapter/cui"
+	"github.com/ktr0731/evans/adapter/grpc"
 	"github.com/ktr0731/evans/adapter/repl"
 	"github.com/ktr0731/evans/cache"
 	"github.com/ktr0731/evans/config"
 	"github.com/ktr0731/evans/logger"
 	"github.com/ktr0731/evans/meta"
+	"github.com/ktr0731/evans/usecase"
+	"github.com/ktr0731/evans/usecase/port"
 	updater "github.com/ktr0731/go-updater"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
@@ -57,10 +61,35 @@ func (c *Command) parseFlags(args []string) *options {
 	f.StringVar(&opts.service, "service", "", "default service")
 	f.StringVar(&opts.call, "call", "", "call specified RPC by CLI mode")
 	f.StringVarP(&opts.file, "file", "f", "", "a script file that will be executed by (used only CLI mode)")
+	f.StringVar(&opts.record, "record", "", "record RPC calls in REPL mode to the specified file")
+	f.StringVar(&opts.replay, "replay", "", "replay RPC calls recorded by --record, then report differences (used only CLI mode)")
+	f.StringVar(&opts.test, "test", "", "run a test suite which is written in YAML or TOML (used only CLI mode)")
+	f.StringVar(&opts.script, "script", "", "send requests of the bidirectional streaming RPC specified by --call according to the script which is written in YAML or TOML (used only CLI mode)")
+	f.StringVar(&opts.junit, "junit", "", "write the result of --test to the specified file as JUnit XML")
+	f.StringVar(&opts.template, "template", "", "print an example JSON document of the message which can be used as a request (used only CLI mode)")
 	f.StringSliceVar(&opts.path, "path", nil, "proto file paths")
 	f.StringToStringVar(&opts.header, "header", nil, "default headers that set to each requests (example: foo=bar)")
 	f.BoolVar(&opts.web, "web", false, "use gRPC Web protocol")
 	f.BoolVarP(&opts.reflection, "reflection", "r", false, "use gRPC reflection")
+	f.DurationVar(&opts.timeout, "timeout", 0, "the timeout of each RPC call (e.g. 2s). 0 means no timeout")
+	f.StringVar(&opts.compression, "compression", "", "compress request messages by the compressor (e.g. gzip)")
+	f.IntVar(&opts.maxSendMsgSize, "max-send-msg-size", 0, "the max size in bytes of a request message. 0 means the default limit of gRPC")
+	f.IntVar(&opts.maxRecvMsgSize, "max-recv-msg-size", 0, "the max size in bytes of a response message. 0 means the default limit of gRPC")
+	f.BoolVar(&opts.bench, "bench", false, "call the RPC specified by --call repeatedly, then report its performance")
+	f.IntVar(&opts.benchConcurrency, "bench-concurrency", usecase.DefaultBenchConcurrency, "the number of workers which call the RPC concurrently (used only with --bench)")
+	f.IntVar(&opts.benchCount, "bench-count", 0, "the number of RPC calls. if both of --bench-count and --bench-duration are 0, 200 is used (used only with --bench)")
+	f.DurationVar(&opts.benchDuration, "bench-duration", 0, "the duration of the load test (e.g. 10s) (used only with --bench)")
+	f.IntVar(&opts.benchQPS, "bench-qps", 0, "the rate limit of RPC calls per second. 0 means no limit (used only with --bench)")
+	f.IntVar(&opts.stream.MaxMessages, "stream-max-messages", 0, "stop receiving streamed responses after n messages. 0 means no limit (used only with --call)")
+	f.DurationVar(&opts.stream.Duration, "stream-duration", 0, "stop receiving streamed responses after the duration (e.g. 10s). 0 means no limit (used only with --call)")
+	f.BoolVar(&opts.stream.ShowTimestamp, "stream-timestamp", false, "show the sequence number and the receive time of each streamed response (used only with --call)")
+	f.BoolVar(&opts.stream.ShowSummary, "stream-summary", false, "show the summary of streamed responses when the stream is finished (used only with --call)")
+	f.StringVar(&opts.filter, "filter", "", "a jq-style expression which extracts values from each response (e.g. '.items[].name') (used only with --call)")
+	f.StringVar(&opts.output, "output", "json", "the format of response messages (json, yaml, table, text, binary, hex or base64)")
+	f.StringVar(&opts.outputTemplate, "output-template", "", "a Go template which formats each response (e.g. '{{.id}},{{.name}}'). it overrides --output")
+	f.StringSliceVar(&opts.columns, "columns", nil, "the columns of repeated message fields which are shown (used only with --output table)")
+	f.BoolVar(&opts.showMetadata, "show-metadata", false, "show header and trailer metadata of responses")
+	f.BoolVar(&opts.showElapsedTime, "show-elapsed-time", false, "show the elapsed time of each RPC call")
 	f.BoolVar(&opts.verbose, "verbose", false, "verbose output")
 	f.BoolVarP(&opts.tls, "tls", "t", false, "use a secure TLS connection")
 	f.StringVar(&opts.cacert, "cacert", "", "the CA certificate file for verifying the server")
@@ -120,6 +149,12 @@ type options struct {
 	service    string
 	call       string
 	file       string
+	record     string
+	replay     string
+	test       string
+	junit      string
+	script     string
+	template   string
 	path       []string
 	header     map[string]string
 	web        bool
@@ -130,6 +165,29 @@ type options struct {
 	certKey    string
 	serverName string
 	insecure   bool
+	timeout    time.Duration
+
+	compression    string
+	maxSendMsgSize int
+	maxRecvMsgSize int
+
+	output          string
+	columns         []string
+	outputTemplate  string
+	showMetadata    bool
+	showElapsedTime bool
+
+	// bench options
+	bench            bool
+	benchConcurrency int
+	benchCount       int
+	benchDuration    time.Duration
+	benchQPS         int
+
+	// stream options
+	stream port.StreamParams
+
+	filter string
 
 	// meta options
 	verbose bool
@@ -152,6 +210,38 @@ type wrappedConfig struct {
 	// if input is stdin, file is empty
 	file string
 
+	// used only REPL mode
+	// if record is empty, RPC calls are not recorded
+	record string
+	// used only CLI mode
+	// a session file which is recorded by record
+	replay string
+
+	// used only CLI mode
+	// a test suite file, and a file which the result is written to as JUnit XML
+	test  string
+	junit string
+
+	// used only CLI mode
+	// if bench mode is disabled, bench is nil
+	bench *port.BenchParams
+
+	// used only CLI mode
+	// a script file which describes requests of a bidirectional streaming RPC
+	script string
+
+	// used only CLI mode
+	// a message name whose example JSON document is printed
+	template string
+
+	// used only CLI mode
+	// it controls receiving responses of streaming RPCs
+	stream port.StreamParams
+
+	// used only CLI mode
+	// a jq-style expression which is applied to each response
+	filter string
+
 	// explicit using REPL mode
 	repl bool
 
@@ -200,6 +290,27 @@ func (c *Command) init(opts *options, proto []string) error {
 		file: opts.file,
 		repl: opts.repl,
 		cli:  opts.cli,
+
+		record: opts.record,
+		replay: opts.replay,
+
+		test:  opts.test,
+		junit: opts.junit,
+
+		script:   opts.script,
+		template: opts.template,
+		stream:   opts.stream,
+		filter:   opts.filter,
+	}
+	if opts.bench {
+		c.wcfg.bench = &port.BenchParams{
+			RPCName:     opts.call,
+			Concurrency: opts.benchConcurrency,
+			Count:       opts.benchCount,
+			Duration:    opts.benchDuration,
+			QPS:         opts.benchQPS,
+			Timeout:     cfg.Request.Timeout,
+		}
 	}
 
 	err = checkPrecondition(c.wcfg)
@@ -264,7 +375,7 @@ func (c *Command) run(args []string) error {
 
 	var err error
 	// TODO: use c.wcfg.cli instead of c.wcfg.repl
-	if !c.wcfg.repl && cli.IsCLIMode(c.wcfg.file) {
+	if !c.wcfg.repl && (c.wcfg.bench != nil || c.wcfg.replay != "" || c.wcfg.test != "" || c.wcfg.script != "" || c.wcfg.template != "" || cli.IsCLIMode(c.wcfg.file)) {
 		err = c.runAsCLI()
 	} else {
 		err = c.runAsREPL()
@@ -282,7 +393,21 @@ func (c *Command) runAsCLI() error {
 		checkUpdateErrCh <- checkUpdate(ctx, c.wcfg.cfg, c.cache)
 	}()
 
-	err := cli.Run(c.wcfg.cfg, c.ui, c.wcfg.file, c.wcfg.call)
+	var err error
+	switch {
+	case c.wcfg.test != "":
+		err = cli.RunTest(c.wcfg.cfg, c.ui, c.wcfg.test, c.wcfg.junit)
+	case c.wcfg.replay != "":
+		err = cli.RunReplay(c.wcfg.cfg, c.ui, c.wcfg.replay)
+	case c.wcfg.template != "":
+		err = cli.RunTemplate(c.wcfg.cfg, c.ui, c.wcfg.template)
+	case c.wcfg.bench != nil:
+		err = cli.RunBench(c.wcfg.cfg, c.ui, c.wcfg.file, c.wcfg.bench)
+	case c.wcfg.script != "":
+		err = cli.RunScript(c.wcfg.cfg, c.ui, c.wcfg.script, c.callParams())
+	default:
+		err = cli.Run(c.wcfg.cfg, c.ui, c.wcfg.file, c.callParams())
+	}
 	if err != nil {
 		return err
 	}
@@ -293,6 +418,11 @@ func (c *Command) runAsCLI() error {
 	return nil
 }
 
+// callParams returns the parameters of the RPC call which is specified by --call and related options.
+func (c *Command) callParams() *port.CallParams {
+	return &port.CallParams{RPCName: c.wcfg.call, Stream: c.wcfg.stream, Filter: c.wcfg.filter}
+}
+
 func (c *Command) runAsREPL() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -315,7 +445,7 @@ func (c *Command) runAsREPL() error {
 		}
 	}
 
-	err := repl.Run(c.wcfg.cfg, c.ui)
+	err := repl.Run(c.wcfg.cfg, c.ui, c.wcfg.record)
 	if err != nil {
 		return err
 	}
@@ -415,6 +545,95 @@ func checkPrecondition(w *wrappedConfig) error {
 		return errors.New("cannot use both of --cli and --repl options")
 	}
 
+	if w.bench != nil {
+		if w.call == "" {
+			return errors.New("--bench option needs --call option")
+		}
+		if w.repl {
+			return errors.New("cannot use both of --bench and --repl options")
+		}
+	}
+
+	if w.stream != (port.StreamParams{}) {
+		if w.call == "" {
+			return errors.New("--stream-* options need --call option")
+		}
+		if w.repl {
+			return errors.New("cannot use both of --stream-* and --repl options")
+		}
+		if w.stream.MaxMessages < 0 || w.stream.Duration < 0 {
+			return errors.New("--stream-max-messages and --stream-duration must not be negative")
+		}
+	}
+
+	if w.filter != "" {
+		if w.call == "" {
+			return errors.New("--filter option needs --call option")
+		}
+		if w.repl {
+			return errors.New("cannot use both of --filter and --repl options")
+		}
+		if w.bench != nil {
+			return errors.New("--filter option cannot be used with --bench option")
+		}
+	}
+
+	if w.script != "" {
+		if w.call == "" {
+			return errors.New("--script option needs --call option")
+		}
+		if w.repl {
+			return errors.New("cannot use both of --script and --repl options")
+		}
+		if w.file != "" || w.bench != nil {
+			return errors.New("--script option cannot be used with --file or --bench options")
+		}
+	}
+
+	if w.replay != "" {
+		if w.repl {
+			return errors.New("cannot use both of --replay and --repl options")
+		}
+		if w.call != "" {
+			return errors.New("cannot use both of --replay and --call options")
+		}
+	}
+
+	if w.record != "" && w.replay != "" {
+		return errors.New("cannot use both of --record and --replay options")
+	}
+
+	if w.test != "" {
+		if w.repl {
+			return errors.New("cannot use both of --test and --repl options")
+		}
+		if w.call != "" || w.replay != "" || w.bench != nil || w.script != "" {
+			return errors.New("--test option cannot be used with --call, --replay, --bench or --script options")
+		}
+	}
+	if w.template != "" {
+		if w.repl {
+			return errors.New("cannot use both of --template and --repl options")
+		}
+		if w.call != "" || w.replay != "" || w.test != "" || w.file != "" {
+			return errors.New("--template option cannot be used with --call, --replay, --test or --file options")
+		}
+	}
+
+	if w.junit != "" && w.test == "" {
+		return errors.New("--junit option needs --test option")
+	}
+
+	if w.cfg.Request.Web && w.cfg.Request.Compression != "" {
+		return errors.New("compression with gRPC Web is not supported yet")
+	}
+	if err := grpc.ValidateCompressor(w.cfg.Request.Compression); err != nil {
+		return err
+	}
+	if w.cfg.Request.MaxSendMsgSize < 0 || w.cfg.Request.MaxRecvMsgSize < 0 {
+		return errors.New("the max message size must not be negative")
+	}
+
 	if w.cfg.Server.Reflection && w.cfg.Request.Web {
 		return errors.New("gRPC Web server reflection is not supported yet")
 	}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/ktr0731/evans/adapter/cui"
+	"github.com/ktr0731/evans/config"
+	"github.com/ktr0731/evans/usecase/port"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/goleak"
 )
@@ -27,3 +29,245 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkPrecondition_bench(t *testing.T) {
+	newConfig := func() *wrappedConfig {
+		return &wrappedConfig{
+			cfg: &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			},
+			call:  "Unary",
+			bench: &port.BenchParams{RPCName: "Unary", Concurrency: 1},
+		}
+	}
+
+	cases := map[string]struct {
+		modify   func(w *wrappedConfig)
+		hasError bool
+	}{
+		"normal":       {modify: func(*wrappedConfig) {}},
+		"without call": {modify: func(w *wrappedConfig) { w.call = "" }, hasError: true},
+		"with repl":    {modify: func(w *wrappedConfig) { w.repl = true }, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := newConfig()
+			c.modify(w)
+			err := checkPrecondition(w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_stream(t *testing.T) {
+	newConfig := func() *wrappedConfig {
+		return &wrappedConfig{
+			cfg: &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			},
+			call:   "ServerStreaming",
+			stream: port.StreamParams{MaxMessages: 10, ShowSummary: true},
+		}
+	}
+
+	cases := map[string]struct {
+		modify   func(w *wrappedConfig)
+		hasError bool
+	}{
+		"normal":            {modify: func(*wrappedConfig) {}},
+		"without call":      {modify: func(w *wrappedConfig) { w.call = "" }, hasError: true},
+		"with repl":         {modify: func(w *wrappedConfig) { w.repl = true }, hasError: true},
+		"negative duration": {modify: func(w *wrappedConfig) { w.stream.Duration = -1 }, hasError: true},
+		"no stream options": {modify: func(w *wrappedConfig) { w.call, w.stream = "", port.StreamParams{} }},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := newConfig()
+			c.modify(w)
+			err := checkPrecondition(w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_filter(t *testing.T) {
+	newConfig := func() *wrappedConfig {
+		return &wrappedConfig{
+			cfg: &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			},
+			call:   "Unary",
+			filter: ".items[].name",
+		}
+	}
+
+	cases := map[string]struct {
+		modify   func(w *wrappedConfig)
+		hasError bool
+	}{
+		"normal":       {modify: func(*wrappedConfig) {}},
+		"with script":  {modify: func(w *wrappedConfig) { w.script = "script.yaml" }},
+		"without call": {modify: func(w *wrappedConfig) { w.call = "" }, hasError: true},
+		"with repl":    {modify: func(w *wrappedConfig) { w.repl = true }, hasError: true},
+		"with bench":   {modify: func(w *wrappedConfig) { w.bench = &port.BenchParams{RPCName: "Unary", Concurrency: 1} }, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := newConfig()
+			c.modify(w)
+			err := checkPrecondition(w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_template(t *testing.T) {
+	newConfig := func() *wrappedConfig {
+		return &wrappedConfig{
+			cfg: &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			},
+			template: "HelloRequest",
+		}
+	}
+
+	cases := map[string]struct {
+		modify   func(w *wrappedConfig)
+		hasError bool
+	}{
+		"normal":    {modify: func(*wrappedConfig) {}},
+		"with repl": {modify: func(w *wrappedConfig) { w.repl = true }, hasError: true},
+		"with call": {modify: func(w *wrappedConfig) { w.call = "Unary" }, hasError: true},
+		"with file": {modify: func(w *wrappedConfig) { w.file = "request.json" }, hasError: true},
+		"with test": {modify: func(w *wrappedConfig) { w.test = "suite.yaml" }, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := newConfig()
+			c.modify(w)
+			err := checkPrecondition(w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_script(t *testing.T) {
+	newConfig := func() *wrappedConfig {
+		return &wrappedConfig{
+			cfg: &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			},
+			call:   "BidiStreaming",
+			script: "script.yaml",
+		}
+	}
+
+	cases := map[string]struct {
+		modify   func(w *wrappedConfig)
+		hasError bool
+	}{
+		"normal":       {modify: func(*wrappedConfig) {}},
+		"without call": {modify: func(w *wrappedConfig) { w.call = "" }, hasError: true},
+		"with repl":    {modify: func(w *wrappedConfig) { w.repl = true }, hasError: true},
+		"with file":    {modify: func(w *wrappedConfig) { w.file = "request.json" }, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := newConfig()
+			c.modify(w)
+			err := checkPrecondition(w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_replay(t *testing.T) {
+	cases := map[string]struct {
+		w        *wrappedConfig
+		hasError bool
+	}{
+		"normal":      {w: &wrappedConfig{replay: "session.jsonl"}},
+		"with repl":   {w: &wrappedConfig{replay: "session.jsonl", repl: true}, hasError: true},
+		"with call":   {w: &wrappedConfig{replay: "session.jsonl", call: "Unary"}, hasError: true},
+		"with record": {w: &wrappedConfig{replay: "session.jsonl", record: "session.jsonl"}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			c.w.cfg = &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			}
+			err := checkPrecondition(c.w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkPrecondition_test(t *testing.T) {
+	cases := map[string]struct {
+		w        *wrappedConfig
+		hasError bool
+	}{
+		"normal":             {w: &wrappedConfig{test: "suite.yaml", junit: "report.xml"}},
+		"with repl":          {w: &wrappedConfig{test: "suite.yaml", repl: true}, hasError: true},
+		"with replay":        {w: &wrappedConfig{test: "suite.yaml", replay: "session.jsonl"}, hasError: true},
+		"junit without test": {w: &wrappedConfig{junit: "report.xml"}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			c.w.cfg = &config.Config{
+				Default: &config.Default{Package: "api", Service: "Example"},
+				Server:  &config.Server{Port: "50051"},
+				Request: &config.Request{},
+			}
+			err := checkPrecondition(c.w)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/ktr0731/evans/adapter/cui"
+	"github.com/ktr0731/evans/adapter/session"
+	"github.com/ktr0731/evans/adapter/testsuite"
 	"github.com/ktr0731/evans/config"
 	"github.com/ktr0731/evans/di"
 	"github.com/ktr0731/evans/usecase"
@@ -38,7 +40,90 @@ func (e *LaunchError) Error() string {
 //   - Precondition error to launch CLI mode.
 // - TODO: Describe more error specification.
 //
-func Run(cfg *config.Config, ui cui.UI, file, call string) error {
+// params specifies the RPC and how to receive and filter its responses.
+// The timeout of params is overwritten by the config.
+func Run(cfg *config.Config, ui cui.UI, file string, params *port.CallParams) error {
+	return run(cfg, ui, file, func(interactor *usecase.Interactor) (io.Reader, error) {
+		params.Timeout = cfg.Request.Timeout
+		return interactor.Call(params)
+	})
+}
+
+// RunBench is an entrypoint for bench mode.
+// It calls the RPC specified by params repeatedly with a request message which is read from `file` or stdin.
+// RunBench returns the same errors as Run.
+func RunBench(cfg *config.Config, ui cui.UI, file string, params *port.BenchParams) error {
+	return run(cfg, ui, file, func(interactor *usecase.Interactor) (io.Reader, error) {
+		return interactor.Bench(params)
+	})
+}
+
+// RunScript is an entrypoint for calling a bidirectional streaming RPC with a script.
+// RunScript reads the script from `script` which is a YAML or TOML file,
+// then sends requests according to the script instead of reading them from stdin.
+// params is the same as Run.
+func RunScript(cfg *config.Config, ui cui.UI, script string, params *port.CallParams) error {
+	s, err := testsuite.ReadScript(script)
+	if err != nil {
+		return err
+	}
+	return run(cfg, ui, "", func(interactor *usecase.Interactor) (io.Reader, error) {
+		params.Timeout, params.Script = cfg.Request.Timeout, s
+		return interactor.Call(params)
+	})
+}
+
+// RunReplay is an entrypoint for replaying a session which is recorded in REPL mode.
+// RunReplay reads the session from `file`, then calls each recorded RPC.
+// If some of responses or status are different from the recorded ones,
+// RunReplay returns *usecase.ReplayError.
+func RunReplay(cfg *config.Config, ui cui.UI, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	calls, err := session.Read(f)
+	if err != nil {
+		return err
+	}
+	return run(cfg, ui, "", func(interactor *usecase.Interactor) (io.Reader, error) {
+		return interactor.Replay(&port.ReplayParams{Calls: calls, Timeout: cfg.Request.Timeout})
+	})
+}
+
+// RunTemplate is an entrypoint for printing an example JSON document of the message.
+// The document can be used as a request file of CLI mode.
+func RunTemplate(cfg *config.Config, ui cui.UI, msgName string) error {
+	return run(cfg, ui, "", func(interactor *usecase.Interactor) (io.Reader, error) {
+		return interactor.Describe(&port.DescribeParams{MsgName: msgName, Template: true})
+	})
+}
+
+// RunTest is an entrypoint for running a test suite.
+// RunTest reads the test suite from `suite` which is a YAML or TOML file.
+// If `junit` isn't empty, the result is also written to the file as JUnit XML.
+// If some of test cases failed, RunTest returns *usecase.TestError.
+func RunTest(cfg *config.Config, ui cui.UI, suite, junit string) error {
+	s, err := testsuite.Read(suite)
+	if err != nil {
+		return err
+	}
+	params := &port.TestParams{Suite: s, Timeout: cfg.Request.Timeout}
+	if junit != "" {
+		f, err := os.Create(junit)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		params.Reporters = append(params.Reporters, testsuite.NewJUnitReporter(f))
+	}
+	return run(cfg, ui, "", func(interactor *usecase.Interactor) (io.Reader, error) {
+		return interactor.Test(params)
+	})
+}
+
+func run(cfg *config.Config, ui cui.UI, file string, f func(*usecase.Interactor) (io.Reader, error)) error {
 	in := DefaultReader
 
 	if file != "" {
@@ -60,7 +145,7 @@ func Run(cfg *config.Config, ui cui.UI, file, call string) error {
 
 	interactor := usecase.NewInteractor(p)
 
-	res, err := interactor.Call(&port.CallParams{RPCName: call})
+	res, err := f(interactor)
 	if err != nil {
 		return err
 	}
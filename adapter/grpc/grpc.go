@@ -20,6 +20,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
 type client struct {
@@ -38,8 +39,13 @@ type client struct {
 // The set of cert and certKey enables mutual authentication if useTLS is enabled.
 // If one of it is not found, NewClient returns entity.ErrMutualAuthParamsAreNotEnough.
 // If useTLS is false, cacert, cert and certKey are ignored.
-func NewClient(addr, serverName string, useReflection, useTLS bool, cacert, cert, certKey string) (entity.GRPCClient, error) {
-	var opts []grpc.DialOption
+//
+// clientOpts configure the compression and message size limits of all RPC calls.
+func NewClient(addr, serverName string, useReflection, useTLS bool, cacert, cert, certKey string, clientOpts ...ClientOption) (entity.GRPCClient, error) {
+	opts, err := dialOptions(clientOpts)
+	if err != nil {
+		return nil, err
+	}
 	if !useTLS {
 		opts = append(opts, grpc.WithInsecure())
 	} else { // Enable TLS authentication
@@ -90,14 +96,17 @@ func NewClient(addr, serverName string, useReflection, useTLS bool, cacert, cert
 	return client, nil
 }
 
-func (c *client) Invoke(ctx context.Context, fqrn string, req, res interface{}) error {
+func (c *client) Invoke(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
 	endpoint, err := fqrnToEndpoint(fqrn)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	loggingRequest(req)
 	wakeUpClientConn(c.conn)
-	return c.conn.Invoke(ctx, endpoint, req, res)
+	var header, trailer metadata.MD
+	opts := append(callOptions(ctx), grpc.Header(&header), grpc.Trailer(&trailer))
+	err = c.conn.Invoke(ctx, endpoint, req, res, opts...)
+	return header, trailer, err
 }
 
 func (c *client) Close(ctx context.Context) error {
@@ -140,12 +149,20 @@ func (s *clientStream) CloseAndReceive(res *proto.Message) error {
 	return nil
 }
 
+func (s *clientStream) Header() (metadata.MD, error) {
+	return s.cs.Header()
+}
+
+func (s *clientStream) Trailer() metadata.MD {
+	return s.cs.Trailer()
+}
+
 func (c *client) NewClientStream(ctx context.Context, rpc entity.RPC) (entity.ClientStream, error) {
 	endpoint, err := fqrnToEndpoint(rpc.FQRN())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert fqrn to endpoint")
 	}
-	cs, err := c.conn.NewStream(ctx, rpc.StreamDesc(), endpoint)
+	cs, err := c.conn.NewStream(ctx, rpc.StreamDesc(), endpoint, callOptions(ctx)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to instantiate gRPC stream")
 	}
@@ -186,6 +203,14 @@ func (s *bidiStream) CloseSend() error {
 	return s.s.cs.CloseSend()
 }
 
+func (s *bidiStream) Header() (metadata.MD, error) {
+	return s.s.cs.Header()
+}
+
+func (s *bidiStream) Trailer() metadata.MD {
+	return s.s.cs.Trailer()
+}
+
 func (c *client) NewBidiStream(ctx context.Context, rpc entity.RPC) (entity.BidiStream, error) {
 	s, err := c.NewServerStream(ctx, rpc)
 	if err != nil {
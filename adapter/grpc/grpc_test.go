@@ -28,6 +28,7 @@ func TestNewClient(t *testing.T) {
 		cacert        string
 		cert          string
 		certKey       string
+		opts          []ClientOption
 
 		hasErr bool
 		err    error
@@ -42,11 +43,13 @@ func TestNewClient(t *testing.T) {
 		"enable mutual TLS with a trusted CA":     {useTLS: true, cacert: certPath("rootCA.pem"), cert: certPath("localhost.pem"), certKey: certPath("localhost-key.pem")},
 		"invalid cacert file path":                {useTLS: true, cacert: "fooCA.pem", hasErr: true},
 		"invalid cert and key file path":          {useTLS: true, cert: "foo.pem", certKey: "foo-key.pem", hasErr: true},
+		"enable gzip compression":                 {opts: []ClientOption{WithCompressor("gzip")}},
+		"unknown compressor":                      {opts: []ClientOption{WithCompressor("foo")}, hasErr: true},
 	}
 	for name, c := range cases {
 		c := c
 		t.Run(name, func(t *testing.T) {
-			_, err := NewClient(c.addr, "", c.useReflection, c.useTLS, c.cacert, c.cert, c.certKey)
+			_, err := NewClient(c.addr, "", c.useReflection, c.useTLS, c.cacert, c.cert, c.certKey, c.opts...)
 			if c.err != nil {
 				require.Error(t, err, "NewClient must return an error")
 				assert.Equal(t, c.err, err)
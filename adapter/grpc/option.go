@@ -0,0 +1,125 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/logger"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+
+	// register the gzip compressor. Other compressors can be used by registering them
+	// with encoding.RegisterCompressor.
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// ClientOption configures a gRPC client which is created by NewClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	compressor     string
+	maxSendMsgSize int
+	maxRecvMsgSize int
+}
+
+// WithCompressor compresses request messages by the compressor which has the name.
+// The compressor must be registered by encoding.RegisterCompressor. gzip is registered by default.
+func WithCompressor(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.compressor = name
+	}
+}
+
+// WithMaxSendMsgSize sets the max size in bytes of a message which can be sent.
+func WithMaxSendMsgSize(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxSendMsgSize = n
+	}
+}
+
+// WithMaxRecvMsgSize sets the max size in bytes of a message which can be received.
+func WithMaxRecvMsgSize(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxRecvMsgSize = n
+	}
+}
+
+// ValidateCompressor returns an error if the compressor which has the name is not registered.
+// An empty name means no compression.
+func ValidateCompressor(name string) error {
+	if name != "" && encoding.GetCompressor(name) == nil {
+		return errors.Errorf("unknown compressor '%s'", name)
+	}
+	return nil
+}
+
+// callOptions returns call options which are specified by ctx.
+// They override the options of the client per RPC call.
+func callOptions(ctx context.Context) []grpc.CallOption {
+	var opts []grpc.CallOption
+	if name, ok := entity.CompressorFromContext(ctx); ok {
+		opts = append(opts, grpc.UseCompressor(name))
+	}
+	return opts
+}
+
+// dialOptions converts opts to dial options. Each option is applied to all RPC calls.
+func dialOptions(opts []ClientOption) ([]grpc.DialOption, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var callOpts []grpc.CallOption
+	if err := ValidateCompressor(o.compressor); err != nil {
+		return nil, err
+	}
+	if o.compressor != "" {
+		callOpts = append(callOpts, grpc.UseCompressor(o.compressor))
+	}
+	if o.maxSendMsgSize < 0 || o.maxRecvMsgSize < 0 {
+		return nil, errors.New("the max message size must not be negative")
+	}
+	if o.maxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(o.maxSendMsgSize))
+	}
+	if o.maxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(o.maxRecvMsgSize))
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithStatsHandler(&payloadLogger{})}
+	if len(callOpts) != 0 {
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	return dialOpts, nil
+}
+
+// payloadLogger logs the size of each message. It is shown in verbose mode.
+// The size on the wire is the size after compression.
+type payloadLogger struct{}
+
+func (l *payloadLogger) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (l *payloadLogger) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.OutPayload:
+		logger.Printf("sent a message: %d bytes (%d bytes on the wire)", s.Length, s.WireLength)
+	case *stats.InPayload:
+		// WireLength of received messages may not be reported by gRPC.
+		if s.WireLength == 0 {
+			logger.Printf("received a message: %d bytes", s.Length)
+			return
+		}
+		logger.Printf("received a message: %d bytes (%d bytes on the wire)", s.Length, s.WireLength)
+	}
+}
+
+func (l *payloadLogger) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (l *payloadLogger) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ktr0731/evans/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dialOptions(t *testing.T) {
+	cases := map[string]struct {
+		opts     []ClientOption
+		expected int
+		hasErr   bool
+	}{
+		"no options":         {expected: 1},
+		"gzip":               {opts: []ClientOption{WithCompressor("gzip")}, expected: 2},
+		"message size":       {opts: []ClientOption{WithMaxSendMsgSize(1024), WithMaxRecvMsgSize(1024)}, expected: 2},
+		"zero message size":  {opts: []ClientOption{WithMaxSendMsgSize(0), WithCompressor("")}, expected: 1},
+		"unknown compressor": {opts: []ClientOption{WithCompressor("foo")}, hasErr: true},
+		"negative size":      {opts: []ClientOption{WithMaxRecvMsgSize(-1)}, hasErr: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts, err := dialOptions(c.opts)
+			if c.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, opts, c.expected)
+		})
+	}
+}
+
+func Test_callOptions(t *testing.T) {
+	assert.Len(t, callOptions(context.Background()), 0)
+	assert.Len(t, callOptions(entity.NewCompressorContext(context.Background(), "gzip")), 1)
+}
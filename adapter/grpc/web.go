@@ -2,15 +2,18 @@ package grpc
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/usecase/port"
 	"github.com/ktr0731/grpc-web-go-client/grpcweb"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
 )
 
 type webClient struct {
+	addr string
 	conn *grpcweb.Client
 
 	builder port.DynamicBuilder
@@ -19,8 +22,14 @@ type webClient struct {
 }
 
 func NewWebClient(addr string, builder port.DynamicBuilder, useReflection, useTLS bool, cacert, cert, certKey string) entity.GRPCClient {
-	conn := grpcweb.NewClient(addr)
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	tb := &recordingTransportBuilder{scheme: scheme, client: &http.Client{}}
+	conn := grpcweb.NewClient(addr, grpcweb.WithTransportBuilder(tb.build))
 	client := &webClient{
+		addr:    addr,
 		conn:    conn,
 		builder: builder,
 	}
@@ -32,20 +41,30 @@ func NewWebClient(addr string, builder port.DynamicBuilder, useReflection, useTL
 	return client
 }
 
-func (c *webClient) Invoke(ctx context.Context, fqrn string, req, res interface{}) error {
+func (c *webClient) Invoke(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
 	endpoint, err := fqrnToEndpoint(fqrn)
 	if err != nil {
-		return errors.Wrap(err, "failed to convert FQRN to endpoint")
+		return nil, nil, errors.Wrap(err, "failed to convert FQRN to endpoint")
+	}
+	if err := validateWebCallOptions(ctx); err != nil {
+		return nil, nil, err
 	}
 
 	loggingRequest(req)
 
+	rec := &metadataRecorder{}
 	request := grpcweb.NewRequest(endpoint, req.(proto.Message), res.(proto.Message))
-	res, err = c.conn.Unary(ctx, request)
-	return err
+	_, err = c.conn.Unary(newWebCallContext(ctx, endpoint, rec), request)
+	if serr := rec.err(); serr != nil {
+		err = serr
+	}
+	header, _ := rec.Header()
+	return header, rec.Trailer(), err
 }
 
 type webClientStream struct {
+	*metadataRecorder
+
 	conn grpcweb.ClientStreamClient
 
 	newRequest func(req proto.Message) *grpcweb.Request
@@ -59,6 +78,9 @@ func (s *webClientStream) Send(req proto.Message) error {
 
 func (s *webClientStream) CloseAndReceive(res *proto.Message) error {
 	response, err := s.conn.CloseAndReceive()
+	if serr := s.err(); serr != nil {
+		return serr
+	}
 	if err != nil {
 		return err
 	}
@@ -71,16 +93,18 @@ func (c *webClient) NewClientStream(ctx context.Context, rpc entity.RPC) (entity
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert FQRN to endpoint")
 	}
-
-	if err != nil {
+	if err := validateWebCallOptions(ctx); err != nil {
 		return nil, err
 	}
-	cc, err := c.conn.ClientStreaming(ctx)
+
+	rec := &metadataRecorder{}
+	cc, err := newStreamConn(c.addr, rec).ClientStreaming(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return &webClientStream{
-		conn: cc,
+		metadataRecorder: rec,
+		conn:             cc,
 		newRequest: func(req proto.Message) *grpcweb.Request {
 			return grpcweb.NewRequest(
 				endpoint,
@@ -92,6 +116,8 @@ func (c *webClient) NewClientStream(ctx context.Context, rpc entity.RPC) (entity
 }
 
 type webServerStream struct {
+	*metadataRecorder
+
 	newClient func(req proto.Message) (grpcweb.ServerStreamClient, error)
 	conn      grpcweb.ServerStreamClient
 }
@@ -108,6 +134,9 @@ func (s *webServerStream) Receive(res *proto.Message) error {
 	}
 	resp, err := s.conn.Receive()
 	if err != nil {
+		if serr := s.err(); serr != nil {
+			return serr
+		}
 		return err
 	}
 	*res = resp.Content.(proto.Message)
@@ -119,7 +148,11 @@ func (c *webClient) NewServerStream(ctx context.Context, rpc entity.RPC) (entity
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert FQRN to endpoint")
 	}
+	if err := validateWebCallOptions(ctx); err != nil {
+		return nil, err
+	}
 
+	rec := &metadataRecorder{}
 	newClient := func(req proto.Message) (grpcweb.ServerStreamClient, error) {
 		request := grpcweb.NewRequest(
 			endpoint,
@@ -127,7 +160,7 @@ func (c *webClient) NewServerStream(ctx context.Context, rpc entity.RPC) (entity
 			c.builder.NewMessage(rpc.ResponseMessage()),
 		)
 
-		sc, err := c.conn.ServerStreaming(ctx, request)
+		sc, err := c.conn.ServerStreaming(newWebCallContext(ctx, endpoint, rec), request)
 		if err != nil {
 			return nil, err
 		}
@@ -135,13 +168,12 @@ func (c *webClient) NewServerStream(ctx context.Context, rpc entity.RPC) (entity
 		return sc, nil
 	}
 
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to make new server streaming gRPC Web request")
-	}
-	return &webServerStream{newClient: newClient}, nil
+	return &webServerStream{metadataRecorder: rec, newClient: newClient}, nil
 }
 
 type webBidiStream struct {
+	*metadataRecorder
+
 	conn grpcweb.BidiStreamClient
 
 	newRequest func(req proto.Message) *grpcweb.Request
@@ -156,6 +188,9 @@ func (s *webBidiStream) Send(req proto.Message) error {
 func (s *webBidiStream) Receive(res *proto.Message) error {
 	response, err := s.conn.Receive()
 	if err != nil {
+		if serr := s.err(); serr != nil {
+			return serr
+		}
 		return err
 	}
 	*res = response.Content.(proto.Message)
@@ -171,6 +206,9 @@ func (c *webClient) NewBidiStream(ctx context.Context, rpc entity.RPC) (entity.B
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert FQRN to endpoint")
 	}
+	if err := validateWebCallOptions(ctx); err != nil {
+		return nil, err
+	}
 
 	newRequest := func(req proto.Message) *grpcweb.Request {
 		return grpcweb.NewRequest(
@@ -180,18 +218,28 @@ func (c *webClient) NewBidiStream(ctx context.Context, rpc entity.RPC) (entity.B
 		)
 	}
 
+	rec := &metadataRecorder{}
 	req := newRequest(c.builder.NewMessage(rpc.RequestMessage()))
-	sc, err := c.conn.BidiStreaming(ctx, req)
+	sc, err := newStreamConn(c.addr, rec).BidiStreaming(ctx, req)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to start bidirectional streaming")
 	}
 
 	return &webBidiStream{
-		conn:       sc,
-		newRequest: newRequest,
+		metadataRecorder: rec,
+		conn:             sc,
+		newRequest:       newRequest,
 	}, nil
 }
 
+// validateWebCallOptions returns an error if ctx has call options which gRPC Web doesn't support.
+func validateWebCallOptions(ctx context.Context) error {
+	if _, ok := entity.CompressorFromContext(ctx); ok {
+		return errors.New("compression with gRPC Web is not supported yet")
+	}
+	return nil
+}
+
 func (c *webClient) Close(ctx context.Context) error {
 	c.reflectionClient.Close()
 	return nil
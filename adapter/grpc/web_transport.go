@@ -0,0 +1,316 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/grpc-web-go-client/grpcweb"
+	"github.com/pkg/errors"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataRecorder records the header and trailer metadata which are sent from a gRPC-Web server.
+// A metadataRecorder is created per one RPC call.
+type metadataRecorder struct {
+	mu      sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+	// headerTrailer is the trailer which is sent as HTTP response headers.
+	// A server sends it instead of a trailer frame if the response is Trailers-Only.
+	headerTrailer metadata.MD
+}
+
+// setHTTPHeader records HTTP response headers. Trailer keys like grpc-status are separated from the header.
+func (r *metadataRecorder) setHTTPHeader(h http.Header) {
+	header, trailer := metadata.MD{}, metadata.MD{}
+	for k, v := range h {
+		md := header
+		if isTrailerKey(k) {
+			md = trailer
+		}
+		for _, vv := range v {
+			appendMetadata(md, k, vv)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.header = metadata.Join(r.header, header)
+	r.headerTrailer = metadata.Join(r.headerTrailer, trailer)
+}
+
+func (r *metadataRecorder) setTrailer(md metadata.MD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trailer = metadata.Join(r.trailer, md)
+}
+
+// err returns a gRPC status error if the recorded metadata has a non-OK status.
+func (r *metadataRecorder) err() error {
+	if st := statusFromMetadata(r.Trailer()); st != nil {
+		return st.Err()
+	}
+	return nil
+}
+
+// Header returns the recorded header. Note that the header of client streaming and bidirectional
+// streaming RPCs is not recorded because the stream transport of grpcweb discards it.
+func (r *metadataRecorder) Header() (metadata.MD, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header, nil
+}
+
+// Trailer returns the trailer which is sent as a trailer frame.
+// If there is no trailer frame, the trailer in HTTP response headers is returned.
+func (r *metadataRecorder) Trailer() metadata.MD {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.trailer == nil {
+		return r.headerTrailer
+	}
+	return r.trailer
+}
+
+// isTrailerKey reports whether k is a key of the trailer which is sent as an HTTP header in Trailers-Only responses.
+func isTrailerKey(k string) bool {
+	switch strings.ToLower(k) {
+	case "grpc-status", "grpc-message", "grpc-status-details-bin":
+		return true
+	default:
+		return false
+	}
+}
+
+// webCall is an RPC call of gRPC-Web which is passed to recordingTransport through the context.
+type webCall struct {
+	endpoint string
+	rec      *metadataRecorder
+}
+
+type webCallKey struct{}
+
+// newWebCallContext returns a copy of ctx which has the endpoint of the RPC call and rec.
+// The header and trailer of the RPC call which is started with the context are recorded to rec.
+func newWebCallContext(ctx context.Context, endpoint string, rec *metadataRecorder) context.Context {
+	return context.WithValue(ctx, webCallKey{}, &webCall{endpoint: endpoint, rec: rec})
+}
+
+func webCallFromContext(ctx context.Context) (*webCall, bool) {
+	call, ok := ctx.Value(webCallKey{}).(*webCall)
+	return call, ok
+}
+
+// recordingTransportBuilder builds recordingTransports which send requests by client.
+type recordingTransportBuilder struct {
+	scheme string
+	client *http.Client
+}
+
+func (b *recordingTransportBuilder) build(host string, req *grpcweb.Request) grpcweb.Transport {
+	return &recordingTransport{
+		Transport: grpcweb.DefaultTransportBuilder(host, req),
+		host:      host,
+		scheme:    b.scheme,
+		client:    b.client,
+	}
+}
+
+// recordingTransport sends a request of the RPC call in the context which is passed to Send,
+// then records HTTP response headers and trailer frames of the response.
+// grpcweb.HTTPTransport can't be used for it because it discards HTTP response headers.
+// If the context doesn't have an RPC call (e.g. server reflection), the request is sent by
+// the transport which is built by grpcweb.DefaultTransportBuilder.
+type recordingTransport struct {
+	grpcweb.Transport
+
+	host   string
+	scheme string
+	client *http.Client
+}
+
+func (t *recordingTransport) Send(ctx context.Context, body io.Reader) (io.ReadCloser, error) {
+	call, ok := webCallFromContext(ctx)
+	if !ok {
+		return t.Transport.Send(ctx, body)
+	}
+
+	u := url.URL{Scheme: t.scheme, Host: t.host, Path: call.endpoint}
+	req, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the API request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("content-type", "application/grpc-web+proto")
+	req.Header.Add("x-grpc-web", "1")
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send the API")
+	}
+	call.rec.setHTTPHeader(res.Header)
+	return &trailerReader{rc: res.Body, rec: call.rec}, nil
+}
+
+// newStreamConn returns a new gRPC-Web client which records trailers of client streaming and
+// bidirectional streaming RPCs to rec. A client is created per one RPC call because
+// grpcweb.StreamTransportBuilder isn't passed the context of the RPC call.
+func newStreamConn(addr string, rec *metadataRecorder) *grpcweb.Client {
+	return grpcweb.NewClient(
+		addr,
+		grpcweb.WithStreamTransportBuilder(func(host, endpoint string) (grpcweb.StreamTransport, error) {
+			t, err := grpcweb.DefaultStreamTransportBuilder(host, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			return &recordingStreamTransport{StreamTransport: t, rec: rec}, nil
+		}),
+	)
+}
+
+// trailerReader reads gRPC-Web frames from rc as it is.
+// If a trailer frame is found, it is recorded to rec.
+type trailerReader struct {
+	rc  io.ReadCloser
+	buf bytes.Buffer
+	rec *metadataRecorder
+}
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.buf.Write(p[:n])
+	r.parseFrames()
+	return n, err
+}
+
+// Close reads the remaining frames to record trailers before closing rc
+// because grpcweb.Client closes the response body without reading
+// trailers in unary RPCs.
+func (r *trailerReader) Close() error {
+	io.Copy(ioutil.Discard, r)
+	return r.rc.Close()
+}
+
+func (r *trailerReader) parseFrames() {
+	for {
+		b := r.buf.Bytes()
+		if len(b) < 5 {
+			return
+		}
+		flag, length := b[0], binary.BigEndian.Uint32(b[1:5])
+		if uint32(len(b)-5) < length {
+			return
+		}
+		frame := r.buf.Next(5 + int(length))
+		if isTrailerFrame(flag, frame[5:]) {
+			r.rec.setTrailer(parseMetadataFrame(frame[5:]))
+		}
+	}
+}
+
+// recordingStreamTransport records trailers that are passed through Receive.
+type recordingStreamTransport struct {
+	grpcweb.StreamTransport
+
+	rec *metadataRecorder
+}
+
+func (t *recordingStreamTransport) Receive() (io.ReadCloser, error) {
+	rc, err := t.StreamTransport.Receive()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) >= 5 && isTrailerFrame(b[0], b[5:]) {
+		t.rec.setTrailer(parseMetadataFrame(b[5:]))
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+// isTrailerFrame reports whether the frame is a trailer frame.
+// The MSB of the flag is set in trailer frames, however some
+// implementations don't set it, so we also check the content.
+func isTrailerFrame(flag byte, content []byte) bool {
+	return flag&0x80 != 0 || bytes.HasPrefix(bytes.ToLower(content), []byte("grpc-status:"))
+}
+
+// parseMetadataFrame parses the content of a trailer frame.
+// The content is formatted like HTTP/1 headers.
+//
+// e.g.
+//	grpc-status: 0\r\ngrpc-message: \r\n
+func parseMetadataFrame(content []byte) metadata.MD {
+	md := metadata.MD{}
+	for _, l := range strings.Split(string(content), "\r\n") {
+		kv := strings.SplitN(l, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		appendMetadata(md, strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	return md
+}
+
+// appendMetadata appends a metadata which is received as an HTTP header to md.
+// The value of a binary header (the key has "-bin" suffix) is decoded by base64
+// as same as gRPC does.
+func appendMetadata(md metadata.MD, k, v string) {
+	k = strings.ToLower(k)
+	if strings.HasSuffix(k, "-bin") {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			b, err = base64.RawStdEncoding.DecodeString(v)
+		}
+		if err == nil {
+			v = string(b)
+		}
+	}
+	md.Append(k, v)
+}
+
+// statusFromMetadata returns a gRPC status if md has a non-OK grpc-status.
+// If md has grpc-status-details-bin, the status is decoded from it.
+func statusFromMetadata(md metadata.MD) *status.Status {
+	v := md.Get("grpc-status")
+	if len(v) == 0 {
+		return nil
+	}
+	code, err := strconv.Atoi(v[0])
+	if err != nil {
+		return status.Newf(codes.Unknown, "invalid grpc-status: %s", v[0])
+	}
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+	if d := md.Get("grpc-status-details-bin"); len(d) != 0 {
+		var st spb.Status
+		if err := proto.Unmarshal([]byte(d[0]), &st); err == nil && st.GetCode() == int32(code) {
+			return status.FromProto(&st)
+		}
+	}
+	var msg string
+	if m := md.Get("grpc-message"); len(m) != 0 {
+		// grpc-message is percent-encoded.
+		msg, err = url.PathUnescape(m[0])
+		if err != nil {
+			msg = m[0]
+		}
+	}
+	return status.New(codes.Code(code), msg)
+}
@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/ktr0731/grpc-web-go-client/grpcweb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func Test_parseMetadataFrame(t *testing.T) {
+	md := parseMetadataFrame([]byte("Grpc-Status: 0\r\ngrpc-message: \r\nfoo-bin: YmFy\r\n"))
+	assert.Equal(t, metadata.MD{
+		"grpc-status":  []string{"0"},
+		"grpc-message": []string{""},
+		"foo-bin":      []string{"bar"},
+	}, md)
+}
+
+func Test_statusFromMetadata(t *testing.T) {
+	st := status.New(codes.Unavailable, "unavailable")
+	st, err := st.WithDetails(&errdetails.RetryInfo{})
+	require.NoError(t, err)
+	b, err := proto.Marshal(st.Proto())
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		md       metadata.MD
+		expected *status.Status
+	}{
+		"no status":       {md: metadata.MD{}},
+		"ok":              {md: metadata.Pairs("grpc-status", "0")},
+		"invalid status":  {md: metadata.Pairs("grpc-status", "foo"), expected: status.New(codes.Unknown, "invalid grpc-status: foo")},
+		"not found":       {md: metadata.Pairs("grpc-status", "5", "grpc-message", "not%20found"), expected: status.New(codes.NotFound, "not found")},
+		"with details":    {md: metadata.Pairs("grpc-status", "14", "grpc-status-details-bin", string(b)), expected: st},
+		"mismatched code": {md: metadata.Pairs("grpc-status", "5", "grpc-status-details-bin", string(b)), expected: status.New(codes.NotFound, "")},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := statusFromMetadata(c.md)
+			if c.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+			require.NotNil(t, actual)
+			assert.True(t, proto.Equal(c.expected.Proto(), actual.Proto()))
+		})
+	}
+}
+
+func Test_recordingTransport(t *testing.T) {
+	trailer := []byte("grpc-status: 5\r\ngrpc-message: not%20found\r\n")
+	var body bytes.Buffer
+	body.Write([]byte{0, 0, 0, 0, 0})
+	body.WriteByte(0x80)
+	binary.Write(&body, binary.BigEndian, uint32(len(trailer)))
+	body.Write(trailer)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("foo", "bar")
+		if r.URL.Path == "/api.Example/TrailersOnly" {
+			w.Header().Set("grpc-status", "7")
+			return
+		}
+		w.Write(body.Bytes())
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	b := &recordingTransportBuilder{scheme: "http", client: srv.Client()}
+	newTransport := func() grpcweb.Transport {
+		return b.build(host, grpcweb.NewRequest("/api.Example/Unary", &empty.Empty{}, &empty.Empty{}))
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		rec := &metadataRecorder{}
+		rc, err := newTransport().Send(newWebCallContext(context.Background(), "/api.Example/Unary", rec), strings.NewReader(""))
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		assert.Equal(t, body.Bytes(), b, "the response body must be read as it is")
+		header, err := rec.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bar"}, header.Get("foo"))
+		assert.Empty(t, header.Get("grpc-status"))
+		assert.Equal(t, metadata.Pairs("grpc-status", "5", "grpc-message", "not%20found"), rec.Trailer())
+		assert.Equal(t, status.Error(codes.NotFound, "not found"), rec.err())
+	})
+
+	t.Run("Trailers-Only", func(t *testing.T) {
+		rec := &metadataRecorder{}
+		rc, err := newTransport().Send(newWebCallContext(context.Background(), "/api.Example/TrailersOnly", rec), strings.NewReader(""))
+		require.NoError(t, err)
+		_, err = ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		header, err := rec.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bar"}, header.Get("foo"))
+		assert.Empty(t, header.Get("grpc-status"), "trailer keys must not be in the header")
+		assert.Equal(t, []string{"7"}, rec.Trailer().Get("grpc-status"))
+		assert.Equal(t, codes.PermissionDenied, status.Code(rec.err()))
+	})
+
+	t.Run("without the RPC call", func(t *testing.T) {
+		rc, err := newTransport().Send(context.Background(), strings.NewReader(""))
+		require.NoError(t, err)
+		defer rc.Close()
+		_, ok := rc.(*trailerReader)
+		assert.False(t, ok)
+	})
+}
+
+func Test_webClient_Invoke(t *testing.T) {
+	trailer := []byte("grpc-status: 0\r\nbaz: qux\r\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api.Example/Unary", r.URL.Path)
+		w.Header().Set("foo", "bar")
+		w.Write([]byte{0, 0, 0, 0, 0})
+		w.Write([]byte{0x80})
+		binary.Write(w, binary.BigEndian, uint32(len(trailer)))
+		w.Write(trailer)
+	}))
+	defer srv.Close()
+
+	client := NewWebClient(strings.TrimPrefix(srv.URL, "http://"), nil, false, false, "", "", "")
+	header, trailerMD, err := client.Invoke(context.Background(), "api.Example.Unary", &empty.Empty{}, &empty.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar"}, header.Get("foo"))
+	assert.Equal(t, []string{"qux"}, trailerMD.Get("baz"))
+}
@@ -0,0 +1,107 @@
+package inputter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/cache"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/usecase/pbusecase"
+	"github.com/pkg/errors"
+)
+
+// Editor is an inputter which inputs requests by editing JSON with an external editor.
+// Editor opens a skeleton of the request message at first. After that,
+// it opens the last edited request of the same RPC instead of the skeleton.
+// Lines which start with "//" are regarded as comments.
+// If the edited request is empty, Input returns io.EOF.
+type Editor struct {
+	editor  string
+	rpcName string
+
+	// env is used to interpolate references to variables in string values.
+	env env.Environment
+}
+
+// NewEditor returns a new Editor. rpcName is the fully-qualified name of the RPC which is used as a key of
+// last edited requests.
+func NewEditor(editor, rpcName string, env env.Environment) *Editor {
+	return &Editor{
+		editor:  editor,
+		rpcName: rpcName,
+		env:     env,
+	}
+}
+
+func (i *Editor) Input(reqType entity.Message) (proto.Message, error) {
+	c := cache.Get()
+	content, ok := c.LastEditedRequests[i.rpcName]
+	if !ok {
+		content = Skeleton(i.rpcName, reqType)
+	}
+
+	edited, err := i.edit(content)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := ioutil.ReadAll(newCommentReader(strings.NewReader(edited)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the edited request")
+	}
+	if len(bytes.TrimSpace(req)) == 0 {
+		return nil, io.EOF
+	}
+	// The request is saved even if it is invalid to avoid losing it.
+	if err := c.SetLastEditedRequest(i.rpcName, edited).Save(); err != nil {
+		return nil, errors.Wrap(err, "failed to save the edited request")
+	}
+	return NewJSONFile(bytes.NewReader(req), i.env).Input(reqType)
+}
+
+// edit writes content to a temporary file, opens it with the editor, and returns the edited content.
+func (i *Editor) edit(content string) (string, error) {
+	f, err := ioutil.TempFile("", "evans-*.json")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a temporary file")
+	}
+	defer os.Remove(f.Name())
+	_, err = io.WriteString(f, content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write the request to the temporary file")
+	}
+
+	cmd := exec.Command(i.editor, f.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to execute %s", i.editor)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the edited request")
+	}
+	return string(b), nil
+}
+
+// Skeleton returns a JSON skeleton of the request message m with a header comment.
+// See pbusecase.MessageTemplate for details.
+func Skeleton(rpcName string, m entity.Message) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// the request of %s (%s)\n", rpcName, m.Name())
+	buf.WriteString("// lines which start with \"//\" are ignored. save an empty request to cancel.\n")
+	buf.WriteString(pbusecase.MessageTemplate(m))
+	return buf.String()
+}
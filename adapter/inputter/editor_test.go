@@ -0,0 +1,117 @@
+package inputter_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ktr0731/evans/adapter/inputter"
+	"github.com/ktr0731/evans/adapter/internal/testhelper"
+	"github.com/ktr0731/evans/adapter/protobuf"
+	"github.com/ktr0731/evans/cache"
+	"github.com/ktr0731/evans/meta"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkeleton(t *testing.T) {
+	cases := map[string]struct {
+		file     string
+		msgIdx   int
+		expected string
+	}{
+		"enum": {
+			file: "enum.proto",
+			expected: `// the request of example.Example.RPC (Book)
+// lines which start with "//" are ignored. save an empty request to cancel.
+{
+  // TYPE_ENUM: EARLY = 0, PHILOSOPHY = 1, HISTORY = 2, SCIENCE = 3
+  "type": "EARLY"
+}
+`,
+		},
+		"oneof": {
+			file:   "oneof.proto",
+			msgIdx: 2,
+			expected: `// the request of example.Example.RPC (BorrowRequest)
+// lines which start with "//" are ignored. save an empty request to cancel.
+{
+  // oneof borrowed: only one of book, cd can be set
+  // TYPE_MESSAGE Book
+  "book": {
+    // TYPE_STRING
+    "title": "",
+    // TYPE_STRING
+    "author": ""
+  }
+}
+`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := testhelper.ReadProtoAsFileDescriptors(t, c.file)
+			p, err := protobuf.ToEntitiesFrom(d)
+			require.NoError(t, err)
+
+			actual := inputter.Skeleton("example.Example.RPC", p[0].Messages[c.msgIdx])
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestEditor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// the cache may be already loaded by other tests.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, meta.AppName), 0755))
+
+	oldCacheHome := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Setenv("XDG_CACHE_HOME", oldCacheHome)
+
+	// newEditor returns a fake editor which overwrites the edited file by content.
+	// If content is empty, the fake editor doesn't modify the file.
+	newEditor := func(name, content string) string {
+		script := "#!/bin/sh\n"
+		if content != "" {
+			script += "printf '%s' '" + content + "' > \"$1\"\n"
+		}
+		p := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(p, []byte(script), 0755))
+		return p
+	}
+
+	d := testhelper.ReadProtoAsFileDescriptors(t, "enum.proto")
+	p, err := protobuf.ToEntitiesFrom(d)
+	require.NoError(t, err)
+	m := p[0].Messages[0]
+
+	const rpcName = "library.Library.Lend"
+
+	input := func(editor string) string {
+		res, err := inputter.NewEditor(editor, rpcName, nil).Input(m)
+		require.NoError(t, err)
+		s, err := (&jsonpb.Marshaler{EnumsAsInts: true}).MarshalToString(res)
+		require.NoError(t, err)
+		return s
+	}
+
+	// The skeleton is sent as it is.
+	require.Equal(t, `{}`, input(newEditor("noop", "")))
+
+	edited := `// comment
+{"type": "HISTORY"}`
+	require.Equal(t, `{"type":2}`, input(newEditor("edit", edited)))
+	require.Equal(t, edited, cache.Get().LastEditedRequests[rpcName])
+
+	// The last edited request is opened instead of the skeleton.
+	require.Equal(t, `{"type":2}`, input(newEditor("noop", "")))
+
+	_, err = inputter.NewEditor(newEditor("cancel", "// empty"), rpcName, nil).Input(m)
+	require.Equal(t, io.EOF, err)
+}
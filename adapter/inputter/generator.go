@@ -0,0 +1,139 @@
+package inputter
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// generate evaluates generators in s. A generator is an action of Go templates (text/template) like {{uuid}}.
+// Generators are evaluated every time a request is input, so they produce different values for each request.
+// The following functions are available.
+//
+//	uuid      a random UUID (version 4)
+//	now       the current time. It is formatted in RFC3339 like 2006-01-02T15:04:05Z
+//	add       adds the duration to the time (add <duration> <time>) like {{now | add "1h"}}
+//	randInt   a random integer between min and max inclusive (randInt <min> <max>)
+//	env       the value of the environment variable (env <name>)
+//	file      the content of the file (file <path>)
+//	base64    encodes the string in standard base64
+//	hex       encodes the string in hex
+//
+// If s doesn't contain "{{", s is returned as it is.
+// A literal "{{" is input by escaping it as "\{{" (or {{"{{"}} like other Go templates).
+func generate(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	s = strings.Replace(s, `\{{`, `{{"{{"}}`, -1)
+	tmpl, err := template.New("input").Funcs(generatorFuncs).Parse(s)
+	if err != nil {
+		return "", errors.Wrap(err, `invalid generator. a literal "{{" must be escaped as "\{{"`)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", errors.Wrap(err, "failed to evaluate generators")
+	}
+	return buf.String(), nil
+}
+
+// generateValue evaluates generators in strings of a decoded JSON value. See generate.
+func generateValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return generate(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			n, err := generateValue(e)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = n
+		}
+		return v, nil
+	case []interface{}:
+		for i, e := range v {
+			n, err := generateValue(e)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// generatedTime is a time which is formatted in RFC3339 by templates.
+type generatedTime struct {
+	time.Time
+}
+
+func (t generatedTime) String() string {
+	return t.Format(time.RFC3339Nano)
+}
+
+var generatorFuncs = template.FuncMap{
+	"uuid": newUUID,
+	"now": func() generatedTime {
+		return generatedTime{time.Now()}
+	},
+	"add": func(d string, t generatedTime) (generatedTime, error) {
+		v, err := time.ParseDuration(d)
+		if err != nil {
+			return generatedTime{}, err
+		}
+		return generatedTime{t.Add(v)}, nil
+	},
+	"randInt": func(min, max int64) (int64, error) {
+		if min > max {
+			return 0, errors.Errorf("min %d is greater than max %d", min, max)
+		}
+		n, err := rand.Int(rand.Reader, new(big.Int).Add(big.NewInt(max-min), big.NewInt(1)))
+		if err != nil {
+			return 0, err
+		}
+		return min + n.Int64(), nil
+	},
+	"env": os.Getenv,
+	"file": func(path string) (string, error) {
+		p, err := homedir.Expand(path)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to expand the file path")
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the file")
+		}
+		return string(b), nil
+	},
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"hex": func(s string) string {
+		return hex.EncodeToString([]byte(s))
+	},
+}
+
+// newUUID returns a random UUID (version 4) defined in RFC 4122.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errors.Wrap(err, "failed to generate a UUID")
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,80 @@
+package inputter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_generate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "foo.bin")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0644))
+
+	os.Setenv("EVANS_GENERATOR_TEST", "bar")
+	defer os.Unsetenv("EVANS_GENERATOR_TEST")
+
+	cases := map[string]struct {
+		in       string
+		expected string
+		assert   func(t *testing.T, s string)
+		hasErr   bool
+	}{
+		"no generators":   {in: "foo $bar", expected: "foo $bar"},
+		"env":             {in: `hi, {{env "EVANS_GENERATOR_TEST"}}`, expected: "hi, bar"},
+		"file":            {in: `{{file "` + path + `"}}`, expected: "foo"},
+		"file and base64": {in: `base64:{{file "` + path + `" | base64}}`, expected: "base64:Zm9v"},
+		"escaped braces":  {in: `\{{uuid}} {{"{{"}}}}`, expected: "{{uuid}} {{}}"},
+		"hex":             {in: `{{"foo" | hex}}`, expected: "666f6f"},
+		"uuid": {
+			in: "{{uuid}}",
+			assert: func(t *testing.T, s string) {
+				require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), s)
+			},
+		},
+		"now": {
+			in: `{{now | add "1h"}}`,
+			assert: func(t *testing.T, s string) {
+				v, err := time.Parse(time.RFC3339Nano, s)
+				require.NoError(t, err)
+				require.WithinDuration(t, time.Now().Add(time.Hour), v, time.Minute)
+			},
+		},
+		"randInt": {
+			in: "{{randInt 3 5}}",
+			assert: func(t *testing.T, s string) {
+				n, err := strconv.Atoi(s)
+				require.NoError(t, err)
+				require.True(t, 3 <= n && n <= 5, n)
+			},
+		},
+		"invalid range":    {in: "{{randInt 5 3}}", hasErr: true},
+		"invalid duration": {in: `{{now | add "1x"}}`, hasErr: true},
+		"unknown function": {in: "{{foo}}", hasErr: true},
+		"literal braces":   {in: `{{"a": 1}}`, hasErr: true},
+		"missing file":     {in: `{{file "` + filepath.Join(dir, "missing") + `"}}`, hasErr: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := generate(c.in)
+			if c.hasErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if c.assert != nil {
+				c.assert(t, actual)
+				return
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -1,30 +1,103 @@
 package inputter
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"io"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/adapter/protobuf"
 	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
 	"github.com/pkg/errors"
 )
 
 type JSONFile struct {
 	decoder *json.Decoder
+
+	// env is used to interpolate references to variables in string values.
+	// If env is nil, references are not interpolated.
+	// Generators like {{uuid}} in string values are always evaluated.
+	env env.Environment
 }
 
-func NewJSONFile(in io.Reader) *JSONFile {
+// NewJSONFile returns a new JSONFile which reads JSON messages from in.
+// Lines which start with "//" are ignored as comments.
+func NewJSONFile(in io.Reader, env env.Environment) *JSONFile {
 	return &JSONFile{
-		decoder: json.NewDecoder(in),
+		decoder: json.NewDecoder(newCommentReader(in)),
+		env:     env,
 	}
 }
 
 func (i *JSONFile) Input(reqType entity.Message) (proto.Message, error) {
-	req := protobuf.NewDynamicBuilder().NewMessage(reqType)
-	err := i.decoder.Decode(req)
+	var raw json.RawMessage
+	if err := i.decoder.Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "failed to read input from JSON")
+	}
+	raw, err := i.interpolate(raw)
 	if err != nil {
+		return nil, err
+	}
+
+	req := protobuf.NewDynamicBuilder().NewMessage(reqType)
+	if err := json.Unmarshal(raw, req); err != nil {
 		return nil, errors.Wrap(err, "failed to read input from JSON")
 	}
 	return req, nil
 }
+
+// interpolate evaluates generators in string values of raw, then replaces references to variables in them.
+// Generators are evaluated first so that values of variables are never evaluated as generators.
+func (i *JSONFile) interpolate(raw json.RawMessage) (json.RawMessage, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "failed to read input from JSON")
+	}
+	v, err := generateValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if i.env != nil {
+		v, err = env.InterpolateValue(i.env, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the interpolated input")
+	}
+	return b, nil
+}
+
+// commentReader is an io.Reader which skips lines which start with "//".
+// JSON strings cannot contain newlines, so such lines are always comments.
+type commentReader struct {
+	r   *bufio.Reader
+	buf []byte
+	err error
+}
+
+func newCommentReader(r io.Reader) *commentReader {
+	return &commentReader{r: bufio.NewReader(r)}
+}
+
+func (r *commentReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		var line []byte
+		line, r.err = r.r.ReadBytes('\n')
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("//")) {
+			r.buf = line
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
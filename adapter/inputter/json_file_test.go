@@ -5,10 +5,11 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/jsonpb"
+	"github.com/ktr0731/evans/adapter/inputter"
 	"github.com/ktr0731/evans/adapter/internal/testhelper"
 	"github.com/ktr0731/evans/adapter/protobuf"
+	"github.com/ktr0731/evans/entity/env"
 	"github.com/stretchr/testify/require"
-	"github.com/ktr0731/evans/adapter/inputter"
 )
 
 func TestJSONFileInputter(t *testing.T) {
@@ -30,7 +31,7 @@ func TestJSONFileInputter(t *testing.T) {
 	require.NoError(t, err)
 
 	in := bytes.NewReader([]byte(jsonInput))
-	inputter := inputter.NewJSONFile(in)
+	inputter := inputter.NewJSONFile(in, nil)
 	res, err := inputter.Input(m)
 	require.NoError(t, err)
 
@@ -40,3 +41,90 @@ func TestJSONFileInputter(t *testing.T) {
 
 	require.Exactly(t, actual, `{"name":"ktr","message":"hi"}`)
 }
+
+func TestJSONFileInputter_variables(t *testing.T) {
+	d := testhelper.ReadProtoAsFileDescriptors(t, "helloworld.proto")
+	p, err := protobuf.ToEntitiesFrom(d)
+	require.NoError(t, err)
+
+	m := p[0].Messages[0]
+
+	e := env.New(nil, nil)
+	e.SetVariable("last", map[string]interface{}{"message": "hello, ktr"})
+	e.SetVariable("user", "ktr")
+
+	in := bytes.NewReader([]byte(`{"name": "$user", "message": "$last.message!"}`))
+	inputter := inputter.NewJSONFile(in, e)
+	res, err := inputter.Input(m)
+	require.NoError(t, err)
+
+	marshaler := jsonpb.Marshaler{}
+	actual, err := marshaler.MarshalToString(res)
+	require.NoError(t, err)
+
+	require.Exactly(t, `{"name":"ktr","message":"hello, ktr!"}`, actual)
+}
+
+func TestJSONFileInputter_generators(t *testing.T) {
+	d := testhelper.ReadProtoAsFileDescriptors(t, "well_known.proto")
+	p, err := protobuf.ToEntitiesFrom(d)
+	require.NoError(t, err)
+
+	m := p[0].Messages[0]
+
+	in := bytes.NewReader([]byte(`{"start": "{{now}}", "capacity": "{{randInt 10 10}}", "note": "{{\"foo\" | hex}}"}`))
+	res, err := inputter.NewJSONFile(in, nil).Input(m)
+	require.NoError(t, err)
+
+	marshaler := jsonpb.Marshaler{}
+	actual, err := marshaler.MarshalToString(res)
+	require.NoError(t, err)
+
+	require.Regexp(t, `^\{"start":"[^"]+","capacity":10,"note":"666f6f"\}$`, actual)
+}
+
+func TestJSONFileInputter_generatorsInVariables(t *testing.T) {
+	d := testhelper.ReadProtoAsFileDescriptors(t, "helloworld.proto")
+	p, err := protobuf.ToEntitiesFrom(d)
+	require.NoError(t, err)
+
+	m := p[0].Messages[0]
+
+	e := env.New(nil, nil)
+	e.SetVariable("last", map[string]interface{}{"secret": `{{env "HOME"}}`})
+
+	in := bytes.NewReader([]byte(`{"name": "$last.secret", "message": "{{\"hi\" | hex}}, $last.secret"}`))
+	res, err := inputter.NewJSONFile(in, e).Input(m)
+	require.NoError(t, err)
+
+	marshaler := jsonpb.Marshaler{}
+	actual, err := marshaler.MarshalToString(res)
+	require.NoError(t, err)
+
+	require.Exactly(t, `{"name":"{{env \"HOME\"}}","message":"6869, {{env \"HOME\"}}"}`, actual)
+}
+
+func TestJSONFileInputter_comments(t *testing.T) {
+	d := testhelper.ReadProtoAsFileDescriptors(t, "helloworld.proto")
+	p, err := protobuf.ToEntitiesFrom(d)
+	require.NoError(t, err)
+
+	m := p[0].Messages[0]
+
+	in := bytes.NewReader([]byte(`// comment
+{
+  // TYPE_STRING
+  "name": "ktr",
+  "message": "// not a comment"
+}
+// comment`))
+	inputter := inputter.NewJSONFile(in, nil)
+	res, err := inputter.Input(m)
+	require.NoError(t, err)
+
+	marshaler := jsonpb.Marshaler{}
+	actual, err := marshaler.MarshalToString(res)
+	require.NoError(t, err)
+
+	require.Exactly(t, `{"name":"ktr","message":"// not a comment"}`, actual)
+}
@@ -3,14 +3,20 @@ package inputter
 import (
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/adapter/prompt"
 	"github.com/ktr0731/evans/adapter/protobuf"
+	"github.com/ktr0731/evans/cache"
 	"github.com/ktr0731/evans/color"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
+	"github.com/ktr0731/evans/usecase/port"
 	"github.com/pkg/errors"
 )
 
@@ -27,6 +33,15 @@ type PromptInputter struct {
 	prompt       prompt.Prompt
 	prefixFormat string
 	env          env.Environment
+
+	// rpcName is the fully-qualified name of the RPC which requests are input for.
+	// If it is not empty, input values are remembered per RPC and used as defaults of the next input.
+	rpcName string
+
+	// errOut is the destination of errors of invalid inputs.
+	errOut io.Writer
+	// out is the destination of previews of the request being input.
+	out io.Writer
 }
 
 func NewPrompt(prefixFormat string, env env.Environment) *PromptInputter {
@@ -38,16 +53,55 @@ func newPromptInputter(prompt prompt.Prompt, prefixFormat string, env env.Enviro
 		prompt:       prompt,
 		prefixFormat: prefixFormat,
 		env:          env,
+		errOut:       os.Stderr,
+		out:          os.Stdout,
 	}
 }
 
+// ForRPC returns a copy of i which remembers input values of the RPC.
+// It is an implementation of port.RPCInputter.
+func (i *PromptInputter) ForRPC(rpcName string) port.Inputter {
+	c := *i
+	c.rpcName = rpcName
+	return &c
+}
+
 // Input is an implementation of port.Inputter
 func (i *PromptInputter) Input(reqType entity.Message) (proto.Message, error) {
 	setter := protobuf.NewMessageSetter(reqType)
 	fields := reqType.Fields()
 
 	// DarkGreen is the initial color
-	return newFieldInputter(i.prompt, i.prefixFormat, setter, []string{}, false, false, color.DefaultColor()).Input(fields)
+	fi := newFieldInputter(i.prompt, i.prefixFormat, i.env, setter, []string{}, false, false, color.DefaultColor())
+	fi.errOut = i.errOut
+	fi.out = i.out
+	if i.rpcName != "" {
+		fi.values = &fieldValues{
+			last:    cache.Get().LastFieldValues[i.rpcName],
+			entered: map[string]string{},
+		}
+	}
+	req, err := fi.Input(fields)
+	if err != nil {
+		return nil, err
+	}
+	if fi.values != nil {
+		// the request is sent even if the values can't be saved.
+		if err := cache.Get().SetLastFieldValues(i.rpcName, fi.values.entered).Save(); err != nil {
+			logger.Printf("failed to save input values: %s", err)
+		}
+	}
+	return req, nil
+}
+
+// fieldValues holds input values of fields which are remembered per RPC.
+// Its keys are field paths like "foo::bar".
+type fieldValues struct {
+	// last holds values which were input last time. They are used as defaults.
+	last map[string]string
+	// entered holds values which are input this time.
+	// Empty values mean that remembered values are forgotten.
+	entered map[string]string
 }
 
 // fieldInputter inputs each fields of req in interactively
@@ -56,6 +110,9 @@ type fieldInputter struct {
 	prompt prompt.Prompt
 	setter *protobuf.MessageSetter
 
+	// env is used to interpolate references to variables in inputs.
+	env env.Environment
+
 	prefixFormat string
 	ancestor     []string
 
@@ -70,11 +127,27 @@ type fieldInputter struct {
 
 	// The field has parent fields and one or more fields is/are repeated.
 	hasAncestorAndHasRepeatedField bool
+
+	// values remembers input values. If it is nil, values are not remembered.
+	// Values of repeated fields and fields of repeated messages are never remembered.
+	values *fieldValues
+
+	// errOut is the destination of errors of invalid inputs. Invalid inputs are input again.
+	errOut io.Writer
+	// out is the destination of previews. See preview.
+	out io.Writer
+
+	// parent is the fieldInputter of the message which has the message being input by this fieldInputter.
+	// field is the field of the parent. They are nil if this fieldInputter inputs the request itself.
+	// field is also nil if this fieldInputter inputs a value of a map entry.
+	parent *fieldInputter
+	field  entity.Field
 }
 
 func newFieldInputter(
 	prompter prompt.Prompt,
 	prefixFormat string,
+	env env.Environment,
 	setter *protobuf.MessageSetter,
 	ancestor []string,
 	hasAncestorAndHasRepeatedField bool,
@@ -85,6 +158,7 @@ func newFieldInputter(
 		prompt:                         prompter,
 		setter:                         setter,
 		prefixFormat:                   prefixFormat,
+		env:                            env,
 		ancestor:                       ancestor,
 		color:                          color,
 		hasAncestorAndHasRepeatedField: hasAncestorAndHasRepeatedField,
@@ -103,35 +177,67 @@ func newFieldInputter(
 // Input is called two times
 // one for Foo's primitive fields
 // another one for bar's primitive fields
+//
+// If prompt.ErrBack is returned while inputting a field, the previous field is cleared and input again.
+// If the field is the first one, Input returns prompt.ErrBack to go back to the parent message.
+// If prompt.ErrSkip is returned, the remaining fields are skipped.
 func (i *fieldInputter) Input(fields []entity.Field) (proto.Message, error) {
 	if err := i.prompt.SetPrefixColor(i.color); err != nil {
 		return nil, err
 	}
 
-	for _, field := range fields {
-		if field.IsRepeated() {
-			if err := i.inputRepeatedField(field); err != nil {
+	for n := 0; n < len(fields); {
+		err := i.inputFieldOf(fields[n])
+		switch err {
+		case nil:
+			n++
+		case prompt.ErrBack:
+			if err := i.setter.ClearField(fields[n]); err != nil {
 				return nil, err
 			}
-		} else {
-			// if oneof, choose one from selection
-			if oneof, ok := field.(entity.OneOfField); ok {
-				var err error
-				field, err = i.chooseOneof(oneof)
-				if err != nil {
-					return nil, err
+			if n == 0 {
+				// the request itself has no previous field, so the first field is input again.
+				if i.parent != nil {
+					return nil, prompt.ErrBack
 				}
+				continue
 			}
-
-			if err := i.inputField(field); err != nil {
+			n--
+			if err := i.setter.ClearField(fields[n]); err != nil {
 				return nil, err
 			}
+		case prompt.ErrSkip:
+			return i.setter.Done(), nil
+		default:
+			return nil, err
 		}
 	}
 
 	return i.setter.Done(), nil
 }
 
+// inputFieldOf inputs field. If field is a oneof field, one of its choices is input.
+func (i *fieldInputter) inputFieldOf(field entity.Field) error {
+	if field.IsRepeated() {
+		return i.inputRepeatedField(field)
+	}
+	// if oneof, choose one from selection
+	if oneof, ok := field.(entity.OneOfField); ok {
+		var err error
+		field, err = i.chooseOneof(oneof)
+		if err != nil {
+			return err
+		}
+		if field == nil {
+			return nil
+		}
+	}
+	return i.inputField(field)
+}
+
+// skipOneof is the option to leave a oneof field unset.
+const skipOneof = "(skip)"
+
 func (i *fieldInputter) chooseOneof(oneof entity.OneOfField) (entity.Field, error) {
 	options := make([]string, 0, len(oneof.Choices()))
 	fieldOf := map[string]entity.Field{}
@@ -139,11 +245,16 @@ func (i *fieldInputter) chooseOneof(oneof entity.OneOfField) (entity.Field, erro
 		options = append(options, choice.FieldName())
 		fieldOf[choice.FieldName()] = choice
 	}
+	options = append(options, skipOneof)
 
-	choice, err := i.prompt.Select(oneof.FieldName(), options)
+	choice, err := i.prompt.SelectWithDefault(oneof.FieldName(), options, i.lastValue(oneof))
 	if err != nil {
 		return nil, err
 	}
+	i.remember(oneof, choice)
+	if choice == skipOneof {
+		return nil, nil
+	}
 
 	f, ok := fieldOf[choice]
 	if !ok {
@@ -153,15 +264,24 @@ func (i *fieldInputter) chooseOneof(oneof entity.OneOfField) (entity.Field, erro
 	return f, nil
 }
 
+// chooseEnum selects a value of enum. Options are labeled with their numbers
+// so that values can be filtered by either names or numbers.
+// A choice is also accepted if it is a name or a number instead of a label.
 func (i *fieldInputter) chooseEnum(enum entity.EnumField) (entity.EnumValue, error) {
 	options := make([]string, 0, len(enum.Values()))
 	valOf := map[string]entity.EnumValue{}
 	for _, v := range enum.Values() {
-		options = append(options, v.Name())
+		options = append(options, enumLabel(v))
+		valOf[enumLabel(v)] = v
 		valOf[v.Name()] = v
+		valOf[strconv.Itoa(int(v.Number()))] = v
 	}
 
-	choice, err := i.prompt.Select(enum.Name(), options)
+	var def string
+	if v, ok := valOf[i.lastValue(enum)]; ok {
+		def = enumLabel(v)
+	}
+	choice, err := i.prompt.SelectWithDefault(enum.Name(), options, def)
 	if err != nil {
 		return nil, err
 	}
@@ -170,12 +290,22 @@ func (i *fieldInputter) chooseEnum(enum entity.EnumField) (entity.EnumValue, err
 	if !ok {
 		return nil, errors.Wrap(ErrUnknownEnumName, choice)
 	}
+	i.remember(enum, c.Name())
 
 	return c, nil
 }
 
+// enumLabel returns the option label of v like "PHILOSOPHY (1)".
+func enumLabel(v entity.EnumValue) string {
+	return fmt.Sprintf("%s (%d)", v.Name(), v.Number())
+}
+
 func (i *fieldInputter) inputField(field entity.Field) error {
 	switch f := field.(type) {
+	case entity.MapField:
+		if err := i.inputMapField(f); err != nil {
+			return err
+		}
 	case entity.EnumField:
 		v, err := i.chooseEnum(f)
 		if err != nil {
@@ -185,6 +315,15 @@ func (i *fieldInputter) inputField(field entity.Field) error {
 			return err
 		}
 	case entity.MessageField:
+		// well-known types are input as a single value like primitive fields.
+		if f.WellKnownType() != "" {
+			i.prompt.SetPrefix(i.makePrefix(f))
+			in, err := i.inputPrimitiveField(f)
+			if err != nil {
+				return err
+			}
+			return i.setter.SetField(f, in)
+		}
 		if f.IsCycled() {
 			prefix := strings.Join(i.ancestor, ancestorDelimiter)
 			if prefix != "" {
@@ -211,15 +350,22 @@ func (i *fieldInputter) inputField(field entity.Field) error {
 		setter := protobuf.NewMessageSetter(f)
 		fields := f.Fields()
 
-		msg, err := newFieldInputter(
+		fi := newFieldInputter(
 			i.prompt,
 			i.prefixFormat,
+			i.env,
 			setter,
 			append(i.ancestor, f.FieldName()),
 			i.hasAncestorAndHasRepeatedField || f.IsRepeated(),
 			f.IsCycled(),
 			i.color,
-		).Input(fields)
+		)
+		fi.values = i.values
+		fi.errOut = i.errOut
+		fi.out = i.out
+		fi.parent = i
+		fi.field = f
+		msg, err := fi.Input(fields)
 		if err != nil {
 			return err
 		}
@@ -249,27 +395,142 @@ func (i *fieldInputter) inputRepeatedField(f entity.Field) error {
 	}()
 	// if repeated fields, create new prompt.
 	// and the prompt will be terminate with ctrl+d.
+	// prompt.ErrBack removes the last element, or goes back to the previous field if there is no element.
+	var n int
 	for {
 		i.prompt = prompt.New(nil, nil)
 		if err := i.prompt.SetPrefixColor(i.color); err != nil {
 			return err
 		}
 
-		if err := i.inputField(f); err == EORF || err == io.EOF {
+		err := i.inputField(f)
+		switch {
+		case err == EORF || err == io.EOF:
 			return nil
-		} else if err != nil {
+		case err == prompt.ErrBack && n > 0:
+			if err := i.setter.RemoveLastRepeatedField(f); err != nil {
+				return err
+			}
+			n--
+			continue
+		case err != nil:
 			return err
 		}
 
+		n++
+		i.color.Next()
+	}
+}
+
+// inputMapField inputs entries of the map field f.
+// A key is input first, then its value. Inputting is finished if the key is empty.
+// prompt.ErrBack at a value inputs its key again. At a key, it removes the last entry,
+// or goes back to the previous field if there is no entry.
+func (i *fieldInputter) inputMapField(f entity.MapField) error {
+	ancestor := make([]string, 0, len(i.ancestor)+1)
+	ancestor = append(append(ancestor, i.ancestor...), f.FieldName())
+	var keys []interface{}
+	for {
+		i.prompt.SetPrefix(makePrefix(i.prefixFormat, f.Key(), ancestor, false, ""))
+		i.prompt.SetCompleter(i.completer(f.Key(), ""))
+		in, err := i.input()
+		if err == prompt.ErrClear {
+			// map keys don't have defaults.
+			in, err = "", nil
+		}
+		if err == prompt.ErrBack && len(keys) > 0 {
+			if err := i.setter.RemoveMapEntry(f, keys[len(keys)-1]); err != nil {
+				return err
+			}
+			keys = keys[:len(keys)-1]
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if in == "" {
+			return nil
+		}
+		k, err := i.convert(f.Key(), in)
+		if err != nil {
+			fmt.Fprintf(i.errOut, "invalid input: %s\n", err)
+			continue
+		}
+
+		v, err := i.inputMapValue(f.Value(), ancestor)
+		if err == prompt.ErrBack {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := i.setter.PutMapEntry(f, k, v); err != nil {
+			return err
+		}
+		keys = append(keys, k)
 		i.color.Next()
 	}
 }
 
+// inputMapValue inputs a value of a map entry.
+// Unlike inputField, the value is returned instead of being set to the setter.
+func (i *fieldInputter) inputMapValue(f entity.Field, ancestor []string) (interface{}, error) {
+	switch f := f.(type) {
+	case entity.EnumField:
+		v, err := i.chooseEnum(f)
+		if err != nil {
+			return nil, err
+		}
+		return v.Number(), nil
+	case entity.MessageField:
+		if f.WellKnownType() != "" {
+			i.prompt.SetPrefix(makePrefix(i.prefixFormat, f, ancestor, false, ""))
+			in, err := i.mapValueInputter(nil, ancestor, false).inputPrimitiveField(f)
+			if err != nil {
+				return nil, err
+			}
+			m, err := protobuf.ConvertWellKnownValue(in.(string), f)
+			if err != nil {
+				return nil, err
+			}
+			if m == nil {
+				// map values cannot be nil.
+				return protobuf.NewMessageSetter(f).Done(), nil
+			}
+			return m, nil
+		}
+		return i.mapValueInputter(protobuf.NewMessageSetter(f), append(ancestor, f.FieldName()), f.IsCycled()).Input(f.Fields())
+	default:
+		i.prompt.SetPrefix(makePrefix(i.prefixFormat, f, ancestor, false, ""))
+		return i.mapValueInputter(nil, ancestor, false).inputPrimitiveField(f)
+	}
+}
+
+// mapValueInputter returns a fieldInputter which inputs a value of a map entry.
+// Values of map entries are never remembered.
+func (i *fieldInputter) mapValueInputter(setter *protobuf.MessageSetter, ancestor []string, hasDirectCycledParent bool) *fieldInputter {
+	fi := newFieldInputter(i.prompt, i.prefixFormat, i.env, setter, ancestor, false, hasDirectCycledParent, i.color)
+	fi.errOut = i.errOut
+	fi.out = i.out
+	fi.parent = i
+	return fi
+}
+
+// inputPrimitiveField inputs a value of f. If the input is empty, the last input value is used.
+// prompt.ErrClear inputs an empty value without the last input value, and forgets it.
 func (i *fieldInputter) inputPrimitiveField(f entity.PrimitiveField) (interface{}, error) {
-	in, err := i.prompt.Input()
+	i.prompt.SetCompleter(i.completer(f, i.lastValue(f)))
+	in, err := i.input()
+	cleared := err == prompt.ErrClear
+	if cleared {
+		in, err = "", nil
+	}
 	if err != nil {
 		return "", err
 	}
+	if in == "" && !cleared {
+		in = i.lastValue(f)
+	}
 
 	// Empty input. See enteredEmptyInput field comments for the behavior
 	// when empty input is entered.
@@ -290,12 +551,179 @@ func (i *fieldInputter) inputPrimitiveField(f entity.PrimitiveField) (interface{
 		i.enteredEmptyInput = false
 	}
 
+	v, err := i.convert(f, in)
+	if err != nil {
+		// the field is input again instead of aborting the whole request.
+		fmt.Fprintf(i.errOut, "invalid input: %s\n", err)
+		return i.inputPrimitiveField(f)
+	}
+	if cleared {
+		i.forget(f)
+	} else {
+		i.remember(f, in)
+	}
+	return v, nil
+}
+
+// input reads an input from the prompt. If prompt.ErrPreview is returned,
+// it shows the preview and reads the input again.
+func (i *fieldInputter) input() (string, error) {
+	for {
+		in, err := i.prompt.Input()
+		if err != prompt.ErrPreview {
+			return in, err
+		}
+		if err := i.preview(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// preview writes the request which is built from the fields input so far in JSON.
+func (i *fieldInputter) preview() error {
+	var (
+		msg   proto.Message
+		field entity.Field
+	)
+	// build messages from the current one to the request.
+	for c := i; c != nil; c = c.parent {
+		if c.setter != nil {
+			s := c.setter.Clone()
+			if msg != nil && field != nil {
+				if err := s.SetField(field, msg); err != nil {
+					return err
+				}
+			}
+			msg = s.Done()
+		}
+		field = c.field
+	}
+	if msg == nil {
+		return nil
+	}
+	s, err := (&jsonpb.Marshaler{Indent: "  "}).MarshalToString(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the preview")
+	}
+	fmt.Fprintf(i.out, "preview:\n%s\n", s)
+	return nil
+}
+
+// convert evaluates generators like {{uuid}} in in, interpolates references to variables in it,
+// and converts it to a value of f.
+// Generators are evaluated before the interpolation so that values of variables are never evaluated as generators.
+// Values of well-known types are only validated. They are converted by the setter.
+func (i *fieldInputter) convert(f entity.PrimitiveField, in string) (interface{}, error) {
+	in, err := generate(in)
+	if err != nil {
+		return nil, err
+	}
+	if i.env != nil {
+		in, err = env.Interpolate(i.env, in)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m, ok := f.(entity.MessageField); ok {
+		if _, err := protobuf.ConvertWellKnownValue(in, m); err != nil {
+			return nil, err
+		}
+		return in, nil
+	}
 	return protobuf.ConvertValue(in, f)
 }
 
+// completer returns the completer of f. last is the last input value of f.
+func (i *fieldInputter) completer(f entity.Field, last string) func(string) []prompt.Suggestion {
+	return func(text string) []prompt.Suggestion {
+		return i.suggestions(f, text, last)
+	}
+}
+
+// suggestions returns completion candidates of f which replace the last word of text.
+// References to variables are completed in any position. Other candidates
+// like bool values and the last input value are completed only at the beginning.
+func (i *fieldInputter) suggestions(f entity.Field, text, last string) []prompt.Suggestion {
+	word := text
+	if n := strings.LastIndexAny(text, " \t"); n != -1 {
+		word = text[n+1:]
+	}
+
+	var s []prompt.Suggestion
+	if strings.HasPrefix(word, "$") {
+		if i.env == nil {
+			return nil
+		}
+		for _, ref := range env.CompleteReference(i.env, word) {
+			s = append(s, prompt.Suggestion{Text: ref, Description: "variable"})
+		}
+		return s
+	}
+	if word != text {
+		return nil
+	}
+
+	var candidates []prompt.Suggestion
+	if last != "" {
+		candidates = append(candidates, prompt.Suggestion{Text: last, Description: "last input"})
+	}
+	if isBoolField(f) {
+		candidates = append(candidates, prompt.Suggestion{Text: "true"}, prompt.Suggestion{Text: "false"})
+	}
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if !seen[c.Text] && strings.HasPrefix(c.Text, word) {
+			s = append(s, c)
+		}
+		seen[c.Text] = true
+	}
+	return s
+}
+
+func isBoolField(f entity.Field) bool {
+	if m, ok := f.(entity.MessageField); ok {
+		return m.WellKnownType() == "google.protobuf.BoolValue"
+	}
+	return f.PBType() == "TYPE_BOOL"
+}
+
 // makePrefix makes prefix for field f.
 func (i *fieldInputter) makePrefix(f entity.PrimitiveField) string {
-	return makePrefix(i.prefixFormat, f, i.ancestor, i.hasAncestorAndHasRepeatedField)
+	return makePrefix(i.prefixFormat, f, i.ancestor, i.hasAncestorAndHasRepeatedField, i.lastValue(f))
+}
+
+// lastValue returns the value of f which was input last time.
+// It returns an empty string if there is no value or f is not remembered.
+func (i *fieldInputter) lastValue(f entity.Field) string {
+	if !i.remembers(f) {
+		return ""
+	}
+	return i.values.last[i.fieldPath(f)]
+}
+
+// remember remembers v as the input value of f. Empty values are ignored.
+func (i *fieldInputter) remember(f entity.Field, v string) {
+	if v == "" || !i.remembers(f) {
+		return
+	}
+	i.values.entered[i.fieldPath(f)] = v
+}
+
+// forget forgets the remembered value of f.
+func (i *fieldInputter) forget(f entity.Field) {
+	if !i.remembers(f) {
+		return
+	}
+	i.values.entered[i.fieldPath(f)] = ""
+}
+
+func (i *fieldInputter) remembers(f entity.Field) bool {
+	return i.values != nil && !f.IsRepeated() && !i.hasAncestorAndHasRepeatedField
+}
+
+// fieldPath returns the path of f from the request message like "foo::bar".
+func (i *fieldInputter) fieldPath(f entity.Field) string {
+	return strings.Join(append(append([]string{}, i.ancestor...), f.FieldName()), ancestorDelimiter)
 }
 
 const (
@@ -303,7 +731,9 @@ const (
 	ancestorDelimiter = "::"
 )
 
-func makePrefix(s string, f entity.PrimitiveField, ancestor []string, ancestorHasRepeated bool) string {
+// makePrefix replaces placeholders in s. If def is not empty, it is shown as the default value
+// at {default}, or at the end of the prefix if s doesn't have {default}.
+func makePrefix(s string, f entity.PrimitiveField, ancestor []string, ancestorHasRepeated bool, def string) string {
 	joinedAncestor := strings.Join(ancestor, ancestorDelimiter)
 	if joinedAncestor != "" {
 		joinedAncestor += ancestorDelimiter
@@ -311,7 +741,20 @@ func makePrefix(s string, f entity.PrimitiveField, ancestor []string, ancestorHa
 
 	s = strings.Replace(s, "{ancestor}", joinedAncestor, -1)
 	s = strings.Replace(s, "{name}", f.FieldName(), -1)
-	s = strings.Replace(s, "{type}", f.PBType(), -1)
+	typ := f.PBType()
+	if m, ok := f.(entity.MessageField); ok && m.WellKnownType() != "" {
+		typ = m.WellKnownType()
+	}
+	s = strings.Replace(s, "{type}", typ, -1)
+	switch {
+	case strings.Contains(s, "{default}"):
+		if def != "" {
+			def = fmt.Sprintf(" [%s]", def)
+		}
+		s = strings.Replace(s, "{default}", def, -1)
+	case def != "":
+		s += fmt.Sprintf("[%s] ", def)
+	}
 
 	if f.IsRepeated() || ancestorHasRepeated {
 		return repeatedStr + s
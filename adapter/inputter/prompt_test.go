@@ -1,16 +1,27 @@
 package inputter
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	goprompt "github.com/c-bata/go-prompt"
+	"github.com/golang/protobuf/proto"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/ktr0731/evans/adapter/internal/testhelper"
 	"github.com/ktr0731/evans/adapter/prompt"
 	"github.com/ktr0731/evans/adapter/protobuf"
+	"github.com/ktr0731/evans/cache"
+	"github.com/ktr0731/evans/color"
+	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/entity/testentity"
+	"github.com/ktr0731/evans/meta"
 	"github.com/ktr0731/evans/tests/helper"
+	"github.com/ktr0731/evans/usecase/port"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -36,6 +47,44 @@ func TestPrompt_Input(t *testing.T) {
 		require.Equal(t, `name:"rin" message:"shima"`, msg.String())
 	})
 
+	t.Run("normal/variables", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "helloworld.proto", "helloworld", "Greeter")
+		env.SetVariable("last", map[string]interface{}{"name": "rin"})
+
+		p := helper.NewMockPrompt([]string{"$last.name", "hi, $last.name"}, nil)
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		rpc, err := env.RPC("SayHello")
+		require.NoError(t, err)
+
+		dmsg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+
+		msg, ok := dmsg.(*dynamic.Message)
+		require.True(t, ok)
+
+		require.Equal(t, `name:"rin" message:"hi, rin"`, msg.String())
+	})
+
+	t.Run("normal/generators", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "helloworld.proto", "helloworld", "Greeter")
+		env.SetVariable("name", "rin")
+		// values of variables are never evaluated as generators.
+		env.SetVariable("last", map[string]interface{}{"secret": `{{env "HOME"}}`})
+
+		p := helper.NewMockPrompt([]string{`{{"rin" | hex}} $name`, `$last.secret {{randInt 1 1}}`}, nil)
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		rpc, err := env.RPC("SayHello")
+		require.NoError(t, err)
+
+		msg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+
+		require.Equal(t, "72696e rin", msg.(*dynamic.Message).GetFieldByName("name"))
+		require.Equal(t, `{{env "HOME"}} 1`, msg.(*dynamic.Message).GetFieldByName("message"))
+	})
+
 	t.Run("normal/nested_message", func(t *testing.T) {
 		env := testhelper.SetupEnv(t, "nested.proto", "library", "Library")
 
@@ -74,6 +123,26 @@ func TestPrompt_Input(t *testing.T) {
 		require.Equal(t, `type:PHILOSOPHY`, msg.String())
 	})
 
+	t.Run("normal/enum:label and number", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "enum.proto", "library", "")
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "enum.proto")
+		packages, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := packages[0].Messages[0]
+
+		for choice, expected := range map[string]string{
+			"HISTORY (2)": `type:HISTORY`,
+			"3":           `type:SCIENCE`,
+		} {
+			p := &enumOptionsPrompt{MockPrompt: helper.NewMockPrompt(nil, []string{choice})}
+			dmsg, err := newPromptInputter(p, prefixFormat, env).Input(m)
+			require.NoError(t, err)
+			require.Equal(t, expected, dmsg.String())
+			require.Equal(t, []string{"EARLY (0)", "PHILOSOPHY (1)", "HISTORY (2)", "SCIENCE (3)"}, p.opts)
+		}
+	})
+
 	t.Run("error/enum:invalid enum name", func(t *testing.T) {
 		env := testhelper.SetupEnv(t, "enum.proto", "library", "")
 
@@ -157,8 +226,46 @@ func TestPrompt_Input(t *testing.T) {
 	t.Run("normal/map", func(t *testing.T) {
 		env := testhelper.SetupEnv(t, "map.proto", "example", "")
 
+		prompt := helper.NewMockPrompt([]string{"foo", "bar", "hoge", "fuga", ""}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "map.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[0]
+		require.Equal(t, "PrimitiveRequest", m.Name())
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, map[interface{}]interface{}{"foo": "bar", "hoge": "fuga"}, msg.(*dynamic.Message).GetFieldByName("foo"))
+	})
+
+	t.Run("normal/map val is message", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "map.proto", "example", "")
+
+		prompt := helper.NewMockPrompt([]string{"key", "val1", "3", ""}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "map.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[2]
+		require.Equal(t, "MessageRequest", m.Name())
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, `foo:<key:"key" value:<fuga:"val1" piyo:3>>`, msg.String())
+	})
+
+	t.Run("normal/map with repeated prompt", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "map.proto", "example", "")
+
 		prompt := helper.NewMockRepeatedPrompt([][]string{
-			{"foo", "", "bar", "", ""},
+			{"foo", "bar"},
+			{"hoge", ""},
+			{""},
 		}, nil)
 
 		cleanup := injectNewPrompt(prompt)
@@ -175,14 +282,16 @@ func TestPrompt_Input(t *testing.T) {
 		msg, err := inputter.Input(m)
 		require.NoError(t, err)
 
-		require.Equal(t, `foo:<key:"foo" value:"bar">`, msg.String())
+		require.Equal(t, map[interface{}]interface{}{"foo": "bar", "hoge": ""}, msg.(*dynamic.Message).GetFieldByName("foo"))
 	})
 
-	t.Run("normal/map val is message", func(t *testing.T) {
+	t.Run("normal/map val is message with repeated prompt", func(t *testing.T) {
 		env := testhelper.SetupEnv(t, "map.proto", "example", "")
 
 		prompt := helper.NewMockRepeatedPrompt([][]string{
-			{"key", "", "val1", "3", "", ""},
+			{"key", "val1", "3"},
+			{"key2", "", "0"},
+			{""},
 		}, nil)
 
 		cleanup := injectNewPrompt(prompt)
@@ -199,7 +308,185 @@ func TestPrompt_Input(t *testing.T) {
 		msg, err := inputter.Input(m)
 		require.NoError(t, err)
 
-		require.Equal(t, `foo:<key:"key" value:<fuga:"val1" piyo:3>>`, msg.String())
+		foo := msg.(*dynamic.Message).GetFieldByName("foo").(map[interface{}]interface{})
+		require.Len(t, foo, 2)
+		require.Equal(t, `fuga:"val1" piyo:3`, foo["key"].(proto.Message).String())
+		require.Equal(t, ``, foo["key2"].(proto.Message).String())
+	})
+
+	t.Run("normal/map empty key", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "map.proto", "example", "")
+
+		prompt := helper.NewMockPrompt([]string{""}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "map.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[2]
+		require.Equal(t, "MessageRequest", m.Name())
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		// an empty key finishes the map field without inputting the value.
+		require.Len(t, prompt.History(), 1)
+		require.Empty(t, msg.(*dynamic.Message).GetFieldByName("foo"))
+	})
+
+	t.Run("normal/invalid inputs are input again", func(t *testing.T) {
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "well_known.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[0]
+
+		prompt := helper.NewMockPrompt([]string{"yesterday", "2019-01-02T03:04:05Z", "1h30m", "ten", "10", ""}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, nil)
+		var errOut bytes.Buffer
+		inputter.errOut = &errOut
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, `start:<seconds:1546398245> duration:<seconds:5400> capacity:<value:10>`, msg.String())
+		require.Equal(t, 2, strings.Count(errOut.String(), "invalid input: "))
+	})
+
+	t.Run("normal/back", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "nested.proto", "library", "Library")
+
+		p := helper.NewMockPrompt([]string{
+			"eriri", helper.InputBack, "megumi",
+			"spencer", helper.InputBack, helper.InputBack,
+			"kato", "spencer", "sawamura",
+		}, nil)
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		rpc, err := env.RPC("BorrowBook")
+		require.NoError(t, err)
+
+		msg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+
+		require.Equal(t, `person:<name:"kato"> book:<title:"spencer" author:"sawamura">`, msg.String())
+	})
+
+	t.Run("normal/back (repeated)", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "repeated.proto", "helloworld", "")
+
+		p := helper.NewMockRepeatedPrompt([][]string{
+			{"foo", "bar", helper.InputBack, "baz", "", ""},
+		}, nil)
+
+		cleanup := injectNewPrompt(p)
+		defer cleanup()
+
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "repeated.proto")
+		packages, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := packages[0].Messages[0]
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, `name:"foo" name:"baz"`, msg.String())
+	})
+
+	t.Run("normal/back (map)", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "map.proto", "example", "")
+
+		prompt := helper.NewMockPrompt([]string{
+			"foo", "bar", "hoge", helper.InputBack, helper.InputBack, "piyo", "fuga", "",
+		}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "map.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[0]
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, map[interface{}]interface{}{"piyo": "fuga"}, msg.(*dynamic.Message).GetFieldByName("foo"))
+	})
+
+	t.Run("normal/skip", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "nested.proto", "library", "Library")
+
+		p := helper.NewMockPrompt([]string{"eriri", "spencer", helper.InputSkip}, nil)
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		rpc, err := env.RPC("BorrowBook")
+		require.NoError(t, err)
+
+		msg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+
+		require.Equal(t, `person:<name:"eriri"> book:<title:"spencer">`, msg.String())
+	})
+
+	t.Run("normal/skip oneof", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "oneof.proto", "shop", "")
+
+		p := helper.NewMockPrompt(nil, []string{skipOneof})
+		inputter := newPromptInputter(p, prefixFormat, env)
+
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "oneof.proto")
+		packages, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := packages[0].Messages[2]
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, ``, msg.String())
+	})
+
+	t.Run("normal/preview", func(t *testing.T) {
+		env := testhelper.SetupEnv(t, "nested.proto", "library", "Library")
+
+		p := helper.NewMockPrompt([]string{"eriri", "spencer", helper.InputPreview, "sawamura"}, nil)
+		inputter := newPromptInputter(p, prefixFormat, env)
+		var out bytes.Buffer
+		inputter.out = &out
+
+		rpc, err := env.RPC("BorrowBook")
+		require.NoError(t, err)
+
+		msg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+
+		require.Equal(t, `person:<name:"eriri"> book:<title:"spencer" author:"sawamura">`, msg.String())
+		expected := `preview:
+{
+  "person": {
+    "name": "eriri"
+  },
+  "book": {
+    "title": "spencer"
+  }
+}
+`
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("normal/well-known types", func(t *testing.T) {
+		descs := testhelper.ReadProtoAsFileDescriptors(t, "well_known.proto")
+		p, err := protobuf.ToEntitiesFrom(descs)
+		require.NoError(t, err)
+		m := p[0].Messages[0]
+		require.Equal(t, "Event", m.Name())
+
+		prompt := helper.NewMockPrompt([]string{"2019-01-02T03:04:05Z", "1h30m", "10", ""}, nil)
+		inputter := newPromptInputter(prompt, prefixFormat, nil)
+
+		msg, err := inputter.Input(m)
+		require.NoError(t, err)
+
+		require.Equal(t, `start:<seconds:1546398245> duration:<seconds:5400> capacity:<value:10>`, msg.String())
 	})
 
 	t.Run("normal/circulated", func(t *testing.T) {
@@ -249,6 +536,108 @@ func TestPrompt_Input(t *testing.T) {
 	})
 }
 
+func TestPrompt_Input_rememberValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, meta.AppName), 0755))
+
+	oldCacheHome := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Setenv("XDG_CACHE_HOME", oldCacheHome)
+
+	const rpcName = "helloworld.Greeter.SayHello"
+	env := testhelper.SetupEnv(t, "helloworld.proto", "helloworld", "Greeter")
+	rpc, err := env.RPC("SayHello")
+	require.NoError(t, err)
+
+	input := func(inputter port.Inputter) string {
+		dmsg, err := inputter.Input(rpc.RequestMessage())
+		require.NoError(t, err)
+		return dmsg.String()
+	}
+
+	env.SetVariable("greeting", "hi")
+	p := helper.NewMockPrompt([]string{"rin", "$greeting"}, nil)
+	require.Equal(t, `name:"rin" message:"hi"`, input(newPromptInputter(p, ">", env).ForRPC(rpcName)))
+	require.Equal(t, map[string]string{"name": "rin", "message": "$greeting"}, cache.Get().LastFieldValues[rpcName])
+
+	// empty inputs accept the last values. references to variables are interpolated again.
+	env.SetVariable("greeting", "hello")
+	p = helper.NewMockPrompt([]string{"", ""}, nil)
+	require.Equal(t, `name:"rin" message:"hello"`, input(newPromptInputter(p, ">", env).ForRPC(rpcName)))
+
+	// values are not remembered if the RPC is unknown.
+	p = helper.NewMockPrompt([]string{"nadeshiko", ""}, nil)
+	require.Equal(t, `name:"nadeshiko"`, input(newPromptInputter(p, ">", env)))
+	require.Equal(t, "rin", cache.Get().LastFieldValues[rpcName]["name"])
+
+	// a cleared field is input as empty, and its value is forgotten.
+	p = helper.NewMockPrompt([]string{"", helper.InputClear}, nil)
+	require.Equal(t, `name:"rin"`, input(newPromptInputter(p, ">", env).ForRPC(rpcName)))
+	require.Equal(t, map[string]string{"name": "rin"}, cache.Get().LastFieldValues[rpcName])
+
+	cache.Get().ClearLastFieldValues(rpcName)
+	p = helper.NewMockPrompt([]string{"", ""}, nil)
+	require.Equal(t, ``, input(newPromptInputter(p, ">", env).ForRPC(rpcName)))
+
+	// the request is input even if the values can't be saved.
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, meta.AppName)))
+	p = helper.NewMockPrompt([]string{"rin", ""}, nil)
+	require.Equal(t, `name:"rin"`, input(newPromptInputter(p, ">", env).ForRPC(rpcName)))
+}
+
+// enumOptionsPrompt records options of the selection.
+type enumOptionsPrompt struct {
+	*helper.MockPrompt
+	opts []string
+}
+
+func (p *enumOptionsPrompt) SelectWithDefault(msg string, opts []string, def string) (string, error) {
+	p.opts = opts
+	return p.MockPrompt.SelectWithDefault(msg, opts, def)
+}
+
+func Test_fieldInputter_suggestions(t *testing.T) {
+	env := testhelper.SetupEnv(t, "helloworld.proto", "helloworld", "Greeter")
+	env.SetVariable("last", map[string]interface{}{"name": "rin", "names": []interface{}{"rin"}})
+	i := newFieldInputter(helper.NewMockPrompt(nil, nil), ">", env, nil, nil, false, false, color.DefaultColor())
+
+	boolField := testentity.NewFld()
+	boolField.FPBtype = "TYPE_BOOL"
+	stringField := testentity.NewFld()
+	stringField.FPBtype = "TYPE_STRING"
+
+	texts := func(s []prompt.Suggestion) []string {
+		var texts []string
+		for _, c := range s {
+			texts = append(texts, c.Text)
+		}
+		return texts
+	}
+
+	cases := map[string]struct {
+		f        entity.Field
+		text     string
+		last     string
+		expected []string
+	}{
+		"bool":                 {f: boolField, expected: []string{"true", "false"}},
+		"bool with prefix":     {f: boolField, text: "f", expected: []string{"false"}},
+		"last input":           {f: boolField, last: "false", expected: []string{"false", "true"}},
+		"string":               {f: stringField},
+		"string with last":     {f: stringField, text: "hi", last: "hi, $last.name", expected: []string{"hi, $last.name"}},
+		"variables":            {f: stringField, text: "$", expected: []string{"$last"}},
+		"references":           {f: stringField, text: "hi, $last.na", expected: []string{"$last.name", "$last.names"}},
+		"not at the beginning": {f: boolField, text: "foo t"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expected, texts(i.suggestions(c.f, c.text, c.last)))
+		})
+	}
+}
+
 func Test_makePrefix(t *testing.T) {
 	var f *testentity.Fld
 	backup := func(tmp testentity.Fld) func() {
@@ -261,14 +650,14 @@ func Test_makePrefix(t *testing.T) {
 	f = testentity.NewFld()
 	t.Run("primitive", func(t *testing.T) {
 		expected := fmt.Sprintf("%s (%s)", f.FieldName(), f.PBType())
-		actual := makePrefix(prefix, f, nil, false)
+		actual := makePrefix(prefix, f, nil, false, "")
 
 		require.Equal(t, expected, actual)
 	})
 
 	t.Run("nested", func(t *testing.T) {
 		expected := fmt.Sprintf("Foo::Bar::%s (%s)", f.FieldName(), f.PBType())
-		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, false)
+		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, false, "")
 		require.Equal(t, expected, actual)
 	})
 
@@ -277,22 +666,36 @@ func Test_makePrefix(t *testing.T) {
 		cleanup := backup(*f)
 		defer cleanup()
 		f.FIsRepeated = true
-		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, false)
+		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, false, "")
 		require.Equal(t, expected, actual, false)
 	})
 
 	t.Run("repeated (ancestor)", func(t *testing.T) {
 		expected := fmt.Sprintf("<repeated> Foo::Bar::%s (%s)", f.FieldName(), f.PBType())
-		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, true)
+		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, true, "")
 		require.Equal(t, expected, actual, false)
 	})
 
+	t.Run("default", func(t *testing.T) {
+		expected := fmt.Sprintf("%s (%s) [rin]", f.FieldName(), f.PBType())
+		actual := makePrefix("{name} ({type}){default}", f, nil, false, "rin")
+		require.Equal(t, expected, actual)
+
+		expected = fmt.Sprintf("%s (%s)[rin] ", f.FieldName(), f.PBType())
+		actual = makePrefix(prefix, f, nil, false, "rin")
+		require.Equal(t, expected, actual)
+
+		expected = fmt.Sprintf("%s (%s)", f.FieldName(), f.PBType())
+		actual = makePrefix("{name} ({type}){default}", f, nil, false, "")
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("repeated (both)", func(t *testing.T) {
 		expected := fmt.Sprintf("<repeated> Foo::Bar::%s (%s)", f.FieldName(), f.PBType())
 		cleanup := backup(*f)
 		defer cleanup()
 		f.FIsRepeated = true
-		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, true)
+		actual := makePrefix(prefix, f, []string{"Foo", "Bar"}, true, "")
 		require.Equal(t, expected, actual, false)
 	})
 }
@@ -0,0 +1,111 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// BytesFormat is the format of bytes fields in JSON formatted responses.
+type BytesFormat string
+
+const (
+	// BytesBase64 formats bytes in standard base64 like the canonical JSON mapping of Protocol Buffers.
+	BytesBase64 BytesFormat = "base64"
+	// BytesHex formats bytes as a hex dump like the output of `hexdump -C`.
+	BytesHex BytesFormat = "hex"
+	// BytesText formats bytes as UTF-8 text if they are printable, otherwise in standard base64.
+	BytesText BytesFormat = "text"
+)
+
+// Validate returns an error if f is unknown.
+func (f BytesFormat) Validate() error {
+	switch f {
+	case BytesBase64, BytesHex, BytesText:
+		return nil
+	default:
+		return errors.Errorf("unknown bytes format '%s'", f)
+	}
+}
+
+// formatBytes converts a base64 encoded JSON string b to the format f.
+// If maxLength is positive, the value is truncated to maxLength bytes and the original length is appended
+// like "AAEC... (1024 bytes)".
+func formatBytes(b json.RawMessage, f BytesFormat, maxLength int) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		// null
+		return b, nil
+	}
+	v, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode bytes")
+	}
+
+	n := len(v)
+	truncated := maxLength > 0 && n > maxLength
+	if truncated {
+		v = v[:maxLength]
+	}
+
+	switch {
+	case f == BytesHex:
+		s = strings.TrimSuffix(hex.Dump(v), "\n")
+	case f == BytesText && isPrintable(v, truncated):
+		if truncated {
+			v = trimIncompleteRune(v)
+		}
+		s = string(v)
+	default:
+		s = base64.StdEncoding.EncodeToString(v)
+	}
+	if truncated {
+		s = fmt.Sprintf("%s... (%d bytes)", s, n)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// isPrintable returns whether b is a printable UTF-8 text. Newlines and tabs are regarded as printable.
+// If truncated is true, an incomplete rune at the end of b is allowed.
+func isPrintable(b []byte, truncated bool) bool {
+	if truncated {
+		b = trimIncompleteRune(b)
+	}
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// trimIncompleteRune removes a rune at the end of b which is cut in the middle.
+func trimIncompleteRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if !utf8.FullRune(b[i:]) {
+			return b[:i]
+		}
+		break
+	}
+	return b
+}
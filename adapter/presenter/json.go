@@ -2,13 +2,22 @@ package presenter
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // JSONPresenter provides JSON formatted output.
@@ -23,6 +32,94 @@ import (
 // Also indenting is supported. (see NewJSONWithIndent)
 type JSONPresenter struct {
 	indent string
+
+	// showMetadata enables to output the header and trailer metadata.
+	showMetadata bool
+
+	// showElapsedTime enables to output the elapsed time of each RPC call.
+	showElapsedTime bool
+
+	// anyResolver is used to resolve google.protobuf.Any in responses and error details.
+	anyResolver jsonpb.AnyResolver
+
+	// origName uses the original field names in proto files instead of lowerCamelCase names.
+	origName bool
+	// enumsAsInts formats enum values as numbers instead of their names.
+	enumsAsInts bool
+	// omitDefaults omits fields which have the default value.
+	omitDefaults bool
+	// int64AsString formats 64-bit integers as strings.
+	int64AsString bool
+	// bytesFormat is the format of bytes fields. The default is BytesBase64.
+	bytesFormat BytesFormat
+	// bytesMaxLength truncates bytes fields which are longer than it if it is positive.
+	bytesMaxLength int
+}
+
+// JSONOption configures JSONPresenter.
+type JSONOption func(*JSONPresenter)
+
+// WithMetadata enables to output the header and trailer metadata of each RPC.
+// They are formatted as a JSON object which has "header" or "trailer" key.
+func WithMetadata() JSONOption {
+	return func(p *JSONPresenter) {
+		p.showMetadata = true
+	}
+}
+
+// WithElapsedTime enables to output the elapsed time of each RPC call.
+// It is formatted as a JSON object which has "elapsed" key.
+func WithElapsedTime() JSONOption {
+	return func(p *JSONPresenter) {
+		p.showElapsedTime = true
+	}
+}
+
+// WithAnyResolver specifies the resolver which is used to decode google.protobuf.Any.
+// If it is not specified, only registered types are decoded.
+func WithAnyResolver(r jsonpb.AnyResolver) JSONOption {
+	return func(p *JSONPresenter) {
+		p.anyResolver = r
+	}
+}
+
+// WithOrigName uses the original field names in proto files instead of lowerCamelCase names.
+func WithOrigName() JSONOption {
+	return func(p *JSONPresenter) {
+		p.origName = true
+	}
+}
+
+// WithEnumsAsInts formats enum values as numbers instead of their names.
+func WithEnumsAsInts() JSONOption {
+	return func(p *JSONPresenter) {
+		p.enumsAsInts = true
+	}
+}
+
+// WithOmitDefaults omits fields which have the default value like jsonpb.
+// By default, all fields are formatted.
+func WithOmitDefaults() JSONOption {
+	return func(p *JSONPresenter) {
+		p.omitDefaults = true
+	}
+}
+
+// WithInt64AsString formats 64-bit integer fields as strings like the canonical JSON mapping of Protocol Buffers.
+// By default, they are formatted as numbers.
+func WithInt64AsString() JSONOption {
+	return func(p *JSONPresenter) {
+		p.int64AsString = true
+	}
+}
+
+// WithBytes formats bytes fields in f. By default, they are formatted in base64.
+// If maxLength is positive, bytes longer than maxLength are truncated and their length is appended.
+func WithBytes(f BytesFormat, maxLength int) JSONOption {
+	return func(p *JSONPresenter) {
+		p.bytesFormat = f
+		p.bytesMaxLength = maxLength
+	}
 }
 
 var emptyResult = strings.NewReader("")
@@ -47,9 +144,13 @@ func (p *JSONPresenter) Header() (io.Reader, error) {
 	return emptyResult, nil
 }
 
+func (p *JSONPresenter) Save() (io.Reader, error) {
+	return emptyResult, nil
+}
+
 func (p *JSONPresenter) Call(res proto.Message) (io.Reader, error) {
 	buf := new(bytes.Buffer)
-	if err := marshalIndent(buf, res, p.indent); err != nil {
+	if err := p.marshalMessage(buf, res, p.indent); err != nil {
 		return nil, err
 	}
 	if _, err := buf.WriteRune('\n'); err != nil {
@@ -58,8 +159,252 @@ func (p *JSONPresenter) Call(res proto.Message) (io.Reader, error) {
 	return buf, nil
 }
 
-func NewJSON() *JSONPresenter {
-	return &JSONPresenter{}
+func (p *JSONPresenter) CallFiltered(v interface{}) (io.Reader, error) {
+	return p.marshalJSON(v)
+}
+
+func (p *JSONPresenter) CallHeader(header metadata.MD) (io.Reader, error) {
+	return p.callMetadata("header", header)
+}
+
+func (p *JSONPresenter) CallTrailer(trailer metadata.MD) (io.Reader, error) {
+	return p.callMetadata("trailer", trailer)
+}
+
+// callMetadata formats md as a JSON object which has the key.
+// The value of binary headers (the key has "-bin" suffix) is encoded by base64.
+func (p *JSONPresenter) callMetadata(key string, md metadata.MD) (io.Reader, error) {
+	if !p.showMetadata {
+		return emptyResult, nil
+	}
+	return p.marshalJSON(map[string]map[string][]string{key: encodeMetadata(md)})
+}
+
+// encodeMetadata encodes the value of binary headers (the key has "-bin" suffix) by base64.
+func encodeMetadata(md metadata.MD) map[string][]string {
+	out := make(map[string][]string, len(md))
+	for k, v := range md {
+		if !strings.HasSuffix(k, "-bin") {
+			out[k] = v
+			continue
+		}
+		encoded := make([]string, 0, len(v))
+		for _, s := range v {
+			encoded = append(encoded, base64.StdEncoding.EncodeToString([]byte(s)))
+		}
+		out[k] = encoded
+	}
+	return out
+}
+
+func (p *JSONPresenter) CallElapsed(elapsed time.Duration) (io.Reader, error) {
+	if !p.showElapsedTime {
+		return emptyResult, nil
+	}
+	return p.marshalJSON(map[string]string{"elapsed": elapsed.String()})
+}
+
+// CallError formats st as a JSON object which has the code, the message and the details.
+// Each detail is decoded from google.protobuf.Any (e.g. google.rpc.BadRequest).
+// If the type of a detail cannot be resolved, the detail is formatted with
+// the type URL and the base64 encoded value.
+func (p *JSONPresenter) CallError(st *status.Status) (io.Reader, error) {
+	v := struct {
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Details []json.RawMessage `json:"details,omitempty"`
+	}{
+		Code:    st.Code().String(),
+		Message: st.Message(),
+	}
+	for _, d := range st.Proto().GetDetails() {
+		v.Details = append(v.Details, p.marshalAny(d))
+	}
+	return p.marshalJSON(v)
+}
+
+// CallReceived formats the sequence number and the receive time of a streamed message
+// as a JSON object like {"seq":1,"receivedAt":"2019-01-02T15:04:05.123Z"}.
+func (p *JSONPresenter) CallReceived(seq int, receivedAt time.Time) (io.Reader, error) {
+	return p.marshalJSON(struct {
+		Seq        int    `json:"seq"`
+		ReceivedAt string `json:"receivedAt"`
+	}{
+		Seq:        seq,
+		ReceivedAt: receivedAt.Format(time.RFC3339Nano),
+	})
+}
+
+// CallStreamSummary formats summary as a JSON object which has "summary" key.
+// If the stream was stopped by the client, "stopped" is true and the status is omitted.
+func (p *JSONPresenter) CallStreamSummary(summary *port.StreamSummary) (io.Reader, error) {
+	type stream struct {
+		Count   int                 `json:"count"`
+		Bytes   int                 `json:"bytes"`
+		Elapsed string              `json:"elapsed"`
+		Rate    float64             `json:"rate"`
+		Stopped bool                `json:"stopped,omitempty"`
+		Code    string              `json:"code,omitempty"`
+		Message string              `json:"message,omitempty"`
+		Trailer map[string][]string `json:"trailer,omitempty"`
+	}
+	v := stream{
+		Count:   summary.Count,
+		Bytes:   summary.Bytes,
+		Elapsed: summary.Elapsed.String(),
+		Rate:    summary.Rate,
+		Stopped: summary.Status == nil,
+	}
+	if summary.Status != nil {
+		v.Code, v.Message = summary.Status.Code().String(), summary.Status.Message()
+	}
+	if len(summary.Trailer) != 0 {
+		v.Trailer = encodeMetadata(summary.Trailer)
+	}
+	return p.marshalJSON(map[string]stream{"summary": v})
+}
+
+// Bench formats report as a JSON object. Durations are formatted like "1.5s".
+func (p *JSONPresenter) Bench(report *port.BenchReport) (io.Reader, error) {
+	type latency struct {
+		Percentile int    `json:"percentile"`
+		Latency    string `json:"latency"`
+	}
+	type bucket struct {
+		Mark      string  `json:"mark"`
+		Count     int     `json:"count"`
+		Frequency float64 `json:"frequency"`
+	}
+	v := struct {
+		Count       int            `json:"count"`
+		Total       string         `json:"total"`
+		RPS         float64        `json:"rps"`
+		Fastest     string         `json:"fastest"`
+		Slowest     string         `json:"slowest"`
+		Average     string         `json:"average"`
+		Latencies   []latency      `json:"latencies"`
+		Histogram   []bucket       `json:"histogram"`
+		StatusCodes map[string]int `json:"statusCodes"`
+	}{
+		Count:       report.Count,
+		Total:       report.Total.String(),
+		RPS:         report.RPS,
+		Fastest:     report.Fastest.String(),
+		Slowest:     report.Slowest.String(),
+		Average:     report.Average.String(),
+		Latencies:   []latency{},
+		Histogram:   []bucket{},
+		StatusCodes: map[string]int{},
+	}
+	for _, l := range report.Latencies {
+		v.Latencies = append(v.Latencies, latency{Percentile: l.Percentile, Latency: l.Latency.String()})
+	}
+	for _, b := range report.Histogram {
+		v.Histogram = append(v.Histogram, bucket{Mark: b.Mark.String(), Count: b.Count, Frequency: b.Frequency})
+	}
+	for code, n := range report.StatusCodes {
+		v.StatusCodes[code.String()] = n
+	}
+	return p.marshalJSON(v)
+}
+
+// Replay formats report as a JSON object which has the number of passed and failed calls,
+// and the result of each call.
+func (p *JSONPresenter) Replay(report *port.ReplayReport) (io.Reader, error) {
+	type result struct {
+		Package string   `json:"package"`
+		Service string   `json:"service"`
+		RPC     string   `json:"rpc"`
+		Passed  bool     `json:"passed"`
+		Diffs   []string `json:"diffs,omitempty"`
+	}
+	v := struct {
+		Passed  int      `json:"passed"`
+		Failed  int      `json:"failed"`
+		Results []result `json:"results"`
+	}{
+		Passed:  report.Passed,
+		Failed:  report.Failed,
+		Results: []result{},
+	}
+	for _, r := range report.Results {
+		v.Results = append(v.Results, result{
+			Package: r.Call.Package,
+			Service: r.Call.Service,
+			RPC:     r.Call.RPC,
+			Passed:  r.Passed(),
+			Diffs:   r.Diffs,
+		})
+	}
+	return p.marshalJSON(v)
+}
+
+// Test formats report as a JSON object which has the summary of the test suite,
+// and the result of each test case.
+func (p *JSONPresenter) Test(report *port.TestReport) (io.Reader, error) {
+	type result struct {
+		Name     string   `json:"name"`
+		RPC      string   `json:"rpc"`
+		Passed   bool     `json:"passed"`
+		Elapsed  string   `json:"elapsed"`
+		Failures []string `json:"failures,omitempty"`
+	}
+	v := struct {
+		Name    string   `json:"name,omitempty"`
+		Passed  int      `json:"passed"`
+		Failed  int      `json:"failed"`
+		Elapsed string   `json:"elapsed"`
+		Results []result `json:"results"`
+	}{
+		Name:    report.Name,
+		Passed:  report.Passed,
+		Failed:  report.Failed,
+		Elapsed: report.Elapsed.String(),
+		Results: []result{},
+	}
+	for _, r := range report.Results {
+		v.Results = append(v.Results, result{
+			Name:     r.Case.Name,
+			RPC:      fmt.Sprintf("%s.%s.%s", r.Case.Package, r.Case.Service, r.Case.RPC),
+			Passed:   r.Passed(),
+			Elapsed:  r.Elapsed.String(),
+			Failures: r.Failures,
+		})
+	}
+	return p.marshalJSON(v)
+}
+
+func (p *JSONPresenter) marshalAny(a *any.Any) json.RawMessage {
+	m := &jsonpb.Marshaler{AnyResolver: p.anyResolver}
+	s, err := m.MarshalToString(a)
+	if err == nil {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(map[string]string{
+		"@type": a.GetTypeUrl(),
+		"value": base64.StdEncoding.EncodeToString(a.GetValue()),
+	})
+	return b
+}
+
+func (p *JSONPresenter) marshalJSON(v interface{}) (io.Reader, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if p.indent == "" {
+		b, err = json.Marshal(v)
+	} else {
+		b, err = json.MarshalIndent(v, "", p.indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(append(b, '\n')), nil
+}
+
+func NewJSON(opts ...JSONOption) *JSONPresenter {
+	return newJSON("", opts)
 }
 
 // NewJSONWithIndent provides indented output.
@@ -68,23 +413,72 @@ func NewJSON() *JSONPresenter {
 //   {
 //     "foo": "some_string"
 //   }
-func NewJSONWithIndent() *JSONPresenter {
-	return &JSONPresenter{indent: "  "}
+func NewJSONWithIndent(opts ...JSONOption) *JSONPresenter {
+	return newJSON("  ", opts)
 }
 
-func marshalIndent(out io.Writer, pb proto.Message, indent string) error {
-	if dmsg, ok := pb.(*dynamic.Message); ok {
-		b, err := dmsg.MarshalJSONPB(&jsonpb.Marshaler{
-			Indent:       indent,
-			EmitDefaults: true,
-		})
-		if err != nil {
-			return err
-		}
+func newJSON(indent string, opts []JSONOption) *JSONPresenter {
+	p := &JSONPresenter{indent: indent}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// marshalMessage formats pb in JSON according to the options of p.
+func (p *JSONPresenter) marshalMessage(out io.Writer, pb proto.Message, indent string) error {
+	m := &jsonpb.Marshaler{
+		Indent:       indent,
+		OrigName:     p.origName,
+		EnumsAsInts:  p.enumsAsInts,
+		EmitDefaults: !p.omitDefaults,
+		AnyResolver:  p.anyResolver,
+	}
+	dmsg, ok := pb.(*dynamic.Message)
+	if !ok {
+		return m.Marshal(out, pb)
+	}
+
+	m.Indent = ""
+	b, err := dmsg.MarshalJSONPB(m)
+	if err != nil {
+		return err
+	}
+	b, err = formatScalars(b, dmsg.GetMessageDescriptor(), p.formatScalar)
+	if err != nil {
+		return errors.Wrap(err, "failed to format the response")
+	}
+	if indent == "" {
 		_, err = out.Write(b)
 		return err
 	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", indent); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(out)
+	return err
+}
 
-	m := &jsonpb.Marshaler{}
-	return m.Marshal(out, pb)
+// formatScalar formats 64-bit integers and bytes according to the options of p.
+func (p *JSONPresenter) formatScalar(b json.RawMessage, t descriptor.FieldDescriptorProto_Type) ([]byte, error) {
+	switch t {
+	case descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_SINT64,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return formatInt64(b, p.int64AsString)
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		f := p.bytesFormat
+		if f == "" {
+			f = BytesBase64
+		}
+		if f == BytesBase64 && p.bytesMaxLength <= 0 {
+			return b, nil
+		}
+		return formatBytes(b, f, p.bytesMaxLength)
+	default:
+		return b, nil
+	}
 }
@@ -1,16 +1,25 @@
 package presenter
 
 import (
+	"encoding/json"
+	"io"
 	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/ktr0731/evans/adapter/internal/testhelper"
+	"github.com/ktr0731/evans/usecase/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-func doCall(t *testing.T, presenter *JSONPresenter) (res string) {
+func doCall(t *testing.T, presenter port.OutputPort) (res string) {
 	descs := testhelper.ReadProtoAsFileDescriptors(t, "helloworld.proto")
 	msg := testhelper.FindMessage(t, "HelloRequest", descs)
 
@@ -43,3 +52,208 @@ func TestJSONPresenterWithIndent(t *testing.T) {
   "message": "kurisu"
 }`+"\n", res)
 }
+
+func TestJSONPresenter_CallMetadata(t *testing.T) {
+	read := func(t *testing.T, f func(metadata.MD) (io.Reader, error), md metadata.MD) string {
+		out, err := f(md)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(out)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		presenter := NewJSON()
+		assert.Empty(t, read(t, presenter.CallHeader, metadata.Pairs("foo", "bar")))
+		assert.Empty(t, read(t, presenter.CallTrailer, metadata.Pairs("foo", "bar")))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		presenter := NewJSON(WithMetadata())
+		assert.Equal(t, `{"header":{"foo":["bar"]}}`+"\n", read(t, presenter.CallHeader, metadata.Pairs("foo", "bar")))
+		assert.Equal(t, `{"trailer":{"hoge-bin":["ZnVnYQ=="]}}`+"\n", read(t, presenter.CallTrailer, metadata.Pairs("hoge-bin", "fuga")))
+	})
+}
+
+func TestJSONPresenter_CallError(t *testing.T) {
+	read := func(t *testing.T, presenter *JSONPresenter, st *status.Status) string {
+		out, err := presenter.CallError(st)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(out)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("without details", func(t *testing.T) {
+		st := status.New(codes.NotFound, "not found")
+		assert.Equal(t, `{"code":"NotFound","message":"not found"}`+"\n", read(t, NewJSON(), st))
+	})
+
+	t.Run("with details", func(t *testing.T) {
+		st, err := status.New(codes.InvalidArgument, "invalid name").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "name", Description: "name is required"},
+			},
+		})
+		require.NoError(t, err)
+		// Status.Proto returns a copy, so re-construct the status.
+		p := st.Proto()
+		p.Details = append(p.Details, &any.Any{TypeUrl: "type.googleapis.com/foo.Bar", Value: []byte("baz")})
+		st = status.FromProto(p)
+
+		assert.Equal(t, `{
+  "code": "InvalidArgument",
+  "message": "invalid name",
+  "details": [
+    {
+      "@type": "type.googleapis.com/google.rpc.BadRequest",
+      "fieldViolations": [
+        {
+          "field": "name",
+          "description": "name is required"
+        }
+      ]
+    },
+    {
+      "@type": "type.googleapis.com/foo.Bar",
+      "value": "YmF6"
+    }
+  ]
+}`+"\n", read(t, NewJSONWithIndent(), st))
+	})
+}
+
+func TestJSONPresenter_CallElapsed(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		out, err := NewJSON().CallElapsed(1500 * time.Millisecond)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(out)
+		require.NoError(t, err)
+		assert.Empty(t, string(b))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		out, err := NewJSON(WithElapsedTime()).CallElapsed(1500 * time.Millisecond)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(out)
+		require.NoError(t, err)
+		assert.Equal(t, `{"elapsed":"1.5s"}`+"\n", string(b))
+	})
+}
+
+func TestJSONPresenter_CallReceived(t *testing.T) {
+	at := time.Date(2019, 1, 2, 15, 4, 5, 123000000, time.UTC)
+	out, err := NewJSON().CallReceived(3, at)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"seq":3,"receivedAt":"2019-01-02T15:04:05.123Z"}`+"\n", string(b))
+}
+
+func TestJSONPresenter_CallStreamSummary(t *testing.T) {
+	cases := map[string]struct {
+		summary  *port.StreamSummary
+		expected string
+	}{
+		"finished": {
+			summary: &port.StreamSummary{
+				Count:   4,
+				Bytes:   20,
+				Elapsed: 2 * time.Second,
+				Rate:    2,
+				Status:  status.New(codes.OK, ""),
+				Trailer: metadata.Pairs("foo", "bar"),
+			},
+			expected: `{"summary":{"count":4,"bytes":20,"elapsed":"2s","rate":2,"code":"OK","trailer":{"foo":["bar"]}}}`,
+		},
+		"stopped": {
+			summary:  &port.StreamSummary{Count: 1, Bytes: 5, Elapsed: time.Second, Rate: 1},
+			expected: `{"summary":{"count":1,"bytes":5,"elapsed":"1s","rate":1,"stopped":true}}`,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := NewJSON().CallStreamSummary(c.summary)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(out)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected+"\n", string(b))
+		})
+	}
+}
+
+func TestJSONPresenter_Bench(t *testing.T) {
+	report := &port.BenchReport{
+		Count:   2,
+		Total:   time.Second,
+		RPS:     2,
+		Fastest: 100 * time.Millisecond,
+		Slowest: 200 * time.Millisecond,
+		Average: 150 * time.Millisecond,
+		Latencies: []*port.BenchLatency{
+			{Percentile: 50, Latency: 100 * time.Millisecond},
+		},
+		Histogram: []*port.BenchBucket{
+			{Mark: 200 * time.Millisecond, Count: 2, Frequency: 1},
+		},
+		StatusCodes: map[codes.Code]int{codes.OK: 1, codes.Unavailable: 1},
+	}
+	out, err := NewJSON().Bench(report)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	expected := `{"count":2,"total":"1s","rps":2,"fastest":"100ms","slowest":"200ms","average":"150ms",` +
+		`"latencies":[{"percentile":50,"latency":"100ms"}],` +
+		`"histogram":[{"mark":"200ms","count":2,"frequency":1}],` +
+		`"statusCodes":{"OK":1,"Unavailable":1}}` + "\n"
+	assert.Equal(t, expected, string(b))
+}
+
+func TestJSONPresenter_Replay(t *testing.T) {
+	report := &port.ReplayReport{
+		Passed: 1,
+		Failed: 1,
+		Results: []*port.ReplayResult{
+			{Call: &port.RecordedCall{Package: "api", Service: "Example", RPC: "Unary"}},
+			{Call: &port.RecordedCall{Package: "api", Service: "Example", RPC: "Unary"}, Diffs: []string{"foo"}},
+		},
+	}
+	out, err := NewJSON().Replay(report)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	expected := `{"passed":1,"failed":1,"results":[` +
+		`{"package":"api","service":"Example","rpc":"Unary","passed":true},` +
+		`{"package":"api","service":"Example","rpc":"Unary","passed":false,"diffs":["foo"]}]}` + "\n"
+	assert.Equal(t, expected, string(b))
+}
+
+func TestJSONPresenter_Test(t *testing.T) {
+	tc := &port.TestCase{Name: "say hello", Package: "api", Service: "Example", RPC: "Unary"}
+	report := &port.TestReport{
+		Name:    "contract",
+		Passed:  1,
+		Failed:  1,
+		Elapsed: time.Second,
+		Results: []*port.TestResult{
+			{Case: tc, Elapsed: 500 * time.Millisecond},
+			{Case: tc, Elapsed: 500 * time.Millisecond, Failures: []string{"foo"}},
+		},
+	}
+	out, err := NewJSON().Test(report)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	expected := `{"name":"contract","passed":1,"failed":1,"elapsed":"1s","results":[` +
+		`{"name":"say hello","rpc":"api.Example.Unary","passed":true,"elapsed":"500ms"},` +
+		`{"name":"say hello","rpc":"api.Example.Unary","passed":false,"elapsed":"500ms","failures":["foo"]}]}` + "\n"
+	assert.Equal(t, expected, string(b))
+}
+
+func TestJSONPresenter_CallFiltered(t *testing.T) {
+	out, err := NewJSON().CallFiltered(map[string]interface{}{"id": json.Number("10000000000000000001"), "name": "makise"})
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":10000000000000000001,"name":"makise"}`+"\n", string(b))
+}
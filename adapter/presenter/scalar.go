@@ -0,0 +1,153 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/jhump/protoreflect/desc"
+)
+
+// wrapperTypes are well-known wrapper types which are formatted as a scalar of the wrapped type.
+var wrapperTypes = map[string]descriptor.FieldDescriptorProto_Type{
+	"google.protobuf.Int64Value":  descriptor.FieldDescriptorProto_TYPE_INT64,
+	"google.protobuf.UInt64Value": descriptor.FieldDescriptorProto_TYPE_UINT64,
+	"google.protobuf.BytesValue":  descriptor.FieldDescriptorProto_TYPE_BYTES,
+}
+
+// scalarConverter converts a JSON formatted scalar value of the type t.
+type scalarConverter func(b json.RawMessage, t descriptor.FieldDescriptorProto_Type) ([]byte, error)
+
+// formatScalars converts each scalar value of a JSON formatted message by conv.
+// The order of fields is kept. b must not be indented.
+//
+// It is used to format values which jsonpb doesn't support. For example, dynamic.Message formats
+// 64-bit integers as numbers, but well-known wrapper types like google.protobuf.Int64Value format them as strings.
+func formatScalars(b []byte, md *desc.MessageDescriptor, conv scalarConverter) ([]byte, error) {
+	f := &scalarFormatter{buf: new(bytes.Buffer), conv: conv}
+	if err := f.message(b, md); err != nil {
+		return nil, err
+	}
+	return f.buf.Bytes(), nil
+}
+
+type scalarFormatter struct {
+	buf  *bytes.Buffer
+	conv scalarConverter
+}
+
+func (f *scalarFormatter) message(b json.RawMessage, md *desc.MessageDescriptor) error {
+	if t, ok := wrapperTypes[md.GetFullyQualifiedName()]; ok {
+		return f.scalar(b, t)
+	}
+	return f.object(b, func(name string) *desc.FieldDescriptor {
+		for _, fd := range md.GetFields() {
+			if fd.GetJSONName() == name || fd.GetName() == name {
+				return fd
+			}
+		}
+		return nil
+	}, f.field)
+}
+
+// object converts each value of a JSON object by conv. lookup returns the field descriptor of a key.
+// Values which don't have field descriptors (e.g. "@type" of google.protobuf.Any) are written as it is.
+// If b is not an object (e.g. google.protobuf.Timestamp), it is written as it is.
+func (f *scalarFormatter) object(
+	b json.RawMessage,
+	lookup func(name string) *desc.FieldDescriptor,
+	conv func(json.RawMessage, *desc.FieldDescriptor) error,
+) error {
+	fields, err := decodeObject(b)
+	if err != nil {
+		f.buf.Write(b)
+		return nil
+	}
+
+	f.buf.WriteRune('{')
+	for i, field := range fields {
+		if i != 0 {
+			f.buf.WriteRune(',')
+		}
+		k, err := json.Marshal(field.name)
+		if err != nil {
+			return err
+		}
+		f.buf.Write(k)
+		f.buf.WriteRune(':')
+
+		fd := lookup(field.name)
+		if fd == nil {
+			f.buf.Write(field.value)
+			continue
+		}
+		if err := conv(field.value, fd); err != nil {
+			return err
+		}
+	}
+	f.buf.WriteRune('}')
+	return nil
+}
+
+func (f *scalarFormatter) field(b json.RawMessage, fd *desc.FieldDescriptor) error {
+	switch {
+	case fd.IsMap():
+		valueField := fd.GetMapValueType()
+		return f.object(b, func(string) *desc.FieldDescriptor { return valueField }, f.value)
+	case fd.IsRepeated():
+		var elems []json.RawMessage
+		if err := json.Unmarshal(b, &elems); err != nil {
+			f.buf.Write(b)
+			return nil
+		}
+		f.buf.WriteRune('[')
+		for i, e := range elems {
+			if i != 0 {
+				f.buf.WriteRune(',')
+			}
+			if err := f.value(e, fd); err != nil {
+				return err
+			}
+		}
+		f.buf.WriteRune(']')
+		return nil
+	default:
+		return f.value(b, fd)
+	}
+}
+
+// value converts a singular value of fd.
+func (f *scalarFormatter) value(b json.RawMessage, fd *desc.FieldDescriptor) error {
+	if md := fd.GetMessageType(); md != nil {
+		return f.message(b, md)
+	}
+	return f.scalar(b, fd.GetType())
+}
+
+func (f *scalarFormatter) scalar(b json.RawMessage, t descriptor.FieldDescriptorProto_Type) error {
+	b, err := f.conv(b, t)
+	if err != nil {
+		return err
+	}
+	f.buf.Write(b)
+	return nil
+}
+
+// formatInt64 formats a 64-bit integer as a string if asString is true, otherwise as a number.
+func formatInt64(b json.RawMessage, asString bool) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	quoted := len(b) != 0 && b[0] == '"'
+	switch {
+	case asString && !quoted && string(b) != "null":
+		return []byte(strconv.Quote(string(b))), nil
+	case !asString && quoted:
+		s, err := strconv.Unquote(string(b))
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	default:
+		return b, nil
+	}
+}
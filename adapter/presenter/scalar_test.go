@@ -0,0 +1,171 @@
+package presenter
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/ktr0731/evans/adapter/internal/testhelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOptionsMessage(t *testing.T) proto.Message {
+	descs := testhelper.ReadProtoAsFileDescriptors(t, "options.proto")
+	msg := dynamic.NewMessage(testhelper.FindMessage(t, "Options", descs))
+	msg.SetFieldByName("user_id", int64(10))
+	msg.SetFieldByName("status", int32(1))
+	msg.AddRepeatedFieldByName("ids", uint64(1))
+	msg.AddRepeatedFieldByName("ids", uint64(2))
+	msg.PutMapFieldByName("counts", "a", int64(3))
+	msg.SetFieldByName("wrapped", &wrappers.Int64Value{Value: 4})
+	return msg
+}
+
+func TestJSONPresenter_marshalMessage(t *testing.T) {
+	cases := map[string]struct {
+		opts     []JSONOption
+		expected string
+	}{
+		"default": {
+			expected: `{"userId":10,"status":"ACTIVE","ids":[1,2],"counts":{"a":3},"wrapped":4,"displayName":"","detail":null}`,
+		},
+		"original names": {
+			opts:     []JSONOption{WithOrigName()},
+			expected: `{"user_id":10,"status":"ACTIVE","ids":[1,2],"counts":{"a":3},"wrapped":4,"display_name":"","detail":null}`,
+		},
+		"enums as ints": {
+			opts:     []JSONOption{WithEnumsAsInts()},
+			expected: `{"userId":10,"status":1,"ids":[1,2],"counts":{"a":3},"wrapped":4,"displayName":"","detail":null}`,
+		},
+		"omit defaults": {
+			opts:     []JSONOption{WithOmitDefaults()},
+			expected: `{"userId":10,"status":"ACTIVE","ids":[1,2],"counts":{"a":3},"wrapped":4}`,
+		},
+		"int64 as string": {
+			opts:     []JSONOption{WithInt64AsString()},
+			expected: `{"userId":"10","status":"ACTIVE","ids":["1","2"],"counts":{"a":"3"},"wrapped":"4","displayName":"","detail":null}`,
+		},
+		"int64 as string with original names": {
+			opts:     []JSONOption{WithInt64AsString(), WithOrigName()},
+			expected: `{"user_id":"10","status":"ACTIVE","ids":["1","2"],"counts":{"a":"3"},"wrapped":"4","display_name":"","detail":null}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := NewJSON(c.opts...).Call(newOptionsMessage(t))
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(out)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected+"\n", string(b))
+		})
+	}
+
+	t.Run("int64 as string with indent", func(t *testing.T) {
+		out, err := NewJSONWithIndent(WithInt64AsString(), WithOmitDefaults()).Call(newOptionsMessage(t))
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(out)
+		require.NoError(t, err)
+		assert.Equal(t, `{
+  "userId": "10",
+  "status": "ACTIVE",
+  "ids": [
+    "1",
+    "2"
+  ],
+  "counts": {
+    "a": "3"
+  },
+  "wrapped": "4"
+}
+`, string(b))
+	})
+}
+
+func TestJSONPresenter_marshalMessage_any(t *testing.T) {
+	descs := testhelper.ReadProtoAsFileDescriptors(t, "options.proto", "users.proto")
+	user := dynamic.NewMessage(testhelper.FindMessage(t, "User", descs))
+	user.SetFieldByName("name", "makise")
+	detail, err := ptypes.MarshalAny(user)
+	require.NoError(t, err)
+	msg := dynamic.NewMessage(testhelper.FindMessage(t, "Options", descs))
+	msg.SetFieldByName("detail", detail)
+
+	_, err = NewJSON(WithOmitDefaults()).Call(msg)
+	require.Error(t, err, "users.User must not be resolved without the resolver")
+
+	out, err := NewJSON(WithOmitDefaults(), WithAnyResolver(dynamic.AnyResolver(nil, descs...))).Call(msg)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"detail":{"@type":"type.googleapis.com/users.User","name":"makise"}}`+"\n", string(b))
+}
+
+func TestJSONPresenter_marshalMessage_bytes(t *testing.T) {
+	descs := testhelper.ReadProtoAsFileDescriptors(t, "bytes.proto")
+	newMessage := func(data []byte) proto.Message {
+		msg := dynamic.NewMessage(testhelper.FindMessage(t, "Bytes", descs))
+		msg.SetFieldByName("data", data)
+		msg.AddRepeatedFieldByName("chunks", []byte{0xff, 0x00})
+		msg.PutMapFieldByName("blobs", "a", []byte("<b>"))
+		msg.SetFieldByName("wrapped", &wrappers.BytesValue{Value: []byte("wrapped")})
+		return msg
+	}
+
+	cases := map[string]struct {
+		opts     []JSONOption
+		data     []byte
+		expected string
+	}{
+		"default": {
+			data:     []byte("hello"),
+			expected: `{"data":"aGVsbG8=","chunks":["/wA="],"blobs":{"a":"PGI+"},"wrapped":"d3JhcHBlZA=="}`,
+		},
+		"hex": {
+			opts: []JSONOption{WithBytes(BytesHex, 0)},
+			data: []byte("hello"),
+			expected: `{"data":"00000000  68 65 6c 6c 6f                                    |hello|",` +
+				`"chunks":["00000000  ff 00                                             |..|"],` +
+				`"blobs":{"a":"00000000  3c 62 3e                                          |<b>|"},` +
+				`"wrapped":"00000000  77 72 61 70 70 65 64                              |wrapped|"}`,
+		},
+		"truncated hex": {
+			opts: []JSONOption{WithBytes(BytesHex, 17)},
+			data: []byte("0123456789abcdefghij"),
+			expected: `{"data":"00000000  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n` +
+				`00000010  67                                                |g|... (20 bytes)",` +
+				`"chunks":["00000000  ff 00                                             |..|"],` +
+				`"blobs":{"a":"00000000  3c 62 3e                                          |<b>|"},` +
+				`"wrapped":"00000000  77 72 61 70 70 65 64                              |wrapped|"}`,
+		},
+		"text": {
+			opts:     []JSONOption{WithBytes(BytesText, 0)},
+			data:     []byte("hello\n"),
+			expected: `{"data":"hello\n","chunks":["/wA="],"blobs":{"a":"<b>"},"wrapped":"wrapped"}`,
+		},
+		"truncated base64": {
+			opts:     []JSONOption{WithBytes(BytesBase64, 3)},
+			data:     []byte("hello"),
+			expected: `{"data":"aGVs... (5 bytes)","chunks":["/wA="],"blobs":{"a":"PGI+"},"wrapped":"d3Jh... (7 bytes)"}`,
+		},
+		"truncated text": {
+			opts:     []JSONOption{WithBytes(BytesText, 4)},
+			data:     []byte("こんにちは"),
+			expected: `{"data":"こ... (15 bytes)","chunks":["/wA="],"blobs":{"a":"<b>"},"wrapped":"wrap... (7 bytes)"}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := NewJSON(c.opts...).Call(newMessage(c.data))
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(out)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected+"\n", string(b))
+		})
+	}
+}
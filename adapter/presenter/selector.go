@@ -0,0 +1,112 @@
+package presenter
+
+import (
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+// Formats is the list of output formats which can be passed to New.
+var Formats = []string{"json", "yaml", "table", "text", string(WireBinary), string(WireHex), string(WireBase64)}
+
+// FormatTemplate is the format which is selected by Selector.SetTemplate.
+// Unlike Formats, it cannot be passed to New because it needs a template.
+const FormatTemplate = "template"
+
+// New returns the presenter which formats response messages in format.
+// format must be one of Formats.
+func New(format string, opts ...JSONOption) (port.OutputPort, error) {
+	switch format {
+	case "json":
+		return NewJSONWithIndent(opts...), nil
+	case "yaml":
+		return NewYAML(opts...), nil
+	case "table":
+		return NewTable(nil, opts...), nil
+	case "text":
+		return NewText(opts...), nil
+	case string(WireBinary), string(WireHex), string(WireBase64):
+		return NewWire(WireEncoding(format), opts...)
+	default:
+		return nil, errors.Errorf("unknown output format '%s'. it must be one of %v", format, Formats)
+	}
+}
+
+// Selector is an OutputPort which can change the output format.
+// SetFormat must not be called while a RPC call is in progress.
+type Selector struct {
+	port.OutputPort
+
+	format   string
+	columns  []string
+	template *TemplatePresenter
+	opts     []JSONOption
+}
+
+// NewSelector returns a Selector which formats outputs in format initially.
+// opts are applied to the presenter of each format.
+func NewSelector(format string, opts ...JSONOption) (*Selector, error) {
+	s := &Selector{opts: opts}
+	if err := s.SetFormat(format); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Format returns the current output format.
+func (s *Selector) Format() string {
+	return s.format
+}
+
+// SetFormat changes the output format. If format is unknown, the format isn't changed.
+func (s *Selector) SetFormat(format string) error {
+	var p port.OutputPort
+	switch format {
+	case "table":
+		p = NewTable(s.columns, s.opts...)
+	case FormatTemplate:
+		if s.template == nil {
+			return errors.New("no output template is specified")
+		}
+		p = s.template
+	default:
+		var err error
+		p, err = New(format, s.opts...)
+		if err != nil {
+			return err
+		}
+	}
+	s.OutputPort, s.format = p, format
+	return nil
+}
+
+// Columns returns the selected columns of the table format.
+func (s *Selector) Columns() []string {
+	return s.columns
+}
+
+// SetColumns selects the columns of the table format. If columns is empty, all columns are shown.
+func (s *Selector) SetColumns(columns []string) {
+	s.columns = columns
+	if s.format == "table" {
+		s.OutputPort = NewTable(columns, s.opts...)
+	}
+}
+
+// Template returns the output template. If it is not specified, Template returns an empty string.
+func (s *Selector) Template() string {
+	if s.template == nil {
+		return ""
+	}
+	return s.template.text
+}
+
+// SetTemplate parses text as the output template, then selects the template format.
+// If text is invalid, neither the template nor the format is changed.
+func (s *Selector) SetTemplate(text string) error {
+	p, err := NewTemplate(text, s.opts...)
+	if err != nil {
+		return err
+	}
+	s.template = p
+	return s.SetFormat(FormatTemplate)
+}
@@ -0,0 +1,39 @@
+package presenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	s, err := NewSelector("json")
+	require.NoError(t, err)
+	assert.Equal(t, "json", s.Format())
+	assert.Equal(t, "{\n  \"name\": \"makise\",\n  \"message\": \"kurisu\"\n}\n", doCall(t, s))
+
+	require.NoError(t, s.SetFormat("hex"))
+	assert.Equal(t, "hex", s.Format())
+	assert.Equal(t, "0a066d616b69736512066b7572697375\n", doCall(t, s))
+
+	s.SetColumns([]string{"name"})
+	require.NoError(t, s.SetFormat("table"))
+	assert.Equal(t, []string{"name"}, s.Columns())
+	assert.IsType(t, &TablePresenter{}, s.OutputPort)
+	assert.Equal(t, []string{"name"}, s.OutputPort.(*TablePresenter).columns)
+
+	assert.Error(t, s.SetFormat("xml"))
+	assert.Equal(t, "table", s.Format(), "the format must not be changed if it is unknown")
+
+	assert.Error(t, s.SetFormat(FormatTemplate), "no template is specified")
+	assert.Error(t, s.SetTemplate("{{.name"))
+	assert.Equal(t, "table", s.Format())
+	require.NoError(t, s.SetTemplate("{{.name}}"))
+	assert.Equal(t, FormatTemplate, s.Format())
+	assert.Equal(t, "{{.name}}", s.Template())
+	assert.Equal(t, "makise\n", doCall(t, s))
+
+	_, err = NewSelector("xml")
+	assert.Error(t, err)
+}
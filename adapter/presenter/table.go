@@ -0,0 +1,188 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+)
+
+// TablePresenter formats response messages as tables.
+// Each repeated message field is rendered as a table which has a column per field of the element message,
+// and a row per element. Other fields are rendered as a table which has "field" and "value" columns.
+// Nested messages in cells are formatted in JSON.
+//
+// Outputs other than response messages (e.g. metadata and errors) are formatted by JSONPresenter.
+type TablePresenter struct {
+	*JSONPresenter
+
+	// columns are the columns of repeated message fields. If it is empty, all fields are shown.
+	columns []string
+}
+
+// NewTable returns a TablePresenter. columns selects the columns of repeated message fields in order.
+// If columns is empty, all fields are shown.
+func NewTable(columns []string, opts ...JSONOption) *TablePresenter {
+	return &TablePresenter{JSONPresenter: NewJSONWithIndent(opts...), columns: columns}
+}
+
+func (p *TablePresenter) Call(res proto.Message) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	if err := p.marshalMessage(buf, res, ""); err != nil {
+		return nil, err
+	}
+	fields, err := decodeObject(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the response")
+	}
+	return p.renderObject(fields), nil
+}
+
+// CallFiltered renders an object like Call, and an array of objects as a table.
+// Other values are formatted like table cells.
+func (p *TablePresenter) CallFiltered(v interface{}) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if fields, err := decodeObject(b); err == nil {
+		return p.renderObject(fields), nil
+	}
+	out := new(bytes.Buffer)
+	if elems, ok := decodeObjectArray(b); ok {
+		p.renderList(out, elems)
+		return out, nil
+	}
+	out.WriteString(formatCell(b))
+	out.WriteRune('\n')
+	return out, nil
+}
+
+func (p *TablePresenter) renderObject(fields []*jsonField) io.Reader {
+	var (
+		scalars [][]string
+		lists   []*jsonField
+		rows    = map[string][][]*jsonField{}
+	)
+	for _, f := range fields {
+		if elems, ok := decodeObjectArray(f.value); ok {
+			lists = append(lists, f)
+			rows[f.name] = elems
+			continue
+		}
+		scalars = append(scalars, []string{f.name, formatCell(f.value)})
+	}
+
+	out := new(bytes.Buffer)
+	if len(scalars) != 0 || len(lists) == 0 {
+		table := tablewriter.NewWriter(out)
+		table.SetHeader([]string{"field", "value"})
+		table.AppendBulk(scalars)
+		table.Render()
+	}
+	for _, l := range lists {
+		if out.Len() != 0 {
+			out.WriteRune('\n')
+		}
+		fmt.Fprintf(out, "%s:\n", l.name)
+		p.renderList(out, rows[l.name])
+	}
+	return out
+}
+
+// renderList renders elements of a repeated message field.
+func (p *TablePresenter) renderList(w io.Writer, elems [][]*jsonField) {
+	columns := p.columns
+	if len(columns) == 0 {
+		seen := map[string]bool{}
+		for _, e := range elems {
+			for _, f := range e {
+				if !seen[f.name] {
+					seen[f.name] = true
+					columns = append(columns, f.name)
+				}
+			}
+		}
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(columns)
+	table.SetAutoFormatHeaders(false)
+	for _, e := range elems {
+		values := make(map[string]string, len(e))
+		for _, f := range e {
+			values[f.name] = formatCell(f.value)
+		}
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, values[c])
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// jsonField is a field of a JSON object.
+type jsonField struct {
+	name  string
+	value json.RawMessage
+}
+
+// decodeObject decodes a JSON object with keeping the order of its fields.
+func decodeObject(b []byte) ([]*jsonField, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, errors.New("the value is not an object")
+	}
+	var fields []*jsonField
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		f := &jsonField{name: t.(string)}
+		if err := dec.Decode(&f.value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// decodeObjectArray decodes v if it is a non-empty array of JSON objects.
+func decodeObjectArray(v json.RawMessage) ([][]*jsonField, bool) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(v, &elems); err != nil || len(elems) == 0 {
+		return nil, false
+	}
+	objs := make([][]*jsonField, 0, len(elems))
+	for _, e := range elems {
+		obj, err := decodeObject(e)
+		if err != nil {
+			return nil, false
+		}
+		objs = append(objs, obj)
+	}
+	return objs, true
+}
+
+// formatCell formats a JSON value as a table cell. Strings are unquoted and nulls are empty.
+func formatCell(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s
+	}
+	if string(v) == "null" {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, v); err != nil {
+		return strings.TrimSpace(string(v))
+	}
+	return buf.String()
+}
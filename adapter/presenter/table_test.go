@@ -0,0 +1,122 @@
+package presenter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/ktr0731/evans/adapter/internal/testhelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newListUsersResponse(t *testing.T) proto.Message {
+	descs := testhelper.ReadProtoAsFileDescriptors(t, "users.proto")
+	res := dynamic.NewMessage(testhelper.FindMessage(t, "ListUsersResponse", descs))
+	user := testhelper.FindMessage(t, "User", descs)
+	profile := testhelper.FindMessage(t, "Profile", descs)
+
+	for i, name := range []string{"okabe", "makise"} {
+		p := dynamic.NewMessage(profile)
+		p.SetFieldByName("email", name+"@example.com")
+		u := dynamic.NewMessage(user)
+		u.SetFieldByName("id", int64(i+1))
+		u.SetFieldByName("name", name)
+		u.SetFieldByName("profile", p)
+		res.AddRepeatedFieldByName("users", u)
+	}
+	res.SetFieldByName("total", int32(2))
+	return res
+}
+
+func TestTablePresenter(t *testing.T) {
+	cases := map[string]struct {
+		columns  []string
+		expected string
+	}{
+		"all columns": {
+			expected: `+-------+-------+
+| FIELD | VALUE |
++-------+-------+
+| total |     2 |
++-------+-------+
+
+users:
++----+--------+--------------------------------+
+| id |  name  |            profile             |
++----+--------+--------------------------------+
+|  1 | okabe  | {"email":"okabe@example.com"}  |
+|  2 | makise | {"email":"makise@example.com"} |
++----+--------+--------------------------------+
+`,
+		},
+		"selected columns": {
+			columns: []string{"name", "id"},
+			expected: `+-------+-------+
+| FIELD | VALUE |
++-------+-------+
+| total |     2 |
++-------+-------+
+
+users:
++--------+----+
+|  name  | id |
++--------+----+
+| okabe  |  1 |
+| makise |  2 |
++--------+----+
+`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := NewTable(c.columns).Call(newListUsersResponse(t))
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(out)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, string(b))
+		})
+	}
+}
+
+func TestTablePresenter_CallFiltered(t *testing.T) {
+	cases := map[string]struct {
+		in       interface{}
+		expected string
+	}{
+		"object": {
+			in: map[string]interface{}{"name": "makise"},
+			expected: `+-------+--------+
+| FIELD | VALUE  |
++-------+--------+
+| name  | makise |
++-------+--------+
+`,
+		},
+		"array of objects": {
+			in: []interface{}{map[string]interface{}{"id": json.Number("1"), "name": "okabe"}},
+			expected: `+----+-------+
+| id | name  |
++----+-------+
+|  1 | okabe |
++----+-------+
+`,
+		},
+		"string": {in: "makise", expected: "makise\n"},
+		"number": {in: json.Number("1"), expected: "1\n"},
+		"array":  {in: []interface{}{"a", "b"}, expected: `["a","b"]` + "\n"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := NewTable(nil).CallFiltered(c.in)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(out)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, string(b))
+		})
+	}
+}
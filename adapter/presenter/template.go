@@ -0,0 +1,179 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// TemplatePresenter formats response messages by a Go template (text/template).
+// The template is executed with the decoded JSON value of each response,
+// so fields are referenced by their JSON names like {{.name}}.
+// Numbers are passed as json.Number to keep 64-bit integers.
+// If the output doesn't end with a newline, a newline is appended.
+//
+// In addition to the predefined functions of text/template, the following functions are available.
+//   json      formats the value in JSON
+//   upper     converts the string to upper case
+//   lower     converts the string to lower case
+//   trim      removes leading and trailing white spaces
+//   replace   replaces all occurrences of old in the string with new (replace <old> <new> <string>)
+//   split     splits the string by the separator (split <separator> <string>)
+//   join      concatenates the elements of the list with the separator (join <separator> <list>)
+//   csv       formats the arguments as a CSV record without the trailing newline
+//   default   returns the default value if the value is empty (default <default> <value>)
+//
+// Outputs other than response messages (e.g. metadata and errors) are formatted by JSONPresenter.
+type TemplatePresenter struct {
+	*JSONPresenter
+
+	text string
+	tmpl *template.Template
+}
+
+// NewTemplate parses text as a Go template, then returns a TemplatePresenter.
+func NewTemplate(text string, opts ...JSONOption) (*TemplatePresenter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the output template")
+	}
+	return &TemplatePresenter{JSONPresenter: NewJSON(opts...), text: text, tmpl: tmpl}, nil
+}
+
+func (p *TemplatePresenter) Call(res proto.Message) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	if err := p.marshalMessage(buf, res, ""); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(buf)
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the response")
+	}
+	return p.execute(v)
+}
+
+// CallFiltered executes the template with v.
+func (p *TemplatePresenter) CallFiltered(v interface{}) (io.Reader, error) {
+	return p.execute(v)
+}
+
+func (p *TemplatePresenter) execute(v interface{}) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	if err := p.tmpl.Execute(buf, v); err != nil {
+		return nil, errors.Wrap(err, "failed to execute the output template")
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteRune('\n')
+	}
+	return buf, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": func(v interface{}) string { return strings.ToUpper(toString(v)) },
+	"lower": func(v interface{}) string { return strings.ToLower(toString(v)) },
+	"trim":  func(v interface{}) string { return strings.TrimSpace(toString(v)) },
+	"replace": func(old, new string, v interface{}) string {
+		return strings.Replace(toString(v), old, new, -1)
+	},
+	"split": func(sep string, v interface{}) []string { return strings.Split(toString(v), sep) },
+	"join": func(sep string, v interface{}) (string, error) {
+		list, err := toList(v)
+		if err != nil {
+			return "", err
+		}
+		ss := make([]string, 0, len(list))
+		for _, e := range list {
+			ss = append(ss, toString(e))
+		}
+		return strings.Join(ss, sep), nil
+	},
+	"csv": func(v ...interface{}) (string, error) {
+		// a list can be passed as the only argument.
+		if len(v) == 1 {
+			if list, err := toList(v[0]); err == nil {
+				v = list
+			}
+		}
+		record := make([]string, 0, len(v))
+		for _, e := range v {
+			record = append(record, toString(e))
+		}
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+	},
+	"default": func(def, v interface{}) interface{} {
+		if isEmpty(v) {
+			return def
+		}
+		return v
+	},
+}
+
+// toString converts v to a string. Objects and arrays are formatted in JSON.
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toList(v interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, errors.Errorf("%v is not a list", v)
+	}
+	list := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		list = append(list, rv.Index(i).Interface())
+	}
+	return list, nil
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return err == nil && f == 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	}
+	return false
+}
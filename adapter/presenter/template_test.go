@@ -0,0 +1,54 @@
+package presenter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatePresenter(t *testing.T) {
+	cases := map[string]struct {
+		text     string
+		expected string
+		hasError bool
+	}{
+		"field":            {text: "{{.name}}", expected: "makise\n"},
+		"trailing newline": {text: "{{.name}}\n", expected: "makise\n"},
+		"json":             {text: "{{json .}}", expected: `{"message":"kurisu","name":"makise"}` + "\n"},
+		"upper":            {text: "{{upper .name}}", expected: "MAKISE\n"},
+		"lower":            {text: `{{lower "MAKISE"}}`, expected: "makise\n"},
+		"trim":             {text: `{{trim "  makise "}}`, expected: "makise\n"},
+		"replace":          {text: `{{replace "k" "K" .name}}`, expected: "maKise\n"},
+		"split and join":   {text: `{{join "-" (split "a" .name)}}`, expected: "m-kise\n"},
+		"csv":              {text: `{{csv .name .message "a,b"}}`, expected: `makise,kurisu,"a,b"` + "\n"},
+		"default":          {text: `{{default "none" .foo}} {{default "none" .name}}`, expected: "none makise\n"},
+		"invalid template": {text: "{{.name", hasError: true},
+		"unknown function": {text: "{{foo .name}}", hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			presenter, err := NewTemplate(c.text)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, doCall(t, presenter))
+		})
+	}
+}
+
+func TestTemplatePresenter_CallFiltered(t *testing.T) {
+	presenter, err := NewTemplate(`{{csv .}} {{default "zero" (index . 2)}}`)
+	require.NoError(t, err)
+
+	out, err := presenter.CallFiltered([]interface{}{"a", json.Number("10000000000000000001"), json.Number("0")})
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, "a,10000000000000000001,0 zero\n", string(b))
+}
@@ -0,0 +1,45 @@
+package presenter
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// TextPresenter formats response messages in the protobuf text format.
+// For example, a message definition is here.
+//   message {
+//     string foo = 1;
+//   }
+//
+// If `foo` is "some_string", the response is
+//   foo: "some_string"
+//
+// Outputs other than response messages (e.g. metadata and errors) are formatted by JSONPresenter.
+type TextPresenter struct {
+	*JSONPresenter
+}
+
+func NewText(opts ...JSONOption) *TextPresenter {
+	return &TextPresenter{JSONPresenter: NewJSONWithIndent(opts...)}
+}
+
+// Call formats res in the text format. The output always ends with a newline like JSONPresenter.
+func (p *TextPresenter) Call(res proto.Message) (io.Reader, error) {
+	var b []byte
+	if dmsg, ok := res.(*dynamic.Message); ok {
+		var err error
+		b, err = dmsg.MarshalTextIndent()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		b = []byte(proto.MarshalTextString(res))
+	}
+	if !bytes.HasSuffix(b, []byte("\n")) {
+		b = append(b, '\n')
+	}
+	return bytes.NewReader(b), nil
+}
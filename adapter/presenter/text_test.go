@@ -0,0 +1,14 @@
+package presenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextPresenter(t *testing.T) {
+	presenter := NewText()
+
+	res := doCall(t, presenter)
+	assert.Equal(t, `name: "makise"`+"\n"+`message: "kurisu"`+"\n", res)
+}
@@ -0,0 +1,58 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// WireEncoding is the encoding of the binary wire format.
+type WireEncoding string
+
+const (
+	// WireBinary outputs the wire format as it is.
+	WireBinary WireEncoding = "binary"
+	// WireHex outputs the wire format encoded in hex.
+	WireHex WireEncoding = "hex"
+	// WireBase64 outputs the wire format encoded in standard base64.
+	WireBase64 WireEncoding = "base64"
+)
+
+// WirePresenter formats response messages in the protobuf binary wire format.
+// It is useful to inspect exact encodings.
+// Outputs other than response messages (e.g. metadata and errors) are formatted by JSONPresenter.
+type WirePresenter struct {
+	*JSONPresenter
+
+	encoding WireEncoding
+}
+
+// NewWire returns a WirePresenter which outputs the wire format encoded by enc.
+func NewWire(enc WireEncoding, opts ...JSONOption) (*WirePresenter, error) {
+	switch enc {
+	case WireBinary, WireHex, WireBase64:
+	default:
+		return nil, errors.Errorf("unknown wire encoding '%s'", enc)
+	}
+	return &WirePresenter{JSONPresenter: NewJSONWithIndent(opts...), encoding: enc}, nil
+}
+
+// Call formats res in the wire format. Except for WireBinary, the output ends with a newline.
+func (p *WirePresenter) Call(res proto.Message) (io.Reader, error) {
+	b, err := proto.Marshal(res)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the response to the wire format")
+	}
+	switch p.encoding {
+	case WireHex:
+		return bytes.NewReader([]byte(hex.EncodeToString(b) + "\n")), nil
+	case WireBase64:
+		return bytes.NewReader([]byte(base64.StdEncoding.EncodeToString(b) + "\n")), nil
+	default:
+		return bytes.NewReader(b), nil
+	}
+}
@@ -0,0 +1,37 @@
+package presenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWirePresenter(t *testing.T) {
+	// field 1 (name): "makise", field 2 (message): "kurisu"
+	wire := "\x0a\x06makise\x12\x06kurisu"
+
+	cases := map[string]struct {
+		encoding WireEncoding
+		expected string
+		hasError bool
+	}{
+		"binary":  {encoding: WireBinary, expected: wire},
+		"hex":     {encoding: WireHex, expected: "0a066d616b69736512066b7572697375\n"},
+		"base64":  {encoding: WireBase64, expected: "CgZtYWtpc2USBmt1cmlzdQ==\n"},
+		"unknown": {encoding: "base32", hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			presenter, err := NewWire(c.encoding)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, doCall(t, presenter))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package presenter
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// YAMLPresenter formats response messages in YAML.
+// For example, a message definition is here.
+//   message {
+//     string foo = 1;
+//   }
+//
+// If `foo` is "some_string", the response is
+//   foo: some_string
+//
+// Field names and values are the same as the JSON mapping of Protocol Buffers.
+// Outputs other than response messages (e.g. metadata and errors) are formatted by JSONPresenter.
+type YAMLPresenter struct {
+	*JSONPresenter
+}
+
+func NewYAML(opts ...JSONOption) *YAMLPresenter {
+	return &YAMLPresenter{JSONPresenter: NewJSONWithIndent(opts...)}
+}
+
+func (p *YAMLPresenter) Call(res proto.Message) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	if err := p.marshalMessage(buf, res, ""); err != nil {
+		return nil, err
+	}
+
+	// JSON is a subset of YAML. MapSlice keeps the order of fields.
+	var v yaml.MapSlice
+	if err := yaml.Unmarshal(buf.Bytes(), &v); err != nil {
+		return nil, errors.Wrap(err, "failed to convert the response to YAML")
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the response to YAML")
+	}
+	return bytes.NewReader(b), nil
+}
+
+func (p *YAMLPresenter) CallFiltered(v interface{}) (io.Reader, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the value to YAML")
+	}
+	return bytes.NewReader(b), nil
+}
@@ -0,0 +1,41 @@
+package presenter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLPresenter(t *testing.T) {
+	presenter := NewYAML()
+
+	res := doCall(t, presenter)
+	assert.Equal(t, "name: makise\nmessage: kurisu\n", res)
+
+	out, err := presenter.Call(newListUsersResponse(t))
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, `users:
+- id: 1
+  name: okabe
+  profile:
+    email: okabe@example.com
+- id: 2
+  name: makise
+  profile:
+    email: makise@example.com
+total: 2
+`, string(b))
+}
+
+func TestYAMLPresenter_CallFiltered(t *testing.T) {
+	out, err := NewYAML().CallFiltered([]interface{}{map[string]interface{}{"id": json.Number("1"), "name": "makise"}})
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, "- id: 1\n  name: makise\n", string(b))
+}
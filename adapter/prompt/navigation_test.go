@@ -0,0 +1,46 @@
+package prompt
+
+import (
+	"testing"
+
+	goprompt "github.com/c-bata/go-prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type consoleParser struct {
+	goprompt.ConsoleParser
+	in []byte
+}
+
+func (p *consoleParser) Read() ([]byte, error) {
+	return p.in, nil
+}
+
+func Test_navigationParser(t *testing.T) {
+	cases := map[string]struct {
+		in       []byte
+		expected error
+	}{
+		"Ctrl+R":                  {in: []byte{0x12}, expected: ErrBack},
+		"Ctrl+T":                  {in: []byte{0x14}, expected: ErrSkip},
+		"Ctrl+O":                  {in: []byte{0x0f}, expected: ErrPreview},
+		"Ctrl+X":                  {in: []byte{0x18}, expected: ErrClear},
+		"Ctrl+P is for go-prompt": {in: []byte{0x10}},
+		"Ctrl+N is for go-prompt": {in: []byte{0x0e}},
+		"normal input":            {in: []byte("a")},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &prompt{}
+			b, err := (&navigationParser{ConsoleParser: &consoleParser{in: c.in}, p: p}).Read()
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, p.navigated)
+			if c.expected != nil {
+				assert.Equal(t, []byte{0x0d}, b)
+			} else {
+				assert.Equal(t, c.in, b)
+			}
+		})
+	}
+}
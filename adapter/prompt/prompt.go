@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"errors"
 	"io"
 
 	goprompt "github.com/c-bata/go-prompt"
@@ -8,6 +9,29 @@ import (
 	survey "gopkg.in/AlecAivazis/survey.v1"
 )
 
+// Errors which are returned by Input of prompts without executor when navigation keys are pressed.
+var (
+	// ErrBack is returned when Ctrl+R is pressed. It requests to go back to the previous input.
+	ErrBack = errors.New("go back to the previous input")
+	// ErrSkip is returned when Ctrl+T is pressed. It requests to skip the rest of inputs.
+	ErrSkip = errors.New("skip the rest of inputs")
+	// ErrPreview is returned when Ctrl+O is pressed. It requests to show a preview of inputs.
+	ErrPreview = errors.New("show a preview of inputs")
+	// ErrClear is returned when Ctrl+X is pressed. It requests to input an empty value
+	// without falling back to the default.
+	ErrClear = errors.New("clear the input")
+)
+
+// navigationKeys maps ASCII codes of navigation keys to errors which are returned by Input.
+// They must not be bound by go-prompt. For example, Ctrl+P and Ctrl+N are used to move
+// through the history and the completion menu.
+var navigationKeys = map[byte]error{
+	0x12: ErrBack,    // Ctrl+R
+	0x14: ErrSkip,    // Ctrl+T
+	0x0f: ErrPreview, // Ctrl+O
+	0x18: ErrClear,   // Ctrl+X
+}
+
 // Prompt provides interactive interfaces to receive user input.
 type Prompt interface {
 	// Run executes Input continually.
@@ -17,26 +41,44 @@ type Prompt interface {
 
 	// Input receives user entered input.
 	// Input will be abort when a user enters CTRL+d.
+	// If the prompt doesn't have executor, Input returns ErrBack, ErrSkip, ErrPreview or ErrClear
+	// when navigation keys are pressed.
 	Input() (string, error)
 
 	// Select displays a selection has options, opts.
 	Select(msg string, opts []string) (string, error)
 
+	// SelectWithDefault is the same as Select, but def is selected at first if opts has it.
+	SelectWithDefault(msg string, opts []string, def string) (string, error)
+
 	// SetPrefix changes the current prompt prefix by passed one.
 	SetPrefix(prefix string)
 
 	// SetPrefixColor changes the current prompt color by passed one.
 	SetPrefixColor(color color.Color) error
 
+	// SetCompleter changes the completer of Input. f receives the text before the cursor and returns candidates
+	// which replace the last word of the text. It is used only if the prompt is instantiated without completer.
+	SetCompleter(f func(text string) []Suggestion)
+
 	// History returns Input() history as a string slice.
 	// A older command is a newer executed command.
 	History() []string
 }
 
+// Suggestion is a completion candidate of Input.
+type Suggestion struct {
+	Text        string
+	Description string
+}
+
 type prompt struct {
 	fieldPrompt   *goprompt.Prompt
 	currentPrefix string
 
+	// completer is set by SetCompleter.
+	completer func(text string) []Suggestion
+
 	hasExecutor bool
 
 	history []string
@@ -52,6 +94,29 @@ type prompt struct {
 	//    If the input is empty string and entered field is false, it is EOF.
 	// 4. Input returns io.EOF as an error.
 	entered bool
+
+	// navigated is the error of the navigation key which is pressed during Input.
+	// Like entered, it is necessary because c-bata/go-prompt returns from Input only when enter is pressed.
+	// navigationParser translates navigation keys into enter, and sets navigated.
+	navigated error
+}
+
+// navigationParser is a console parser which translates navigation keys into enter.
+type navigationParser struct {
+	goprompt.ConsoleParser
+	p *prompt
+}
+
+func (n *navigationParser) Read() ([]byte, error) {
+	b, err := n.ConsoleParser.Read()
+	if err != nil || len(b) != 1 {
+		return b, err
+	}
+	if nav, ok := navigationKeys[b[0]]; ok {
+		n.p.navigated = nav
+		return []byte{0x0d}, nil
+	}
+	return b, nil
 }
 
 // New instantiates a prompt which satisfied Prompt with c-bata/go-prompt.
@@ -77,9 +142,10 @@ func newPrompt(executor func(string), completer func(goprompt.Document) []goprom
 		}
 	}
 	if completer == nil {
-		completer = func(d goprompt.Document) []goprompt.Suggest {
-			return nil
-		}
+		completer = p.complete
+	}
+	if executor == nil {
+		opt = append(opt, goprompt.OptionParser(&navigationParser{ConsoleParser: goprompt.NewStandardInputParser(), p: p}))
 	}
 	p.fieldPrompt = goprompt.New(
 		wrappedExecutor,
@@ -108,7 +174,11 @@ func (p *prompt) Run() {
 
 func (p *prompt) Input() (string, error) {
 	p.entered = false
+	p.navigated = nil
 	in := p.fieldPrompt.Input()
+	if p.navigated != nil {
+		return "", p.navigated
+	}
 	// ctrl+d
 	if !p.entered && in == "" {
 		return "", io.EOF
@@ -117,11 +187,23 @@ func (p *prompt) Input() (string, error) {
 }
 
 func (p *prompt) Select(msg string, opts []string) (string, error) {
-	var choice string
-	err := survey.AskOne(&survey.Select{
+	return p.SelectWithDefault(msg, opts, "")
+}
+
+func (p *prompt) SelectWithDefault(msg string, opts []string, def string) (string, error) {
+	s := &survey.Select{
 		Message: msg,
 		Options: opts,
-	}, &choice, nil)
+	}
+	// survey fails if the default is not one of the options.
+	for _, o := range opts {
+		if o == def {
+			s.Default = def
+			break
+		}
+	}
+	var choice string
+	err := survey.AskOne(s, &choice, nil)
 	if err != nil {
 		return "", io.EOF
 	}
@@ -136,6 +218,22 @@ func (p *prompt) SetPrefixColor(color color.Color) error {
 	return goprompt.OptionPrefixTextColor(goprompt.Color(color))(p.fieldPrompt)
 }
 
+func (p *prompt) SetCompleter(f func(text string) []Suggestion) {
+	p.completer = f
+}
+
+// complete converts candidates of the completer which is set by SetCompleter.
+func (p *prompt) complete(d goprompt.Document) []goprompt.Suggest {
+	if p.completer == nil {
+		return nil
+	}
+	var s []goprompt.Suggest
+	for _, c := range p.completer(d.TextBeforeCursor()) {
+		s = append(s, goprompt.Suggest{Text: c.Text, Description: c.Description})
+	}
+	return s
+}
+
 func (p *prompt) livePrefix() (string, bool) {
 	return p.currentPrefix, true
 }
@@ -0,0 +1,73 @@
+package protobuf
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/logger"
+
+	// register error detail types of google.rpc.Status.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// errorDetailsProto defines error detail types of google.rpc which are not provided by errdetails package.
+const errorDetailsProto = `syntax = "proto3";
+
+package google.rpc;
+
+message ErrorInfo {
+  string reason = 1;
+  string domain = 2;
+  map<string, string> metadata = 3;
+}
+`
+
+var (
+	errorDetailsFile     *desc.FileDescriptor
+	errorDetailsFileOnce sync.Once
+)
+
+func loadErrorDetailsFile() *desc.FileDescriptor {
+	errorDetailsFileOnce.Do(func() {
+		const name = "evans/google/rpc/error_details.proto"
+		p := &protoparse.Parser{
+			Accessor: protoparse.FileContentsFromMap(map[string]string{name: errorDetailsProto}),
+		}
+		fds, err := p.ParseFiles(name)
+		if err != nil {
+			logger.Printf("failed to parse built-in error details: %s", err)
+			return
+		}
+		errorDetailsFile = fds[0]
+	})
+	return errorDetailsFile
+}
+
+// NewAnyResolver returns a jsonpb.AnyResolver which resolves google.protobuf.Any from messages in pkgs.
+// If a type is not found in pkgs, it is resolved from registered types such as google.rpc.BadRequest.
+func NewAnyResolver(pkgs []*entity.Package) jsonpb.AnyResolver {
+	var files []*desc.FileDescriptor
+	encountered := map[*desc.FileDescriptor]bool{}
+	for _, pkg := range pkgs {
+		for _, m := range pkg.Messages {
+			msg, ok := m.(*message)
+			if !ok {
+				continue
+			}
+			f := msg.d.GetFile()
+			if encountered[f] {
+				continue
+			}
+			encountered[f] = true
+			files = append(files, f)
+		}
+	}
+	if f := loadErrorDetailsFile(); f != nil {
+		files = append(files, f)
+	}
+	return dynamic.AnyResolver(nil, files...)
+}
@@ -0,0 +1,45 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func TestNewAnyResolver(t *testing.T) {
+	d := parseFile(t, []string{"helloworld.proto"}, nil)
+	pkgs, err := ToEntitiesFrom(d)
+	require.NoError(t, err)
+
+	r := NewAnyResolver(pkgs)
+
+	t.Run("loaded message", func(t *testing.T) {
+		m, err := r.Resolve("type.googleapis.com/helloworld.HelloRequest")
+		require.NoError(t, err)
+		dm, ok := m.(*dynamic.Message)
+		require.True(t, ok)
+		assert.Equal(t, "helloworld.HelloRequest", dm.GetMessageDescriptor().GetFullyQualifiedName())
+	})
+
+	t.Run("registered error detail", func(t *testing.T) {
+		m, err := r.Resolve("type.googleapis.com/google.rpc.BadRequest")
+		require.NoError(t, err)
+		assert.IsType(t, &errdetails.BadRequest{}, m)
+	})
+
+	t.Run("built-in error detail", func(t *testing.T) {
+		m, err := r.Resolve("type.googleapis.com/google.rpc.ErrorInfo")
+		require.NoError(t, err)
+		dm, ok := m.(*dynamic.Message)
+		require.True(t, ok)
+		assert.Equal(t, "google.rpc.ErrorInfo", dm.GetMessageDescriptor().GetFullyQualifiedName())
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		_, err := r.Resolve("type.googleapis.com/foo.Bar")
+		assert.Error(t, err)
+	})
+}
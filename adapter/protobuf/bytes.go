@@ -0,0 +1,51 @@
+package protobuf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// convertBytes converts an input of bytes fields. The following inputs are accepted:
+//
+//	base64:<base64>   standard base64 like base64:aGVsbG8=
+//	hex:<hex>         hex like hex:68656c6c6f
+//	@<path>           the content of the file
+//	otherwise         UTF-8 text. Escape sequences like \x00 or \u3042 are also interpreted.
+func convertBytes(in string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(in, "base64:"):
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(in, "base64:"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base64")
+		}
+		return b, nil
+	case strings.HasPrefix(in, "hex:"):
+		b, err := hex.DecodeString(strings.TrimPrefix(in, "hex:"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid hex")
+		}
+		return b, nil
+	case strings.HasPrefix(in, "@"):
+		path, err := homedir.Expand(strings.TrimPrefix(in, "@"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to expand the file path")
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the file")
+		}
+		return b, nil
+	default:
+		s, err := strconv.Unquote(`"` + in + `"`)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	}
+}
@@ -0,0 +1,45 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_convertBytes(t *testing.T) {
+	f, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.Write([]byte{0x00, 0xff})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	cases := map[string]struct {
+		in       string
+		expected []byte
+		hasErr   bool
+	}{
+		"text":           {in: "hello", expected: []byte("hello")},
+		"escaped text":   {in: `\x00あ`, expected: []byte("\x00あ")},
+		"base64":         {in: "base64:aGVsbG8=", expected: []byte("hello")},
+		"invalid base64": {in: "base64:aGVsbG8", hasErr: true},
+		"hex":            {in: "hex:00ff", expected: []byte{0x00, 0xff}},
+		"invalid hex":    {in: "hex:0g", hasErr: true},
+		"file":           {in: "@" + f.Name(), expected: []byte{0x00, 0xff}},
+		"missing file":   {in: "@" + f.Name() + ".missing", hasErr: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := convertBytes(c.in)
+			if c.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
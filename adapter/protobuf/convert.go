@@ -92,8 +92,7 @@ func ConvertValue(pv string, field entity.PrimitiveField) (interface{}, error) {
 	// not interpreted as an escape sequence.
 	// So, we need to call strconv.Unquote to interpret backslashes as an escape sequence.
 	case descriptor.FieldDescriptorProto_TYPE_BYTES:
-		pv, err = strconv.Unquote(`"` + pv + `"`)
-		v = []byte(pv)
+		v, err = convertBytes(pv)
 
 	case descriptor.FieldDescriptorProto_TYPE_SFIXED64:
 		v, err = strconv.ParseUint(pv, 10, 64)
@@ -169,6 +168,9 @@ func ToEntitiesFromServiceDescriptors(services []*desc.ServiceDescriptor) ([]ent
 				}
 
 				for _, f := range msg.Fields() {
+					if m, ok := f.(*mapField); ok {
+						f = m.value
+					}
 					if f.Type() != entity.FieldTypeMessage {
 						continue
 					}
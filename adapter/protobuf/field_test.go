@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/jhump/protoreflect/desc"
+	"github.com/ktr0731/evans/entity"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,3 +40,39 @@ func TestField(t *testing.T) {
 		assert.True(t, fields[0].IsRepeated())
 	})
 }
+
+func TestMapField(t *testing.T) {
+	d := parseFile(t, []string{"map.proto"}, nil)
+	require.Len(t, d, 1)
+
+	m := newMessage(d[0].FindMessage("example.MapRequest"))
+	fields := m.Fields()
+	require.Len(t, fields, 3)
+
+	cases := []struct {
+		name      string
+		pbType    string
+		keyType   string
+		valueType entity.FieldType
+	}{
+		{name: "counts", pbType: "map<TYPE_STRING, TYPE_INT32>", keyType: "TYPE_STRING", valueType: entity.FieldTypePrimitive},
+		{name: "items", pbType: "map<TYPE_INT64, TYPE_MESSAGE>", keyType: "TYPE_INT64", valueType: entity.FieldTypeMessage},
+		{name: "statuses", pbType: "map<TYPE_STRING, TYPE_ENUM>", keyType: "TYPE_STRING", valueType: entity.FieldTypeEnum},
+	}
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, ok := fields[i].(entity.MapField)
+			require.True(t, ok)
+			assert.Equal(t, c.name, f.FieldName())
+			assert.Equal(t, entity.FieldTypeMap, f.Type())
+			assert.False(t, f.IsRepeated())
+			assert.Equal(t, c.pbType, f.PBType())
+			assert.Equal(t, c.keyType, f.Key().PBType())
+			assert.Equal(t, c.valueType, f.Value().Type())
+		})
+	}
+
+	setter := NewMessageSetter(m)
+	require.NoError(t, setter.PutMapEntry(fields[0].(entity.MapField), "foo", int32(1)))
+	require.Equal(t, `counts:<key:"foo" value:1>`, setter.Done().String())
+}
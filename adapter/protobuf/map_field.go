@@ -0,0 +1,43 @@
+package protobuf
+
+import (
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/ktr0731/evans/entity"
+)
+
+type mapField struct {
+	d *desc.FieldDescriptor
+
+	key, value entity.Field
+}
+
+func (f *mapField) FieldName() string {
+	return f.d.GetName()
+}
+
+func (f *mapField) FQRN() string {
+	return f.d.GetFullyQualifiedName()
+}
+
+func (f *mapField) Type() entity.FieldType {
+	return entity.FieldTypeMap
+}
+
+// IsRepeated always returns false because map fields are not regarded as repeated fields of entries.
+func (f *mapField) IsRepeated() bool {
+	return false
+}
+
+func (f *mapField) PBType() string {
+	return fmt.Sprintf("map<%s, %s>", f.key.PBType(), f.value.PBType())
+}
+
+func (f *mapField) Key() entity.Field {
+	return f.key
+}
+
+func (f *mapField) Value() entity.Field {
+	return f.value
+}
@@ -36,8 +36,6 @@ func (b *messageBuilder) add(f entity.Field) {
 	b.m.fields = append(b.m.fields, f)
 }
 
-// maps are also interpret as a repeated message type.
-// ref. https://developers.google.com/protocol-buffers/docs/proto#backwards-compatibility
 func (b *messageBuilder) processMessageField(f *desc.FieldDescriptor) entity.Field {
 	field := &messageField{
 		d: f,
@@ -79,6 +77,12 @@ func (b *messageBuilder) addOneOfField(d *desc.OneOfDescriptor) {
 func (b *messageBuilder) processField(d *desc.FieldDescriptor) entity.Field {
 	var f entity.Field
 	switch {
+	case d.IsMap():
+		f = &mapField{
+			d:     d,
+			key:   newPrimitiveField(d.GetMapKeyType()),
+			value: b.processField(d.GetMapValueType()),
+		}
 	case isMessageType(d.AsFieldDescriptorProto().GetType()):
 		f = b.processMessageField(d)
 	case isEnumType(d):
@@ -29,3 +29,11 @@ func (f *messageField) IsRepeated() bool {
 func (f *messageField) PBType() string {
 	return f.d.GetType().String()
 }
+
+func (f *messageField) WellKnownType() string {
+	name := f.d.GetMessageType().GetFullyQualifiedName()
+	if _, ok := wellKnownTypeConverters[name]; ok {
+		return name
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/ktr0731/evans/entity"
 )
@@ -35,6 +36,17 @@ func (s *MessageSetter) SetField(f entity.Field, v interface{}) error {
 		}
 		return s.m.TrySetField(f.d, v)
 	case *messageField:
+		// a value of well-known types can be passed as a string. See ConvertWellKnownValue.
+		if in, ok := v.(string); ok && f.WellKnownType() != "" {
+			m, err := ConvertWellKnownValue(in, f)
+			if err != nil {
+				return err
+			}
+			if m == nil {
+				return nil
+			}
+			v = m
+		}
 		if f.IsRepeated() {
 			return s.m.TryAddRepeatedField(f.d, v)
 		}
@@ -49,6 +61,79 @@ func (s *MessageSetter) SetField(f entity.Field, v interface{}) error {
 	}
 }
 
+// PutMapEntry puts an entry which consists of k and v to the map field f.
+func (s *MessageSetter) PutMapEntry(f entity.MapField, k, v interface{}) error {
+	mf, ok := f.(*mapField)
+	if !ok {
+		return errors.New("unknown type: " + f.PBType())
+	}
+	return s.m.TryPutMapField(mf.d, k, v)
+}
+
+// ClearField clears the value of f. If f is a oneof field, the value of the chosen field is cleared.
+func (s *MessageSetter) ClearField(f entity.Field) error {
+	if f, ok := f.(*oneOfField); ok {
+		return s.m.TryClearOneOfField(f.d)
+	}
+	d, err := fieldDescriptor(f)
+	if err != nil {
+		return err
+	}
+	return s.m.TryClearField(d)
+}
+
+// RemoveLastRepeatedField removes the last element of the repeated field f.
+func (s *MessageSetter) RemoveLastRepeatedField(f entity.Field) error {
+	d, err := fieldDescriptor(f)
+	if err != nil {
+		return err
+	}
+	n := s.m.FieldLength(d)
+	if n == 0 {
+		return nil
+	}
+	v, err := s.m.TryGetField(d)
+	if err != nil {
+		return err
+	}
+	elems, ok := v.([]interface{})
+	if !ok {
+		return errors.New("not a repeated field: " + f.FieldName())
+	}
+	return s.m.TrySetField(d, elems[:n-1])
+}
+
+// RemoveMapEntry removes the entry which has the key k from the map field f.
+func (s *MessageSetter) RemoveMapEntry(f entity.MapField, k interface{}) error {
+	mf, ok := f.(*mapField)
+	if !ok {
+		return errors.New("unknown type: " + f.PBType())
+	}
+	return s.m.TryRemoveMapField(mf.d, k)
+}
+
+// Clone returns a copy of s. It is used to inspect the message which is being built.
+func (s *MessageSetter) Clone() *MessageSetter {
+	m := dynamic.NewMessage(s.m.GetMessageDescriptor())
+	m.Merge(s.m)
+	return &MessageSetter{m: m}
+}
+
+func fieldDescriptor(f entity.Field) (*desc.FieldDescriptor, error) {
+	switch f := f.(type) {
+	case *primitiveField:
+		return f.d, nil
+	case *enumField:
+		return f.d, nil
+	case *messageField:
+		return f.d, nil
+	case *mapField:
+		return f.d, nil
+	default:
+		return nil, errors.New("unknown type: " + f.PBType())
+	}
+}
+
 func (s *MessageSetter) Done() proto.Message {
 	m := s.m
 	s.m = nil
@@ -0,0 +1,125 @@
+package protobuf
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/ktr0731/evans/entity"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// wellKnownTypeConverters converts an input to a message of the well-known type.
+// The key is the fully-qualified name of the type.
+var wellKnownTypeConverters = map[string]func(in string, f *messageField) (proto.Message, error){
+	"google.protobuf.Timestamp": convertTimestamp,
+	"google.protobuf.Duration":  convertDuration,
+	"google.protobuf.FieldMask": convertFieldMask,
+
+	"google.protobuf.Struct":    convertJSON(func() proto.Message { return &structpb.Struct{} }),
+	"google.protobuf.Value":     convertJSON(func() proto.Message { return &structpb.Value{} }),
+	"google.protobuf.ListValue": convertJSON(func() proto.Message { return &structpb.ListValue{} }),
+
+	"google.protobuf.DoubleValue": convertWrapper,
+	"google.protobuf.FloatValue":  convertWrapper,
+	"google.protobuf.Int64Value":  convertWrapper,
+	"google.protobuf.UInt64Value": convertWrapper,
+	"google.protobuf.Int32Value":  convertWrapper,
+	"google.protobuf.UInt32Value": convertWrapper,
+	"google.protobuf.BoolValue":   convertWrapper,
+	"google.protobuf.StringValue": convertWrapper,
+	"google.protobuf.BytesValue":  convertWrapper,
+}
+
+// ConvertWellKnownValue converts in to a message of the well-known type of field.
+// The following inputs are accepted:
+//
+//   google.protobuf.Timestamp  RFC3339 like 2006-01-02T15:04:05Z, or "now"
+//   google.protobuf.Duration   a duration like 1h30m
+//   google.protobuf.FieldMask  comma-separated paths like foo.bar,baz
+//   google.protobuf.Struct     JSON (Value and ListValue are also the same)
+//   wrapper types              a single value of the wrapped type
+//
+// If in is empty, ConvertWellKnownValue returns nil. It means the field is not set.
+func ConvertWellKnownValue(in string, field entity.MessageField) (proto.Message, error) {
+	f, ok := field.(*messageField)
+	if !ok {
+		return nil, errors.New("type assertion failed")
+	}
+	name := f.WellKnownType()
+	if name == "" {
+		return nil, errors.Errorf("%s is not a well-known type", f.d.GetMessageType().GetFullyQualifiedName())
+	}
+	if in == "" {
+		return nil, nil
+	}
+
+	m, err := wellKnownTypeConverters[name](in, f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", name)
+	}
+	// convert to a dynamic message to set it to other dynamic messages.
+	dm := dynamic.NewMessage(f.d.GetMessageType())
+	if err := dm.MergeFrom(m); err != nil {
+		return nil, errors.Wrapf(err, "failed to convert %s", name)
+	}
+	return dm, nil
+}
+
+func convertTimestamp(in string, _ *messageField) (proto.Message, error) {
+	if in == "now" {
+		return ptypes.TimestampNow(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, in)
+	if err != nil {
+		return nil, err
+	}
+	return ptypes.TimestampProto(t)
+}
+
+func convertDuration(in string, _ *messageField) (proto.Message, error) {
+	d, err := time.ParseDuration(in)
+	if err != nil {
+		return nil, err
+	}
+	return ptypes.DurationProto(d), nil
+}
+
+func convertFieldMask(in string, _ *messageField) (proto.Message, error) {
+	var paths []string
+	for _, p := range strings.Split(in, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return &field_mask.FieldMask{Paths: paths}, nil
+}
+
+func convertJSON(newMessage func() proto.Message) func(string, *messageField) (proto.Message, error) {
+	return func(in string, _ *messageField) (proto.Message, error) {
+		m := newMessage()
+		if err := jsonpb.UnmarshalString(in, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+// convertWrapper converts in to a wrapper type message by the same way as a primitive field.
+func convertWrapper(in string, f *messageField) (proto.Message, error) {
+	value := f.d.GetMessageType().FindFieldByName("value")
+	v, err := ConvertValue(in, newPrimitiveField(value))
+	if err != nil {
+		return nil, err
+	}
+	m := dynamic.NewMessage(f.d.GetMessageType())
+	if err := m.TrySetField(value, v); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,82 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ktr0731/evans/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertWellKnownValue(t *testing.T) {
+	d := parseFile(t, []string{"well_known.proto"}, nil)
+	m := newMessage(d[0].FindMessage("example.WellKnownRequest"))
+
+	fields := map[string]entity.MessageField{}
+	for _, f := range m.Fields() {
+		fields[f.FieldName()] = f.(entity.MessageField)
+	}
+
+	cases := map[string]struct {
+		field    string
+		in       string
+		expected string
+		hasError bool
+	}{
+		"timestamp":         {field: "timestamp", in: "2019-01-02T03:04:05Z", expected: `"2019-01-02T03:04:05Z"`},
+		"timestamp (nanos)": {field: "timestamp", in: "2019-01-02T03:04:05.5+09:00", expected: `"2019-01-01T18:04:05.500Z"`},
+		"invalid timestamp": {field: "timestamp", in: "yesterday", hasError: true},
+		"duration":          {field: "duration", in: "1h30m", expected: `"5400s"`},
+		"invalid duration":  {field: "duration", in: "1 hour", hasError: true},
+		"int64 wrapper":     {field: "int64", in: "100", expected: `"100"`},
+		"invalid wrapper":   {field: "int64", in: "foo", hasError: true},
+		"string wrapper":    {field: "string", in: "foo", expected: `"foo"`},
+		"struct":            {field: "struct", in: `{"foo": [1, "bar"]}`, expected: `{"foo":[1,"bar"]}`},
+		"invalid struct":    {field: "struct", in: `[1]`, hasError: true},
+		"list":              {field: "list", in: `[true, null]`, expected: `[true,null]`},
+		"field mask":        {field: "mask", in: "foo.bar, baz", expected: `{"paths":["foo.bar","baz"]}`},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := fields[c.field]
+			require.NotEmpty(t, f.WellKnownType())
+
+			v, err := ConvertWellKnownValue(c.in, f)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			setter := NewMessageSetter(m)
+			require.NoError(t, setter.SetField(f, v))
+			actual, err := (&jsonpb.Marshaler{}).MarshalToString(setter.Done())
+			require.NoError(t, err)
+			assert.Equal(t, `{"`+c.field+`":`+c.expected+`}`, actual)
+		})
+	}
+
+	t.Run("now", func(t *testing.T) {
+		v, err := ConvertWellKnownValue("now", fields["timestamp"])
+		require.NoError(t, err)
+		require.NotNil(t, v)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		v, err := ConvertWellKnownValue("", fields["duration"])
+		require.NoError(t, err)
+		require.Nil(t, v)
+
+		setter := NewMessageSetter(m)
+		require.NoError(t, setter.SetField(fields["duration"], ""))
+		require.Equal(t, "", setter.Done().String())
+	})
+
+	t.Run("setter accepts a string", func(t *testing.T) {
+		setter := NewMessageSetter(m)
+		require.NoError(t, setter.SetField(fields["duration"], "2s"))
+		require.Equal(t, `duration:<seconds:2>`, setter.Done().String())
+	})
+}
@@ -1,13 +1,25 @@
 package repl
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
+	"time"
 	"unicode"
 
+	"github.com/ktr0731/evans/adapter/grpc"
+	"github.com/ktr0731/evans/adapter/inputter"
+	"github.com/ktr0731/evans/cache"
+	"github.com/ktr0731/evans/config"
 	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/usecase"
 	"github.com/ktr0731/evans/usecase/port"
+	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 )
 
 type commander interface {
@@ -26,18 +38,62 @@ func (c *descCommand) Synopsis() string {
 }
 
 func (c *descCommand) Help() string {
-	return "usage: desc <message name>"
+	return `usage: desc [options] <message name>
+
+options:
+  --template            show an example JSON document of the message instead of its structure`
+}
+
+// parseArgs parses options and the message name which are passed to desc command.
+func (c *descCommand) parseArgs(args []string) (*port.DescribeParams, error) {
+	fs := pflag.NewFlagSet("desc", pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	template := fs.Bool("template", false, "")
+	if err := fs.Parse(args); err != nil {
+		return nil, errors.Wrap(err, "failed to parse options")
+	}
+	if fs.NArg() < 1 {
+		return nil, errors.Wrap(ErrArgumentRequired, "message name")
+	}
+	return &port.DescribeParams{MsgName: fs.Arg(0), Template: *template}, nil
 }
 
 func (c *descCommand) Validate(args []string) error {
+	_, err := c.parseArgs(args)
+	return err
+}
+
+func (c *descCommand) Run(args []string) (io.Reader, error) {
+	params, err := c.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return c.inputPort.Describe(params)
+}
+
+type templateCommand struct {
+	inputPort port.InputPort
+}
+
+func (c *templateCommand) Synopsis() string {
+	return "show an example JSON document of selected message"
+}
+
+func (c *templateCommand) Help() string {
+	return `usage: template <message name>
+
+the document can be used as a request file of CLI mode or call --file.`
+}
+
+func (c *templateCommand) Validate(args []string) error {
 	if len(args) < 1 {
 		return errors.Wrap(ErrArgumentRequired, "message name")
 	}
 	return nil
 }
 
-func (c *descCommand) Run(args []string) (io.Reader, error) {
-	params := &port.DescribeParams{MsgName: args[0]}
+func (c *templateCommand) Run(args []string) (io.Reader, error) {
+	params := &port.DescribeParams{MsgName: args[0], Template: true}
 	return c.inputPort.Describe(params)
 }
 
@@ -102,7 +158,7 @@ func (c *showCommand) Synopsis() string {
 }
 
 func (c *showCommand) Help() string {
-	return "usage: show <package | service | message | rpc | header>"
+	return "usage: show <package | service | message | rpc | header | variable>"
 }
 
 func (c *showCommand) Validate(args []string) error {
@@ -127,6 +183,8 @@ func (c *showCommand) Run(args []string) (io.Reader, error) {
 		params.Type = port.ShowTypeRPC
 	case "h", "header", "headers":
 		params.Type = port.ShowTypeHeader
+	case "v", "var", "variable", "variables":
+		params.Type = port.ShowTypeVariable
 	default:
 		return nil, errors.Wrap(ErrUnknownTarget, target)
 	}
@@ -135,6 +193,13 @@ func (c *showCommand) Run(args []string) (io.Reader, error) {
 
 type callCommand struct {
 	inputPort port.InputPort
+
+	// timeout is the default timeout of each RPC call.
+	// It is overridden by --timeout option.
+	timeout time.Duration
+
+	// env is used to interpolate references to variables in requests which are passed by --data or --file.
+	env env.Environment
 }
 
 func (c *callCommand) Synopsis() string {
@@ -142,18 +207,150 @@ func (c *callCommand) Synopsis() string {
 }
 
 func (c *callCommand) Help() string {
-	return "usage: call <RPC name>"
+	return `usage: call [options] <RPC name>
+
+options:
+  --timeout <duration>  the timeout of the RPC call (e.g. 2s, 500ms). 0 means no timeout
+  --filter <expr>       a jq-style expression which extracts values from each response (e.g. '.items[].name')
+  --data <json>         the request in JSON instead of inputting it interactively
+  --file <path>         the file which has the request in JSON instead of inputting it interactively
+  --edit                edit the request in JSON with $EDITOR instead of inputting it interactively
+  --reset               forget the remembered input values of the RPC before calling it
+  --compression <name>  compress request messages by the compressor (e.g. gzip) instead of the default one
+
+field values which are input interactively are remembered per RPC, and they are used as defaults
+of the next input. press enter to accept the default, or ctrl+x to input an empty value instead.
+
+keys which are available while inputting fields:
+  ctrl+r                go back to the previous field and input it again
+  ctrl+t                skip the rest of fields of the current message
+  ctrl+o                show a preview of the request built so far
+  ctrl+x                input an empty value instead of the default
+
+for client streaming and bidirectional streaming RPCs, --data and --file can have multiple JSON messages.
+with --edit, the editor is opened for each message until an empty request is saved.
+
+references to variables like $last.user.id in field inputs and JSON requests are replaced with their values.
+a literal '$' is input by escaping it as '$$'.
+
+the filter can also be specified after a pipe like 'call ListUsers | .users[0]'.
+
+options for server streaming and bidirectional streaming RPCs:
+  --max-messages <n>    stop receiving after n messages. 0 means no limit
+  --duration <duration> stop receiving after the duration (e.g. 10s). 0 means no limit
+  --timestamp           show the sequence number and the receive time of each message
+  --summary             show the summary when the stream is finished`
 }
 
-func (c *callCommand) Validate(args []string) error {
-	if len(args) < 1 {
-		return errors.Wrap(ErrArgumentRequired, "service or RPC name")
+// callRequestOptions are options of call command which specify how requests are input.
+type callRequestOptions struct {
+	data string
+	file string
+	edit bool
+	// reset reports whether remembered input values of the RPC should be cleared.
+	reset bool
+}
+
+// parseArgs parses options and the RPC name which are passed to call command.
+// It doesn't have any side effects like reading files because it is also called by Validate.
+func (c *callCommand) parseArgs(args []string) (*port.CallParams, *callRequestOptions, error) {
+	var opts callRequestOptions
+	fs := pflag.NewFlagSet("call", pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	timeout := fs.Duration("timeout", c.timeout, "")
+	var stream port.StreamParams
+	fs.IntVar(&stream.MaxMessages, "max-messages", 0, "")
+	fs.DurationVar(&stream.Duration, "duration", 0, "")
+	fs.BoolVar(&stream.ShowTimestamp, "timestamp", false, "")
+	fs.BoolVar(&stream.ShowSummary, "summary", false, "")
+	filter := fs.String("filter", "", "")
+	fs.StringVar(&opts.data, "data", "", "")
+	fs.StringVar(&opts.file, "file", "", "")
+	fs.BoolVar(&opts.edit, "edit", false, "")
+	fs.BoolVar(&opts.reset, "reset", false, "")
+	compression := fs.String("compression", "", "")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse options")
 	}
-	return nil
+	if fs.NArg() < 1 {
+		return nil, nil, errors.Wrap(ErrArgumentRequired, "service or RPC name")
+	}
+	if stream.MaxMessages < 0 || stream.Duration < 0 {
+		return nil, nil, errors.New("--max-messages and --duration must not be negative")
+	}
+	if opts.data != "" && opts.file != "" || opts.edit && (opts.data != "" || opts.file != "") {
+		return nil, nil, errors.New("only one of --data, --file and --edit can be specified")
+	}
+	if opts.edit && config.Editor() == "" {
+		return nil, nil, errors.New("--edit requires one of $EDITOR value or Vim")
+	}
+	if err := grpc.ValidateCompressor(*compression); err != nil {
+		return nil, nil, err
+	}
+	params := &port.CallParams{
+		RPCName:     fs.Arg(0),
+		Timeout:     *timeout,
+		Stream:      stream,
+		Filter:      *filter,
+		Compression: *compression,
+	}
+	return params, &opts, nil
+}
+
+// requestInputter returns the inputter which is specified by opts.
+// If opts doesn't specify any inputter, it returns nil to use the default inputter.
+func (c *callCommand) requestInputter(rpcName string, opts *callRequestOptions) (port.Inputter, error) {
+	switch {
+	case opts.edit:
+		return inputter.NewEditor(config.Editor(), c.rpcFQRN(rpcName), c.env), nil
+	case opts.data != "":
+		return inputter.NewJSONFile(strings.NewReader(opts.data), c.env), nil
+	case opts.file != "":
+		path, err := homedir.Expand(opts.file)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to expand the file path")
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the request file")
+		}
+		return inputter.NewJSONFile(bytes.NewReader(b), c.env), nil
+	default:
+		return nil, nil
+	}
+}
+
+// rpcFQRN returns the fully-qualified name of the RPC. It returns name as it is if the RPC is not found.
+func (c *callCommand) rpcFQRN(name string) string {
+	if c.env == nil {
+		return name
+	}
+	rpc, err := c.env.RPC(name)
+	if err != nil {
+		return name
+	}
+	return rpc.FQRN()
+}
+
+func (c *callCommand) Validate(args []string) error {
+	_, _, err := c.parseArgs(args)
+	return err
 }
 
 func (c *callCommand) Run(args []string) (io.Reader, error) {
-	params := &port.CallParams{RPCName: args[0]}
+	params, opts, err := c.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	params.Inputter, err = c.requestInputter(params.RPCName, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.reset {
+		if err := cache.Get().ClearLastFieldValues(c.rpcFQRN(params.RPCName)).Save(); err != nil {
+			return nil, errors.Wrap(err, "failed to clear remembered input values")
+		}
+	}
 	res, err := c.inputPort.Call(params)
 	if err == io.EOF {
 		return strings.NewReader("inputting canceled\n"), nil
@@ -161,6 +358,66 @@ func (c *callCommand) Run(args []string) (io.Reader, error) {
 	return res, err
 }
 
+type benchCommand struct {
+	inputPort port.InputPort
+
+	// timeout is the default timeout of each RPC call.
+	// It is overridden by --timeout option.
+	timeout time.Duration
+}
+
+func (c *benchCommand) Synopsis() string {
+	return "call a RPC repeatedly with interactively input, then report its performance"
+}
+
+func (c *benchCommand) Help() string {
+	return `usage: bench [options] <RPC name>
+
+options:
+  --concurrency <n>     the number of workers which call the RPC concurrently (default 10)
+  --count <n>           the number of RPC calls (default 200 if --duration is not specified)
+  --duration <duration> the duration of the load test (e.g. 10s). it is used with --count if both are specified
+  --qps <n>             the rate limit of RPC calls per second. 0 means no limit
+  --timeout <duration>  the timeout of each RPC call (e.g. 2s, 500ms). 0 means no timeout`
+}
+
+// parseArgs parses options and the RPC name which are passed to bench command.
+func (c *benchCommand) parseArgs(args []string) (*port.BenchParams, error) {
+	fs := pflag.NewFlagSet("bench", pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	params := &port.BenchParams{}
+	fs.IntVar(&params.Concurrency, "concurrency", usecase.DefaultBenchConcurrency, "")
+	fs.IntVar(&params.Count, "count", 0, "")
+	fs.DurationVar(&params.Duration, "duration", 0, "")
+	fs.IntVar(&params.QPS, "qps", 0, "")
+	fs.DurationVar(&params.Timeout, "timeout", c.timeout, "")
+	if err := fs.Parse(args); err != nil {
+		return nil, errors.Wrap(err, "failed to parse options")
+	}
+	if fs.NArg() < 1 {
+		return nil, errors.Wrap(ErrArgumentRequired, "service or RPC name")
+	}
+	params.RPCName = fs.Arg(0)
+	return params, nil
+}
+
+func (c *benchCommand) Validate(args []string) error {
+	_, err := c.parseArgs(args)
+	return err
+}
+
+func (c *benchCommand) Run(args []string) (io.Reader, error) {
+	params, err := c.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.inputPort.Bench(params)
+	if err == io.EOF {
+		return strings.NewReader("inputting canceled\n"), nil
+	}
+	return res, err
+}
+
 type headerCommand struct {
 	inputPort port.InputPort
 }
@@ -203,3 +460,94 @@ func (c *headerCommand) Run(args []string) (io.Reader, error) {
 	params := &port.HeaderParams{Headers: headers}
 	return c.inputPort.Header(params)
 }
+
+type saveCommand struct {
+	inputPort port.InputPort
+}
+
+func (c *saveCommand) Synopsis() string {
+	return "save the last response as a variable. it can be referenced as $<name> like $last."
+}
+
+func (c *saveCommand) Help() string {
+	return `usage: save <name>
+
+references to variables can be used in field inputs, header values and JSON requests.
+a literal '$' is input by escaping it as '$$' like '$$last.png'.`
+}
+
+func (c *saveCommand) Validate(args []string) error {
+	if len(args) < 1 {
+		return errors.Wrap(ErrArgumentRequired, "variable name")
+	}
+	return nil
+}
+
+func (c *saveCommand) Run(args []string) (io.Reader, error) {
+	return c.inputPort.Save(&port.SaveParams{Name: args[0]})
+}
+
+// outputFormatter changes the format of response messages.
+type outputFormatter interface {
+	Format() string
+	SetFormat(format string) error
+	Columns() []string
+	SetColumns(columns []string)
+	Template() string
+	SetTemplate(text string) error
+}
+
+type outputCommand struct {
+	formatter outputFormatter
+}
+
+func (c *outputCommand) Synopsis() string {
+	return "show or change the format of response messages"
+}
+
+func (c *outputCommand) Help() string {
+	return `usage: output [json | yaml | table [column...] | template [text] | text | binary | hex | base64]
+
+columns select the fields of repeated message fields which are shown by table format.
+text is a Go template which formats each response (e.g. '{{.id}},{{.name}}').
+if text is omitted, the last template is used.`
+}
+
+func (c *outputCommand) Validate(args []string) error {
+	if len(args) > 1 {
+		switch strings.ToLower(args[0]) {
+		case "table", "template":
+		default:
+			return errors.New("arguments can be specified only with table or template format")
+		}
+	}
+	return nil
+}
+
+func (c *outputCommand) Run(args []string) (io.Reader, error) {
+	if len(args) == 0 {
+		format := c.formatter.Format()
+		if cols := c.formatter.Columns(); format == "table" && len(cols) != 0 {
+			format += fmt.Sprintf(" (columns: %s)", strings.Join(cols, ", "))
+		}
+		if format == "template" {
+			format += fmt.Sprintf(" (%s)", c.formatter.Template())
+		}
+		return strings.NewReader(format + "\n"), nil
+	}
+
+	format := strings.ToLower(args[0])
+	if format == "template" && len(args) > 1 {
+		if err := c.formatter.SetTemplate(strings.Join(args[1:], " ")); err != nil {
+			return nil, err
+		}
+		return strings.NewReader(""), nil
+	}
+	if err := c.formatter.SetFormat(format); err != nil {
+		return nil, err
+	}
+	if len(args) > 1 {
+		c.formatter.SetColumns(args[1:])
+	}
+	return strings.NewReader(""), nil
+}
@@ -2,10 +2,16 @@ package repl
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ktr0731/evans/adapter/inputter"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -47,3 +53,292 @@ func Test_headCommand(t *testing.T) {
 		})
 	}
 }
+
+type callInputPort struct {
+	port.InputPort
+	received *port.CallParams
+	headers  []*entity.Header
+}
+
+func (i *callInputPort) Call(p *port.CallParams) (io.Reader, error) {
+	i.received = p
+	return nil, nil
+}
+
+func (i *callInputPort) Header(p *port.HeaderParams) (io.Reader, error) {
+	i.headers = p.Headers
+	return nil, nil
+}
+
+func Test_callCommand(t *testing.T) {
+	cases := map[string]struct {
+		args     []string
+		expected *port.CallParams
+		hasError bool
+	}{
+		"normal":          {args: []string{"SayHello"}, expected: &port.CallParams{RPCName: "SayHello", Timeout: 3 * time.Second}},
+		"with timeout":    {args: []string{"--timeout", "2s", "SayHello"}, expected: &port.CallParams{RPCName: "SayHello", Timeout: 2 * time.Second}},
+		"disable timeout": {args: []string{"--timeout=0", "SayHello"}, expected: &port.CallParams{RPCName: "SayHello"}},
+		"stream options": {
+			args: []string{"--max-messages", "10", "--duration", "5s", "--timestamp", "--summary", "SayHello"},
+			expected: &port.CallParams{
+				RPCName: "SayHello",
+				Timeout: 3 * time.Second,
+				Stream:  port.StreamParams{MaxMessages: 10, Duration: 5 * time.Second, ShowTimestamp: true, ShowSummary: true},
+			},
+		},
+		"with filter": {
+			args:     []string{"--filter", ".items[].name", "SayHello"},
+			expected: &port.CallParams{RPCName: "SayHello", Timeout: 3 * time.Second, Filter: ".items[].name"},
+		},
+		"with compression": {
+			args:     []string{"--compression", "gzip", "SayHello"},
+			expected: &port.CallParams{RPCName: "SayHello", Timeout: 3 * time.Second, Compression: "gzip"},
+		},
+		"RPC name is missing":   {args: []string{"--timeout", "2s"}, hasError: true},
+		"unknown compressor":    {args: []string{"--compression", "foo", "SayHello"}, hasError: true},
+		"invalid timeout":       {args: []string{"--timeout", "foo", "SayHello"}, hasError: true},
+		"negative max messages": {args: []string{"--max-messages", "-1", "SayHello"}, hasError: true},
+		"unknown option":        {args: []string{"--foo", "SayHello"}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			inputPort := &callInputPort{}
+			cmd := &callCommand{inputPort: inputPort, timeout: 3 * time.Second}
+
+			err := cmd.Validate(c.args)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = cmd.Run(c.args)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, inputPort.received)
+		})
+	}
+}
+
+func Test_callCommand_request(t *testing.T) {
+	f, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`{"name": "makise"}`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	oldEditor := os.Getenv("EDITOR")
+	os.Setenv("EDITOR", "vi")
+	defer os.Setenv("EDITOR", oldEditor)
+
+	cases := map[string]struct {
+		args     []string
+		inputter port.Inputter
+		hasError bool
+	}{
+		"data":              {args: []string{"--data", `{"name": "makise"}`, "SayHello"}, inputter: &inputter.JSONFile{}},
+		"file":              {args: []string{"--file", f.Name(), "SayHello"}, inputter: &inputter.JSONFile{}},
+		"edit":              {args: []string{"--edit", "SayHello"}, inputter: &inputter.Editor{}},
+		"both of them":      {args: []string{"--data", "{}", "--file", f.Name(), "SayHello"}, hasError: true},
+		"edit and data":     {args: []string{"--edit", "--data", "{}", "SayHello"}, hasError: true},
+		"file is not found": {args: []string{"--file", f.Name() + ".notfound", "SayHello"}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			inputPort := &callInputPort{}
+			cmd := &callCommand{inputPort: inputPort}
+
+			err := cmd.Validate(c.args)
+			if err == nil {
+				// files are read by Run, not by Validate.
+				_, err = cmd.Run(c.args)
+			}
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, c.inputter, inputPort.received.Inputter)
+		})
+	}
+
+	t.Run("Validate doesn't read the file", func(t *testing.T) {
+		cmd := &callCommand{inputPort: &callInputPort{}}
+		require.NoError(t, cmd.Validate([]string{"--file", f.Name() + ".notfound", "SayHello"}))
+	})
+}
+
+type benchInputPort struct {
+	port.InputPort
+	received *port.BenchParams
+}
+
+func (i *benchInputPort) Bench(p *port.BenchParams) (io.Reader, error) {
+	i.received = p
+	return nil, nil
+}
+
+func Test_benchCommand(t *testing.T) {
+	cases := map[string]struct {
+		args     []string
+		expected *port.BenchParams
+		hasError bool
+	}{
+		"normal": {
+			args:     []string{"SayHello"},
+			expected: &port.BenchParams{RPCName: "SayHello", Concurrency: 10, Timeout: 3 * time.Second},
+		},
+		"with options": {
+			args:     []string{"--concurrency", "5", "--count", "100", "--duration", "10s", "--qps", "50", "--timeout", "1s", "SayHello"},
+			expected: &port.BenchParams{RPCName: "SayHello", Concurrency: 5, Count: 100, Duration: 10 * time.Second, QPS: 50, Timeout: time.Second},
+		},
+		"RPC name is missing": {args: []string{"--count", "100"}, hasError: true},
+		"invalid count":       {args: []string{"--count", "foo", "SayHello"}, hasError: true},
+		"unknown option":      {args: []string{"--foo", "SayHello"}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			inputPort := &benchInputPort{}
+			cmd := &benchCommand{inputPort: inputPort, timeout: 3 * time.Second}
+
+			err := cmd.Validate(c.args)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = cmd.Run(c.args)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, inputPort.received)
+		})
+	}
+}
+
+type descInputPort struct {
+	port.InputPort
+	received *port.DescribeParams
+}
+
+func (i *descInputPort) Describe(p *port.DescribeParams) (io.Reader, error) {
+	i.received = p
+	return nil, nil
+}
+
+func Test_descCommand(t *testing.T) {
+	cases := map[string]struct {
+		cmd      commander
+		args     []string
+		expected *port.DescribeParams
+		hasError bool
+	}{
+		"desc":                     {cmd: &descCommand{}, args: []string{"HelloRequest"}, expected: &port.DescribeParams{MsgName: "HelloRequest"}},
+		"desc with template":       {cmd: &descCommand{}, args: []string{"--template", "HelloRequest"}, expected: &port.DescribeParams{MsgName: "HelloRequest", Template: true}},
+		"template":                 {cmd: &templateCommand{}, args: []string{"HelloRequest"}, expected: &port.DescribeParams{MsgName: "HelloRequest", Template: true}},
+		"desc message is missing":  {cmd: &descCommand{}, args: []string{"--template"}, hasError: true},
+		"template message missing": {cmd: &templateCommand{}, hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			inputPort := &descInputPort{}
+			switch cmd := c.cmd.(type) {
+			case *descCommand:
+				cmd.inputPort = inputPort
+			case *templateCommand:
+				cmd.inputPort = inputPort
+			}
+
+			err := c.cmd.Validate(c.args)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = c.cmd.Run(c.args)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, inputPort.received)
+		})
+	}
+}
+
+type fakeOutputFormatter struct {
+	format   string
+	columns  []string
+	template string
+}
+
+func (f *fakeOutputFormatter) Format() string { return f.format }
+
+func (f *fakeOutputFormatter) SetFormat(format string) error {
+	if format != "json" && format != "table" {
+		return errors.New("unknown format")
+	}
+	f.format = format
+	return nil
+}
+
+func (f *fakeOutputFormatter) Columns() []string { return f.columns }
+
+func (f *fakeOutputFormatter) SetColumns(columns []string) { f.columns = columns }
+
+func (f *fakeOutputFormatter) Template() string { return f.template }
+
+func (f *fakeOutputFormatter) SetTemplate(text string) error {
+	f.format, f.template = "template", text
+	return nil
+}
+
+func Test_outputCommand(t *testing.T) {
+	cases := map[string]struct {
+		format   string
+		columns  []string
+		args     []string
+		expected string
+		out      string
+		hasError bool
+	}{
+		"show":              {format: "json", expected: "json", out: "json\n"},
+		"show columns":      {format: "table", columns: []string{"id", "name"}, expected: "table", out: "table (columns: id, name)\n"},
+		"change":            {format: "json", args: []string{"table"}, expected: "table"},
+		"change (upper)":    {format: "json", args: []string{"TABLE"}, expected: "table"},
+		"change columns":    {format: "json", args: []string{"table", "id"}, expected: "table"},
+		"show template":     {format: "template", expected: "template", out: "template ({{.id}})\n"},
+		"change template":   {format: "json", args: []string{"template", "{{.id}}", "{{.name}}"}, expected: "template"},
+		"columns with json": {format: "table", args: []string{"json", "id"}, expected: "table", hasError: true},
+		"unknown":           {format: "json", args: []string{"xml"}, expected: "json", hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			formatter := &fakeOutputFormatter{format: c.format, columns: c.columns, template: "{{.id}}"}
+			cmd := &outputCommand{formatter}
+
+			err := cmd.Validate(c.args)
+			var r io.Reader
+			if err == nil {
+				r, err = cmd.Run(c.args)
+			}
+			require.Equal(t, c.expected, formatter.format)
+			if c.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if len(c.args) > 1 && c.expected == "table" {
+				require.Equal(t, c.args[1:], formatter.columns)
+			}
+			if len(c.args) > 1 && c.expected == "template" {
+				require.Equal(t, strings.Join(c.args[1:], " "), formatter.template)
+			}
+			b, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, c.out, string(b))
+		})
+	}
+}
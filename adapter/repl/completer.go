@@ -30,6 +30,21 @@ func (c *completer) complete(d prompt.Document) []prompt.Suggest {
 				{Text: "message"},
 				{Text: "rpc"},
 				{Text: "header"},
+				{Text: "variable"},
+			}
+		}
+
+	case "output":
+		if len(args) == 2 {
+			s = []prompt.Suggest{
+				{Text: "json", Description: "JSON format"},
+				{Text: "yaml", Description: "YAML format"},
+				{Text: "table", Description: "repeated message fields as tables"},
+				{Text: "template", Description: "Go template"},
+				{Text: "text", Description: "protobuf text format"},
+				{Text: "binary", Description: "raw protobuf wire format"},
+				{Text: "hex", Description: "protobuf wire format encoded in hex"},
+				{Text: "base64", Description: "protobuf wire format encoded in base64"},
 			}
 		}
 
@@ -50,7 +65,33 @@ func (c *completer) complete(d prompt.Document) []prompt.Suggest {
 			s[i] = prompt.Suggest{Text: svc.Name()}
 		}
 
-	case "call":
+	case "call", "bench":
+		if strings.HasPrefix(d.GetWordBeforeCursor(), "-") {
+			s = []prompt.Suggest{
+				{Text: "--timeout", Description: "the timeout of the RPC call"},
+			}
+			if args[0] == "bench" {
+				s = append(s, []prompt.Suggest{
+					{Text: "--concurrency", Description: "the number of workers"},
+					{Text: "--count", Description: "the number of RPC calls"},
+					{Text: "--duration", Description: "the duration of the load test"},
+					{Text: "--qps", Description: "the rate limit of RPC calls per second"},
+				}...)
+			} else {
+				s = append(s, []prompt.Suggest{
+					{Text: "--filter", Description: "a jq-style expression which extracts values from each response"},
+					{Text: "--data", Description: "the request in JSON"},
+					{Text: "--file", Description: "the file which has the request in JSON"},
+					{Text: "--edit", Description: "edit the request in JSON with $EDITOR"},
+					{Text: "--reset", Description: "forget the remembered input values of the RPC"},
+					{Text: "--max-messages", Description: "stop receiving streamed messages after n messages"},
+					{Text: "--duration", Description: "stop receiving streamed messages after the duration"},
+					{Text: "--timestamp", Description: "show the receive time of each streamed message"},
+					{Text: "--summary", Description: "show the summary of the stream"},
+				}...)
+			}
+			break
+		}
 		rpcs, err := c.env.RPCs()
 		if err != nil {
 			return nil
@@ -60,7 +101,13 @@ func (c *completer) complete(d prompt.Document) []prompt.Suggest {
 			s[i] = prompt.Suggest{Text: rpc.Name()}
 		}
 
-	case "desc":
+	case "desc", "template":
+		if args[0] == "desc" && strings.HasPrefix(d.GetWordBeforeCursor(), "-") {
+			s = []prompt.Suggest{
+				{Text: "--template", Description: "show an example JSON document of the message"},
+			}
+			break
+		}
 		msgs, err := c.env.Messages()
 		if err != nil {
 			return nil
@@ -1,6 +1,7 @@
 package repl
 
 import (
+	"io"
 	"strings"
 	"time"
 )
@@ -35,7 +36,11 @@ func (e *executor) execute(l string) {
 		return
 	}
 
-	e.repl.ui.Println(result)
+	// result may return an error while reading it (e.g. server streaming RPCs).
+	if _, err := io.Copy(e.repl.ui.Writer(), result); err != nil {
+		e.repl.ui.ErrPrintln(err.Error())
+		return
+	}
 	e.repl.prompt.SetPrefix(e.repl.getPrompt())
 
 	e.history = append(e.history, l)
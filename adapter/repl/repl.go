@@ -14,6 +14,7 @@ import (
 	goprompt "github.com/c-bata/go-prompt"
 	"github.com/ktr0731/evans/adapter/cui"
 	"github.com/ktr0731/evans/adapter/prompt"
+	"github.com/ktr0731/evans/adapter/session"
 	"github.com/ktr0731/evans/cache"
 	"github.com/ktr0731/evans/config"
 	"github.com/ktr0731/evans/di"
@@ -39,7 +40,10 @@ var (
 	DefaultReader io.Reader = os.Stdin
 )
 
-func Run(cfg *config.Config, ui cui.UI) error {
+// Run launches REPL mode.
+// If record isn't empty, each RPC call in the session is recorded to the file.
+// The recorded session can be replayed by CLI mode.
+func Run(cfg *config.Config, ui cui.UI, record string) error {
 	if cfg.REPL.ColoredOutput {
 		ui = cui.NewColored(ui)
 	}
@@ -54,6 +58,15 @@ func Run(cfg *config.Config, ui cui.UI) error {
 	defer closeCancel()
 	defer p.Cleanup(closeCtx)
 
+	if record != "" {
+		f, err := os.Create(record)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the session file")
+		}
+		defer f.Close()
+		p.Recorder = session.NewRecorder(f)
+	}
+
 	interactor := usecase.NewInteractor(p)
 
 	env, err := di.Env(cfg)
@@ -61,7 +74,12 @@ func Run(cfg *config.Config, ui cui.UI) error {
 		return err
 	}
 
-	r := newEnv(cfg.REPL, cfg.Server, env, ui, interactor)
+	presenter, err := di.Presenter(cfg)
+	if err != nil {
+		return err
+	}
+
+	r := newEnv(cfg.REPL, cfg.Server, cfg.Request, env, ui, interactor, presenter)
 	if err := r.start(); err != nil {
 		return err
 	}
@@ -82,14 +100,18 @@ type repl struct {
 	exitCh chan struct{}
 }
 
-func newEnv(config *config.REPL, serverConfig *config.Server, env env.Environment, ui cui.UI, inputPort port.InputPort) *repl {
+func newEnv(config *config.REPL, serverConfig *config.Server, requestConfig *config.Request, env env.Environment, ui cui.UI, inputPort port.InputPort, formatter outputFormatter) *repl {
 	cmds := map[string]commander{
-		"call":    &callCommand{inputPort},
-		"desc":    &descCommand{inputPort},
-		"package": &packageCommand{inputPort},
-		"service": &serviceCommand{inputPort},
-		"show":    &showCommand{inputPort},
-		"header":  &headerCommand{inputPort},
+		"call":     &callCommand{inputPort: inputPort, timeout: requestConfig.Timeout, env: env},
+		"bench":    &benchCommand{inputPort: inputPort, timeout: requestConfig.Timeout},
+		"desc":     &descCommand{inputPort},
+		"template": &templateCommand{inputPort},
+		"package":  &packageCommand{inputPort},
+		"service":  &serviceCommand{inputPort},
+		"show":     &showCommand{inputPort},
+		"header":   &headerCommand{inputPort},
+		"save":     &saveCommand{inputPort},
+		"output":   &outputCommand{formatter},
 	}
 
 	repl := &repl{
@@ -127,6 +149,13 @@ func newEnv(config *config.REPL, serverConfig *config.Server, env env.Environmen
 }
 
 func (r *repl) eval(l string) (io.Reader, error) {
+	// only call command accepts a filter after a pipe.
+	// pipes in other commands are passed as it is.
+	var filter string
+	if fields := strings.Fields(l); len(fields) != 0 && fields[0] == "call" {
+		l, filter = splitFilter(l)
+	}
+
 	// trim quote
 	// e.g. key='foo' is interpreted to `foo`
 	//      key='foo bar' is `foo bar`
@@ -136,6 +165,9 @@ func (r *repl) eval(l string) (io.Reader, error) {
 	if err != nil {
 		return nil, err
 	}
+	if filter != "" {
+		part = append(part, "--filter", filter)
+	}
 
 	if part[0] == "help" {
 		return strings.NewReader(r.help(r.cmds) + "\n"), nil
@@ -160,6 +192,26 @@ func (r *repl) eval(l string) (io.Reader, error) {
 	return cmd.Run(args)
 }
 
+// splitFilter splits l at the first pipe which is not quoted.
+// The command line and the filter expression after the pipe are returned.
+// If l has no pipe, the filter is empty.
+func splitFilter(l string) (string, string) {
+	var quote rune
+	for i, c := range l {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '|':
+			return strings.TrimSpace(l[:i]), strings.TrimSpace(l[i+1:])
+		}
+	}
+	return l, ""
+}
+
 func (r *repl) start() error {
 	defer r.cleanup()
 	if r.config.ShowSplashText {
@@ -7,8 +7,11 @@ import (
 
 	"github.com/ktr0731/evans/cache"
 	"github.com/ktr0731/evans/config"
+	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/tests/helper"
+	"github.com/ktr0731/evans/usecase/port"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var cacheDir string
@@ -75,3 +78,40 @@ func Test_repl_cleanup(t *testing.T) {
 		})
 	}
 }
+
+func Test_splitFilter(t *testing.T) {
+	cases := map[string]struct {
+		in           string
+		line, filter string
+	}{
+		"no filter":      {in: "call SayHello", line: "call SayHello"},
+		"filter":         {in: "call SayHello | .items[0]", line: "call SayHello", filter: ".items[0]"},
+		"pipe in filter": {in: "call SayHello|.items | .[0]", line: "call SayHello", filter: ".items | .[0]"},
+		"quoted pipe":    {in: `header foo='a|b' | .x`, line: `header foo='a|b'`, filter: ".x"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			line, filter := splitFilter(c.in)
+			assert.Equal(t, c.line, line)
+			assert.Equal(t, c.filter, filter)
+		})
+	}
+}
+
+func Test_repl_eval_filter(t *testing.T) {
+	inputPort := &callInputPort{}
+	r := &repl{cmds: map[string]commander{
+		"call":   &callCommand{inputPort: inputPort},
+		"header": &headerCommand{inputPort},
+	}}
+
+	_, err := r.eval(`call SayHello | .meta["a b"]`)
+	require.NoError(t, err)
+	assert.Equal(t, &port.CallParams{RPCName: "SayHello", Filter: `.meta["a b"]`}, inputPort.received)
+
+	// pipes are not regarded as filters in other commands.
+	_, err = r.eval("header foo=a|b")
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Header{{Key: "foo", Val: "a|b"}}, inputPort.headers)
+}
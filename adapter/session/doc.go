@@ -0,0 +1,2 @@
+// Package session implements reading and writing recorded sessions.
+package session
@@ -0,0 +1,47 @@
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+// Recorder writes each recorded call to a writer as a line of JSON.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder returns a new Recorder which writes a session to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+func (r *Recorder) Record(call *port.RecordedCall) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(call); err != nil {
+		return errors.Wrap(err, "failed to write the recorded call")
+	}
+	return nil
+}
+
+// Read reads all recorded calls from a session which is written by Recorder.
+func Read(r io.Reader) ([]*port.RecordedCall, error) {
+	dec := json.NewDecoder(r)
+	var calls []*port.RecordedCall
+	for {
+		var call port.RecordedCall
+		err := dec.Decode(&call)
+		if err == io.EOF {
+			return calls, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the call #%d", len(calls)+1)
+		}
+		calls = append(calls, &call)
+	}
+}
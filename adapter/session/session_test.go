@@ -0,0 +1,49 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	calls := []*port.RecordedCall{
+		{
+			Package:   "api",
+			Service:   "Example",
+			RPC:       "Unary",
+			Headers:   map[string]string{"foo": "bar"},
+			Requests:  []json.RawMessage{json.RawMessage(`{"name":"maho"}`)},
+			Responses: []json.RawMessage{json.RawMessage(`{"message":"hello, maho"}`)},
+		},
+		{
+			Package:   "api",
+			Service:   "Example",
+			RPC:       "Unary",
+			Requests:  []json.RawMessage{json.RawMessage(`{}`)},
+			Responses: []json.RawMessage{},
+			Status:    &port.RecordedStatus{Code: "InvalidArgument", Message: "name is required"},
+		},
+	}
+
+	var buf bytes.Buffer
+	r := NewRecorder(&buf)
+	for _, c := range calls {
+		require.NoError(t, r.Record(c))
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"), "each call must be written as a line")
+
+	actual, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, calls, actual)
+}
+
+func TestRead(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"rpc":"Unary"}` + "\n" + `{`))
+	assert.Error(t, err)
+}
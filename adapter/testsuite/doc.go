@@ -0,0 +1,3 @@
+// Package testsuite implements reading test suite files and scripts for bidirectional streaming RPCs,
+// and writing test reports.
+package testsuite
@@ -0,0 +1,80 @@
+package testsuite
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitReporter writes a test report as JUnit XML.
+type JUnitReporter struct {
+	w io.Writer
+}
+
+// NewJUnitReporter returns a new JUnitReporter which writes reports to w.
+func NewJUnitReporter(w io.Writer) *JUnitReporter {
+	return &JUnitReporter{w: w}
+}
+
+func (r *JUnitReporter) Report(report *port.TestReport) error {
+	suite := junitTestSuite{
+		Name:     report.Name,
+		Tests:    len(report.Results),
+		Failures: report.Failed,
+		Time:     fmt.Sprintf("%.3f", report.Elapsed.Seconds()),
+	}
+	for _, res := range report.Results {
+		c := junitTestCase{
+			Name:      res.Case.Name,
+			ClassName: fmt.Sprintf("%s.%s.%s", res.Case.Package, res.Case.Service, res.Case.RPC),
+			Time:      fmt.Sprintf("%.3f", res.Elapsed.Seconds()),
+		}
+		if !res.Passed() {
+			c.Failure = &junitFailure{
+				Message:  res.Failures[0],
+				Contents: strings.Join(res.Failures, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(r.w, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write JUnit XML")
+	}
+	enc := xml.NewEncoder(r.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return errors.Wrap(err, "failed to write JUnit XML")
+	}
+	_, err := io.WriteString(r.w, "\n")
+	return err
+}
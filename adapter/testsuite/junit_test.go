@@ -0,0 +1,39 @@
+package testsuite
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	tc := &port.TestCase{Name: "say hello", Package: "api", Service: "Example", RPC: "Unary"}
+	report := &port.TestReport{
+		Name:    "contract",
+		Passed:  1,
+		Failed:  1,
+		Elapsed: 1500 * time.Millisecond,
+		Results: []*port.TestResult{
+			{Case: tc, Elapsed: time.Second},
+			{Case: tc, Elapsed: 500 * time.Millisecond, Failures: []string{"foo", "bar"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewJUnitReporter(&buf).Report(report))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="contract" tests="2" failures="1" time="1.500">
+    <testcase name="say hello" classname="api.Example.Unary" time="1.000"></testcase>
+    <testcase name="say hello" classname="api.Example.Unary" time="0.500">
+      <failure message="foo">foo&#xA;bar</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buf.String())
+}
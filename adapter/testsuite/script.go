@@ -0,0 +1,101 @@
+package testsuite
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type scriptFile struct {
+	Steps []*stepFile `yaml:"steps" toml:"steps"`
+}
+
+type stepFile struct {
+	Send    interface{} `yaml:"send" toml:"send"`
+	Sleep   string      `yaml:"sleep" toml:"sleep"`
+	Receive int         `yaml:"receive" toml:"receive"`
+	Match   *fieldFile  `yaml:"match" toml:"match"`
+}
+
+// ReadScript reads a script for bidirectional streaming RPCs from a YAML or TOML file.
+// The format is determined by the extension of the file.
+func ReadScript(fname string) (*port.BidiScript, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	var f scriptFile
+	switch ext := filepath.Ext(fname); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &f)
+	case ".toml":
+		err = toml.Unmarshal(b, &f)
+	default:
+		return nil, errors.Errorf("unsupported script format: %s", ext)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the script")
+	}
+
+	script := &port.BidiScript{}
+	for i, s := range f.Steps {
+		step, err := s.toBidiStep()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid step #%d", i+1)
+		}
+		script.Steps = append(script.Steps, step)
+	}
+	return script, nil
+}
+
+func (s *stepFile) toBidiStep() (*port.BidiStep, error) {
+	var (
+		step = &port.BidiStep{}
+		n    int
+		err  error
+	)
+	if s.Send != nil {
+		n++
+		if step.Send, err = toJSON(s.Send); err != nil {
+			return nil, errors.Wrap(err, "invalid request")
+		}
+	}
+	if s.Sleep != "" {
+		n++
+		if step.Sleep, err = time.ParseDuration(s.Sleep); err != nil {
+			return nil, errors.Wrap(err, "invalid sleep")
+		}
+		if step.Sleep <= 0 {
+			return nil, errors.New("sleep must be positive")
+		}
+	}
+	if s.Receive != 0 {
+		n++
+		if s.Receive < 0 {
+			return nil, errors.New("receive must be positive")
+		}
+		step.Receive = s.Receive
+	}
+	if s.Match != nil {
+		n++
+		if s.Match.Equals == nil && s.Match.Regex == "" {
+			return nil, errors.Errorf("match for '%s' must have either equals or regex", s.Match.Path)
+		}
+		step.Match = &port.FieldMatcher{Path: s.Match.Path, Regex: s.Match.Regex}
+		if s.Match.Equals != nil {
+			if step.Match.Equals, err = toJSON(s.Match.Equals); err != nil {
+				return nil, errors.Wrapf(err, "invalid expected value for '%s'", s.Match.Path)
+			}
+		}
+	}
+	if n != 1 {
+		return nil, errors.New("a step must have exactly one of send, sleep, receive and match")
+	}
+	return step, nil
+}
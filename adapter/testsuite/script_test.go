@@ -0,0 +1,56 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadScript(t *testing.T) {
+	expected := &port.BidiScript{
+		Steps: []*port.BidiStep{
+			{Send: json.RawMessage(`{"name":"maho"}`)},
+			{Receive: 1},
+			{Sleep: 500 * time.Millisecond},
+			{Send: json.RawMessage(`{"name": "kurisu"}`)},
+			{Match: &port.FieldMatcher{Path: "message", Regex: "kurisu$"}},
+		},
+	}
+
+	for _, fname := range []string{"script.yaml", "script.toml"} {
+		t.Run(fname, func(t *testing.T) {
+			script, err := ReadScript(filepath.Join("testdata", fname))
+			require.NoError(t, err)
+			assert.Equal(t, expected, script)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		cases := map[string]string{
+			"script.json":   `{}`,
+			"empty.yaml":    "steps:\n  - {}\n",
+			"multiple.yaml": "steps:\n  - send: {name: maho}\n    receive: 1\n",
+			"sleep.yaml":    "steps:\n  - sleep: foo\n",
+			"match.yaml":    "steps:\n  - match:\n      path: message\n",
+		}
+		for fname, content := range cases {
+			t.Run(fname, func(t *testing.T) {
+				p := filepath.Join(dir, fname)
+				require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+				_, err := ReadScript(p)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type suiteFile struct {
+	Name  string      `yaml:"name" toml:"name"`
+	Tests []*caseFile `yaml:"tests" toml:"tests"`
+}
+
+type caseFile struct {
+	Name string `yaml:"name" toml:"name"`
+	// RPC is the fully-qualified RPC name such as "api.Example.Unary".
+	RPC      string            `yaml:"rpc" toml:"rpc"`
+	Headers  map[string]string `yaml:"headers" toml:"headers"`
+	Timeout  string            `yaml:"timeout" toml:"timeout"`
+	Request  interface{}       `yaml:"request" toml:"request"`
+	Requests []interface{}     `yaml:"requests" toml:"requests"`
+	Expect   *expectFile       `yaml:"expect" toml:"expect"`
+}
+
+type expectFile struct {
+	Code      string        `yaml:"code" toml:"code"`
+	Message   string        `yaml:"message" toml:"message"`
+	Response  interface{}   `yaml:"response" toml:"response"`
+	Responses []interface{} `yaml:"responses" toml:"responses"`
+	Fields    []*fieldFile  `yaml:"fields" toml:"fields"`
+}
+
+type fieldFile struct {
+	Index  int         `yaml:"index" toml:"index"`
+	Path   string      `yaml:"path" toml:"path"`
+	Equals interface{} `yaml:"equals" toml:"equals"`
+	Regex  string      `yaml:"regex" toml:"regex"`
+}
+
+// Read reads a test suite from a YAML or TOML file.
+// The format is determined by the extension of the file.
+func Read(fname string) (*port.TestSuite, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	var f suiteFile
+	switch ext := filepath.Ext(fname); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &f)
+	case ".toml":
+		err = toml.Unmarshal(b, &f)
+	default:
+		return nil, errors.Errorf("unsupported test suite format: %s", ext)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the test suite")
+	}
+
+	suite := &port.TestSuite{Name: f.Name}
+	for i, c := range f.Tests {
+		tc, err := c.toTestCase()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid test case #%d (%s)", i+1, c.Name)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return suite, nil
+}
+
+func (c *caseFile) toTestCase() (*port.TestCase, error) {
+	pkg, svc, rpc, err := splitFQRN(c.RPC)
+	if err != nil {
+		return nil, err
+	}
+	tc := &port.TestCase{
+		Name:    c.Name,
+		Package: pkg,
+		Service: svc,
+		RPC:     rpc,
+		Headers: c.Headers,
+		Expect:  &port.TestExpectation{},
+	}
+	if tc.Name == "" {
+		tc.Name = c.RPC
+	}
+
+	if c.Timeout != "" {
+		tc.Timeout, err = time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid timeout")
+		}
+	}
+
+	if c.Request != nil && len(c.Requests) != 0 {
+		return nil, errors.New("request and requests cannot be used together")
+	}
+	reqs := c.Requests
+	if c.Request != nil {
+		reqs = []interface{}{c.Request}
+	}
+	if tc.Requests, err = toJSONList(reqs); err != nil {
+		return nil, errors.Wrap(err, "invalid request")
+	}
+
+	if c.Expect == nil {
+		return tc, nil
+	}
+	e := c.Expect
+	tc.Expect.Code = e.Code
+	tc.Expect.Message = e.Message
+	if e.Response != nil && len(e.Responses) != 0 {
+		return nil, errors.New("response and responses cannot be used together")
+	}
+	resps := e.Responses
+	if e.Response != nil {
+		resps = []interface{}{e.Response}
+	}
+	if tc.Expect.Responses, err = toJSONList(resps); err != nil {
+		return nil, errors.Wrap(err, "invalid expected response")
+	}
+	for _, f := range e.Fields {
+		if f.Equals == nil && f.Regex == "" {
+			return nil, errors.Errorf("field matcher for '%s' must have either equals or regex", f.Path)
+		}
+		m := &port.FieldMatcher{Index: f.Index, Path: f.Path, Regex: f.Regex}
+		if f.Equals != nil {
+			if m.Equals, err = toJSON(f.Equals); err != nil {
+				return nil, errors.Wrapf(err, "invalid expected value for '%s'", f.Path)
+			}
+		}
+		tc.Expect.Fields = append(tc.Expect.Fields, m)
+	}
+	return tc, nil
+}
+
+// splitFQRN splits a fully-qualified RPC name to the package, the service and the RPC.
+// The package name may contain dots.
+func splitFQRN(fqrn string) (pkg, svc, rpc string, err error) {
+	i := strings.LastIndex(fqrn, ".")
+	if i == -1 {
+		return "", "", "", errors.Errorf("rpc must be a fully-qualified name such as package.Service.RPC: '%s'", fqrn)
+	}
+	rpc = fqrn[i+1:]
+	j := strings.LastIndex(fqrn[:i], ".")
+	if j == -1 {
+		return "", "", "", errors.Errorf("rpc must be a fully-qualified name such as package.Service.RPC: '%s'", fqrn)
+	}
+	return fqrn[:j], fqrn[j+1 : i], rpc, nil
+}
+
+func toJSONList(vs []interface{}) ([]json.RawMessage, error) {
+	l := make([]json.RawMessage, 0, len(vs))
+	for _, v := range vs {
+		b, err := toJSON(v)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, b)
+	}
+	return l, nil
+}
+
+// toJSON encodes a value which is decoded from YAML or TOML.
+// A string is regarded as JSON text if it is a JSON object or array.
+func toJSON(v interface{}) (json.RawMessage, error) {
+	if s, ok := v.(string); ok {
+		t := strings.TrimSpace(s)
+		if (strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")) && json.Valid([]byte(t)) {
+			return json.RawMessage(t), nil
+		}
+	}
+	v, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// normalize converts maps which are decoded by YAML to JSON compatible maps.
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			ks, ok := k.(string)
+			if !ok {
+				ks = fmt.Sprint(k)
+			}
+			n, err := normalize(e)
+			if err != nil {
+				return nil, err
+			}
+			m[ks] = n
+		}
+		return m, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			n, err := normalize(e)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = n
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			n, err := normalize(e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, n)
+		}
+		return l, nil
+	case []map[string]interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			n, err := normalize(e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, n)
+		}
+		return l, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,78 @@
+package testsuite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	expected := &port.TestSuite{
+		Name: "contract",
+		Cases: []*port.TestCase{
+			{
+				Name:     "say hello",
+				Package:  "api.v1",
+				Service:  "Example",
+				RPC:      "Unary",
+				Headers:  map[string]string{"foo": "bar"},
+				Requests: []json.RawMessage{json.RawMessage(`{"name":"maho"}`)},
+				Timeout:  2 * time.Second,
+				Expect: &port.TestExpectation{
+					Responses: []json.RawMessage{json.RawMessage(`{"message":"hello, maho"}`)},
+					Fields: []*port.FieldMatcher{
+						{Path: "message", Regex: "^hello"},
+						{Path: "message", Equals: json.RawMessage(`"hello, maho"`)},
+					},
+				},
+			},
+			{
+				Name:     "api.v1.Example.ClientStreaming",
+				Package:  "api.v1",
+				Service:  "Example",
+				RPC:      "ClientStreaming",
+				Requests: []json.RawMessage{json.RawMessage(`{"name": "maho"}`), json.RawMessage(`{"name":"kurisu"}`)},
+				Expect: &port.TestExpectation{
+					Code:      "InvalidArgument",
+					Message:   "too many names",
+					Responses: []json.RawMessage{},
+				},
+			},
+		},
+	}
+
+	for _, fname := range []string{"suite.yaml", "suite.toml"} {
+		t.Run(fname, func(t *testing.T) {
+			suite, err := Read(filepath.Join("testdata", fname))
+			require.NoError(t, err)
+			assert.Equal(t, expected, suite)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		cases := map[string]string{
+			"suite.json": `{}`,
+			"rpc.yaml":   "tests:\n  - rpc: Unary\n",
+			"field.yaml": "tests:\n  - rpc: api.Example.Unary\n    expect:\n      fields:\n        - path: message\n",
+		}
+		for fname, content := range cases {
+			t.Run(fname, func(t *testing.T) {
+				p := filepath.Join(dir, fname)
+				require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+				_, err := Read(p)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -28,6 +28,14 @@ type Cache struct {
 	UpdateInfo     UpdateInfo `toml:"updateInfo"`
 	InstalledBy    MeansType  `default:"" toml:"installedBy"`
 	CommandHistory []string   `default:"" toml:"commandHistory"`
+
+	// LastEditedRequests holds the last edited request per RPC.
+	// Its keys are fully-qualified RPC names.
+	LastEditedRequests map[string]string `toml:"lastEditedRequests"`
+
+	// LastFieldValues holds the last input values of fields per RPC.
+	// Its keys are fully-qualified RPC names, and keys of each value are field paths like "foo::bar".
+	LastFieldValues map[string]map[string]string `toml:"lastFieldValues"`
 }
 
 // Save writes the receiver to the cache file.
@@ -128,6 +136,45 @@ func (c *Cache) SetCommandHistory(h []string) *Cache {
 	return c
 }
 
+// SetLastEditedRequest sets the last edited request of the RPC.
+func (c *Cache) SetLastEditedRequest(rpcName, req string) *Cache {
+	if c.LastEditedRequests == nil {
+		c.LastEditedRequests = map[string]string{}
+	}
+	c.LastEditedRequests[rpcName] = req
+	return c
+}
+
+// SetLastFieldValues merges values into the last input values of fields of the RPC.
+// Fields which have empty values are removed.
+func (c *Cache) SetLastFieldValues(rpcName string, values map[string]string) *Cache {
+	if c.LastFieldValues == nil {
+		c.LastFieldValues = map[string]map[string]string{}
+	}
+	if c.LastFieldValues[rpcName] == nil {
+		c.LastFieldValues[rpcName] = map[string]string{}
+	}
+	for k, v := range values {
+		if v == "" {
+			delete(c.LastFieldValues[rpcName], k)
+			continue
+		}
+		c.LastFieldValues[rpcName][k] = v
+	}
+	return c
+}
+
+// ClearLastFieldValues removes the last input values of fields of the RPC.
+// If rpcName is empty, values of all RPCs are removed.
+func (c *Cache) ClearLastFieldValues(rpcName string) *Cache {
+	if rpcName == "" {
+		c.LastFieldValues = nil
+		return c
+	}
+	delete(c.LastFieldValues, rpcName)
+	return c
+}
+
 func resolvePath() string {
 	return filepath.Join(xdgbasedir.CacheHome(), meta.AppName, defaultFileName)
 }
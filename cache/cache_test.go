@@ -114,4 +114,29 @@ func TestCache(t *testing.T) {
 		newCache = Get()
 		assert.Empty(t, newCache.UpdateInfo, "UpdateInfo must be cleared by ClearUpdateInfo, but got non-empty values")
 	})
+
+	t.Run("last field values", func(t *testing.T) {
+		defer func() {
+			os.RemoveAll(testDir)
+			cachedCache = nil
+		}()
+
+		cache := Get()
+		cache.SetLastFieldValues("a.A.Foo", map[string]string{"name": "rin", "age": "17"})
+		cache.SetLastFieldValues("a.A.Foo", map[string]string{"name": "nadeshiko"})
+		cache.SetLastFieldValues("a.A.Bar", map[string]string{"id": "1"})
+		require.NoError(t, cache.Save())
+
+		cachedCache = nil
+		cache = Get()
+		assert.Equal(t, map[string]string{"name": "nadeshiko", "age": "17"}, cache.LastFieldValues["a.A.Foo"], "values must be merged")
+
+		cache.SetLastFieldValues("a.A.Foo", map[string]string{"age": ""})
+		assert.Equal(t, map[string]string{"name": "nadeshiko"}, cache.LastFieldValues["a.A.Foo"], "empty values must be removed")
+
+		cache.ClearLastFieldValues("a.A.Foo")
+		assert.Equal(t, map[string]map[string]string{"a.A.Bar": {"id": "1"}}, cache.LastFieldValues)
+		cache.ClearLastFieldValues("")
+		assert.Empty(t, cache.LastFieldValues)
+	})
 }
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/k0kubun/pp"
 	"github.com/ktr0731/evans/logger"
@@ -40,6 +41,20 @@ type Request struct {
 	CACertFile  string `toml:"caCertFile"`
 	CertFile    string `toml:"certFile"`
 	CertKeyFile string `toml:"certKeyFile"`
+
+	// Timeout is the default deadline of each RPC call.
+	// If it is zero, RPC calls don't have any deadline.
+	Timeout time.Duration `toml:"timeout"`
+
+	// Compression is the name of the compressor which compresses request messages.
+	// The compressor must be registered by encoding.RegisterCompressor of gRPC.
+	// If it is empty, request messages are not compressed.
+	Compression string `toml:"compression"`
+
+	// MaxSendMsgSize and MaxRecvMsgSize are the max size of a message in bytes.
+	// If they are zero, the default limits of gRPC are used.
+	MaxSendMsgSize int `toml:"maxSendMsgSize"`
+	MaxRecvMsgSize int `toml:"maxRecvMsgSize"`
 }
 
 type REPL struct {
@@ -52,6 +67,28 @@ type REPL struct {
 	SplashTextPath string `toml:"splashTextPath"`
 
 	HistorySize int `toml:"historySize"`
+
+	// OutputTemplate is a Go template which formats each response.
+	// It is used in both of REPL and CLI mode.
+	OutputTemplate string `toml:"outputTemplate"`
+}
+
+type Output struct {
+	Format          string   `toml:"format"`
+	Columns         []string `toml:"columns"`
+	ShowMetadata    bool     `toml:"showMetadata"`
+	ShowElapsedTime bool     `toml:"showElapsedTime"`
+
+	// JSON mapping options of response messages.
+	OrigName      bool `toml:"origName"`
+	EnumsAsInts   bool `toml:"enumsAsInts"`
+	EmitDefaults  bool `toml:"emitDefaults"`
+	Int64AsString bool `toml:"int64AsString"`
+
+	// Bytes is the format of bytes fields. One of base64, hex (a hex dump) or text.
+	Bytes string `toml:"bytes"`
+	// BytesMaxLength truncates bytes fields which are longer than it. 0 means no limit.
+	BytesMaxLength int `toml:"bytesMaxLength"`
 }
 
 type Meta struct {
@@ -69,6 +106,7 @@ type Config struct {
 	Server  *Server  `toml:"server"`
 	Log     *Log     `toml:"log"`
 	Request *Request `toml:"request"`
+	Output  *Output  `toml:"output"`
 }
 
 type Default struct {
@@ -116,6 +154,7 @@ func newDefaultViper() *viper.Viper {
 	v.SetDefault("repl.showSplashText", true)
 	v.SetDefault("repl.splashTextPath", "")
 	v.SetDefault("repl.historySize", 100)
+	v.SetDefault("repl.outputTemplate", "")
 
 	v.SetDefault("server.host", "127.0.0.1")
 	v.SetDefault("server.port", "50051")
@@ -130,6 +169,21 @@ func newDefaultViper() *viper.Viper {
 	v.SetDefault("request.certFile", "")
 	v.SetDefault("request.certKeyFile", "")
 	v.SetDefault("request.web", false)
+	v.SetDefault("request.timeout", "0s")
+	v.SetDefault("request.compression", "")
+	v.SetDefault("request.maxSendMsgSize", 0)
+	v.SetDefault("request.maxRecvMsgSize", 0)
+
+	v.SetDefault("output.format", "json")
+	v.SetDefault("output.columns", []string{})
+	v.SetDefault("output.showMetadata", false)
+	v.SetDefault("output.showElapsedTime", false)
+	v.SetDefault("output.origName", false)
+	v.SetDefault("output.enumsAsInts", false)
+	v.SetDefault("output.emitDefaults", true)
+	v.SetDefault("output.int64AsString", false)
+	v.SetDefault("output.bytes", "base64")
+	v.SetDefault("output.bytesMaxLength", 0)
 
 	return v
 }
@@ -138,20 +192,29 @@ func newDefaultViper() *viper.Viper {
 func bindFlags(vp *viper.Viper, fs *pflag.FlagSet) {
 	// kv defines the mapping from a viper config name to a flag name.
 	kv := map[string]string{
-		"default.protoPath":   "path",
-		"default.package":     "package",
-		"default.service":     "service",
-		"server.host":         "host",
-		"server.port":         "port",
-		"server.reflection":   "reflection",
-		"server.tls":          "tls",
-		"server.name":         "servername",
-		"request.header":      "header",
-		"request.web":         "web",
-		"request.cacertFile":  "cacert",
-		"request.certFile":    "cert",
-		"request.certKeyFile": "certkey",
-		"repl.showSplashText": "silent",
+		"default.protoPath":      "path",
+		"default.package":        "package",
+		"default.service":        "service",
+		"server.host":            "host",
+		"server.port":            "port",
+		"server.reflection":      "reflection",
+		"server.tls":             "tls",
+		"server.name":            "servername",
+		"request.header":         "header",
+		"request.web":            "web",
+		"request.cacertFile":     "cacert",
+		"request.certFile":       "cert",
+		"request.certKeyFile":    "certkey",
+		"request.timeout":        "timeout",
+		"request.compression":    "compression",
+		"request.maxSendMsgSize": "max-send-msg-size",
+		"request.maxRecvMsgSize": "max-recv-msg-size",
+		"repl.showSplashText":    "silent",
+		"repl.outputTemplate":    "output-template",
+		"output.format":          "output",
+		"output.columns":         "columns",
+		"output.showMetadata":    "show-metadata",
+		"output.showElapsedTime": "show-elapsed-time",
 	}
 	for k, v := range kv {
 		f := fs.Lookup(v)
@@ -356,7 +419,7 @@ func Edit() error {
 			return err
 		}
 	}
-	editor := getEditor()
+	editor := Editor()
 	if editor == "" {
 		return errors.New("--edit requires one of $EDITOR value or Vim")
 	}
@@ -402,7 +465,10 @@ func lookupProjectRootPath() (string, bool) {
 	return p, true
 }
 
-func getEditor() string {
+// Editor returns the editor which is used to edit files.
+// $EDITOR is used if it is configured. Else, Vim is used.
+// If both of them are not found, Editor returns an empty string.
+func Editor() string {
 	if env := os.Getenv("EDITOR"); env != "" {
 		return env
 	}
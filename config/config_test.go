@@ -232,11 +232,14 @@ func TestLoad(t *testing.T) {
 		fs.String("port", "", "")
 		fs.StringToString("header", nil, "")
 		fs.StringSlice("path", nil, "")
+		fs.Duration("timeout", 0, "")
 		// --port flag changes port number to '8080'. Also --header appends 'foo=bar' and 'hoge=fuga' to 'request.header'.
+		// --timeout flag changes timeout to '2s'.
 		fs.Parse([]string{
 			"--port", "8080",
 			"--path", "yoko.touma",
 			"--header", "foo=bar", "--header", "hoge=fuga",
+			"--timeout", "2s",
 		})
 
 		cfg, err := Get(fs)
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/golang/protobuf/jsonpb"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/ktr0731/evans/adapter/grpc"
 	"github.com/ktr0731/evans/adapter/inputter"
@@ -24,98 +25,99 @@ import (
 var (
 	env     environment.Environment
 	envOnce sync.Once
+	// envErr is the error of the initialization. It is returned by every call of initEnv.
+	envErr error
 )
 
-func initEnv(cfg *config.Config) (rerr error) {
+func initEnv(cfg *config.Config) error {
 	envOnce.Do(func() {
-		paths, err := resolveProtoPaths(cfg)
-		if err != nil {
-			rerr = errors.Wrap(err, "failed to resolve proto paths")
-			return
-		}
+		envErr = newEnv(cfg)
+	})
+	return envErr
+}
 
-		files := resolveProtoFiles(cfg)
-		desc, err := protobuf.ParseFile(files, paths)
-		if err != nil {
-			rerr = errors.Wrap(err, "failed to parse proto files")
-			return
-		}
+func newEnv(cfg *config.Config) error {
+	paths, err := resolveProtoPaths(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve proto paths")
+	}
 
-		gRPCClient, err := GRPCClient(cfg)
-		if err != nil {
-			rerr = err
-			return
-		}
+	files := resolveProtoFiles(cfg)
+	desc, err := protobuf.ParseFile(files, paths)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse proto files")
+	}
 
-		headers := make([]entity.Header, 0, len(cfg.Request.Header))
-		for k, v := range cfg.Request.Header {
-			if len(v) == 0 {
-				continue
-			}
-			// TODO: support multiple values
-			if len(v) > 1 {
-				logger.Println("currently, Evans doesn't support multiple header values corresponding to a key")
-			}
-			headers = append(headers, entity.Header{Key: k, Val: v[0]})
+	gRPCClient, err := GRPCClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]entity.Header, 0, len(cfg.Request.Header))
+	for k, v := range cfg.Request.Header {
+		if len(v) == 0 {
+			continue
 		}
-		if gRPCClient.ReflectionEnabled() {
-			desc, err = gRPCClient.ListPackages()
-			if err != nil {
-				rerr = errors.Wrap(err, "failed to list packages by gRPC reflection")
-				return
-			}
+		// TODO: support multiple values
+		if len(v) > 1 {
+			logger.Println("currently, Evans doesn't support multiple header values corresponding to a key")
 		}
-		env = environment.New(desc, headers)
-
-		// If a package is specified, Evans sets it as default.
-		// The priority is as follows:
-		//
-		// 1. If cfg.Default.Package (command-line flag) isn't empty,
-		//    use it as default package.
-		// 2. If the number of loaded packages is only one, use it.
-		// 3. If cfg.Default.Service isn't empty, Evans tries to interpret
-		//    as a string concating a package and service.
-		//    Evans splits it to two strings with ".", and the first
-		//    string is regarded as the package name.
-		pkg := cfg.Default.Package
-		svc := cfg.Default.Service
-		if pkg == "" && len(env.Packages()) == 1 {
-			pkg = env.Packages()[0].Name
+		headers = append(headers, entity.Header{Key: k, Val: v[0]})
+	}
+	if gRPCClient.ReflectionEnabled() {
+		desc, err = gRPCClient.ListPackages()
+		if err != nil {
+			return errors.Wrap(err, "failed to list packages by gRPC reflection")
 		}
-		if pkg == "" && svc != "" {
-			i := strings.LastIndex(svc, ".")
-			if i != -1 {
-				pkg = svc[:i]
-				svc = svc[i+1:]
-			}
+	}
+	env = environment.New(desc, headers)
+
+	// If a package is specified, Evans sets it as default.
+	// The priority is as follows:
+	//
+	// 1. If cfg.Default.Package (command-line flag) isn't empty,
+	//    use it as default package.
+	// 2. If the number of loaded packages is only one, use it.
+	// 3. If cfg.Default.Service isn't empty, Evans tries to interpret
+	//    as a string concating a package and service.
+	//    Evans splits it to two strings with ".", and the first
+	//    string is regarded as the package name.
+	pkg := cfg.Default.Package
+	svc := cfg.Default.Service
+	if pkg == "" && len(env.Packages()) == 1 {
+		pkg = env.Packages()[0].Name
+	}
+	if pkg == "" && svc != "" {
+		i := strings.LastIndex(svc, ".")
+		if i != -1 {
+			pkg = svc[:i]
+			svc = svc[i+1:]
 		}
+	}
 
-		if pkg != "" {
-			if err := env.UsePackage(pkg); err != nil {
-				rerr = errors.Wrapf(err, "failed to set package to env as a default package: %s", pkg)
-				return
-			}
+	if pkg != "" {
+		if err := env.UsePackage(pkg); err != nil {
+			return errors.Wrapf(err, "failed to set package to env as a default package: %s", pkg)
 		}
+	}
 
-		if svc == "" {
-			svcs, err := env.Services()
-			if err == nil && len(svcs) == 1 {
-				svc = svcs[0].Name()
-			}
+	if svc == "" {
+		svcs, err := env.Services()
+		if err == nil && len(svcs) == 1 {
+			svc = svcs[0].Name()
 		}
-		if svc != "" {
+	}
+	if svc != "" {
+		if err := env.UseService(svc); err != nil {
+			// If an error is returned, try again with a package
+			// trimmed string.
+			svc = strings.TrimPrefix(svc, pkg+".")
 			if err := env.UseService(svc); err != nil {
-				// If an error is returned, try again with a package
-				// trimmed string.
-				svc = strings.TrimPrefix(svc, pkg+".")
-				if err := env.UseService(svc); err != nil {
-					rerr = errors.Wrapf(err, "failed to set service '%s' to env as a default service", svc)
-					return
-				}
+				return errors.Wrapf(err, "failed to set service '%s' to env as a default service", svc)
 			}
 		}
-	})
-	return
+	}
+	return nil
 }
 
 func Env(cfg *config.Config) (environment.Environment, error) {
@@ -173,41 +175,113 @@ func resolveProtoFiles(conf *config.Config) []string {
 }
 
 var (
-	jsonCLIPresenter     *presenter.JSONPresenter
-	jsonCLIPresenterOnce sync.Once
+	cliPresenter     *presenter.Selector
+	cliPresenterOnce sync.Once
+	cliPresenterErr  error
+
+	// responseMarshaler has the same JSON mapping options as cliPresenter.
+	// It is used to filter responses and keep them as variables.
+	responseMarshaler *jsonpb.Marshaler
 )
 
-func initJSONCLIPresenter() error {
-	jsonCLIPresenterOnce.Do(func() {
-		jsonCLIPresenter = presenter.NewJSONWithIndent()
+func initCLIPresenter(cfg *config.Config) error {
+	cliPresenterOnce.Do(func() {
+		cliPresenterErr = newCLIPresenter(cfg)
 	})
+	return cliPresenterErr
+}
+
+func newCLIPresenter(cfg *config.Config) error {
+	e, err := Env(cfg)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return errors.New("environment is not initialized")
+	}
+	resolver := protobuf.NewAnyResolver(e.Packages())
+	responseMarshaler = &jsonpb.Marshaler{
+		OrigName:     cfg.Output.OrigName,
+		EnumsAsInts:  cfg.Output.EnumsAsInts,
+		EmitDefaults: cfg.Output.EmitDefaults,
+		AnyResolver:  resolver,
+	}
+	opts := []presenter.JSONOption{
+		presenter.WithAnyResolver(resolver),
+	}
+	if cfg.Output.ShowMetadata {
+		opts = append(opts, presenter.WithMetadata())
+	}
+	if cfg.Output.ShowElapsedTime {
+		opts = append(opts, presenter.WithElapsedTime())
+	}
+	if cfg.Output.OrigName {
+		opts = append(opts, presenter.WithOrigName())
+	}
+	if cfg.Output.EnumsAsInts {
+		opts = append(opts, presenter.WithEnumsAsInts())
+	}
+	if !cfg.Output.EmitDefaults {
+		opts = append(opts, presenter.WithOmitDefaults())
+	}
+	if cfg.Output.Int64AsString {
+		opts = append(opts, presenter.WithInt64AsString())
+	}
+	bytesFormat := presenter.BytesFormat(cfg.Output.Bytes)
+	if bytesFormat != "" {
+		if err := bytesFormat.Validate(); err != nil {
+			return err
+		}
+		opts = append(opts, presenter.WithBytes(bytesFormat, cfg.Output.BytesMaxLength))
+	}
+	cliPresenter, err = presenter.NewSelector(cfg.Output.Format, opts...)
+	if err != nil {
+		return err
+	}
+	cliPresenter.SetColumns(cfg.Output.Columns)
+	if cfg.REPL.OutputTemplate != "" {
+		return cliPresenter.SetTemplate(cfg.REPL.OutputTemplate)
+	}
 	return nil
 }
 
+// Presenter returns the presenter which is used by both of CLI and REPL mode.
+// Its output format can be changed by SetFormat.
+func Presenter(cfg *config.Config) (*presenter.Selector, error) {
+	if err := initCLIPresenter(cfg); err != nil {
+		return nil, err
+	}
+	return cliPresenter, nil
+}
+
 var (
 	jsonFileInputter     *inputter.JSONFile
 	jsonFileInputterOnce sync.Once
+	jsonFileInputterErr  error
 )
 
-func initJSONFileInputter(in io.Reader) error {
+func initJSONFileInputter(cfg *config.Config, in io.Reader) error {
 	jsonFileInputterOnce.Do(func() {
-		jsonFileInputter = inputter.NewJSONFile(in)
+		var e environment.Environment
+		e, jsonFileInputterErr = Env(cfg)
+		jsonFileInputter = inputter.NewJSONFile(in, e)
 	})
-	return nil
+	return jsonFileInputterErr
 }
 
 var (
 	promptInputter     *inputter.PromptInputter
 	promptInputterOnce sync.Once
+	promptInputterErr  error
 )
 
-func initPromptInputter(cfg *config.Config) (err error) {
+func initPromptInputter(cfg *config.Config) error {
 	promptInputterOnce.Do(func() {
 		var e environment.Environment
-		e, err = Env(cfg)
+		e, promptInputterErr = Env(cfg)
 		promptInputter = inputter.NewPrompt(cfg.REPL.InputPromptFormat, e)
 	})
-	return
+	return promptInputterErr
 }
 
 var (
@@ -239,7 +313,10 @@ func initGRPCClient(cfg *config.Config) error {
 				cfg.Server.TLS,
 				cfg.Request.CACertFile,
 				cfg.Request.CertFile,
-				cfg.Request.CertKeyFile)
+				cfg.Request.CertKeyFile,
+				grpc.WithCompressor(cfg.Request.Compression),
+				grpc.WithMaxSendMsgSize(cfg.Request.MaxSendMsgSize),
+				grpc.WithMaxRecvMsgSize(cfg.Request.MaxRecvMsgSize))
 		}
 	})
 	return err
@@ -307,11 +384,11 @@ func initDependencies(cfg *config.Config, in io.Reader) error {
 	initerOnce.Do(func() {
 		initer = &initializer{}
 		initer.register(
-			func() error { return initJSONFileInputter(in) },
+			func() error { return initJSONFileInputter(cfg, in) },
 			func() error { return initPromptInputter(cfg) },
 			func() error { return initGRPCClient(cfg) },
 			func() error { return initEnv(cfg) },
-			initJSONCLIPresenter,
+			func() error { return initCLIPresenter(cfg) },
 			initDynamicBuilder,
 		)
 	})
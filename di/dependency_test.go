@@ -2,6 +2,8 @@ package di
 
 import (
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/ktr0731/evans/config"
@@ -82,3 +84,24 @@ func Test_initGRPCClient(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestEnv_error(t *testing.T) {
+	envOnce, envErr, env = sync.Once{}, nil, nil
+	cliPresenterOnce, cliPresenterErr, cliPresenter = sync.Once{}, nil, nil
+	jsonFileInputterOnce, jsonFileInputterErr, jsonFileInputter = sync.Once{}, nil, nil
+
+	cfg := &config.Config{
+		Default: &config.Default{ProtoFile: []string{"nonexist.proto"}},
+	}
+
+	// the error of the initialization is consumed by the first call.
+	require.Error(t, initJSONFileInputter(cfg, strings.NewReader("")))
+
+	_, err := Env(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse proto files")
+
+	_, err = Presenter(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse proto files")
+}
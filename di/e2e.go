@@ -7,7 +7,7 @@ import "sync"
 func Reset() {
 	reset(
 		&envOnce,
-		&jsonCLIPresenterOnce,
+		&cliPresenterOnce,
 		&jsonFileInputterOnce,
 		&promptInputterOnce,
 		&gRPCClientOnce,
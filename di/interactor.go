@@ -15,10 +15,12 @@ func NewCLIInteractorParams(cfg *config.Config, in io.Reader) (*usecase.Interact
 
 	return &usecase.InteractorParams{
 		Env:            env,
-		OutputPort:     jsonCLIPresenter,
+		OutputPort:     cliPresenter,
 		InputterPort:   jsonFileInputter,
 		GRPCClient:     gRPCClient,
 		DynamicBuilder: dynamicBuilder,
+
+		ResponseMarshaler: responseMarshaler,
 	}, nil
 }
 
@@ -28,9 +30,11 @@ func NewREPLInteractorParams(cfg *config.Config, in io.Reader) (param *usecase.I
 	}
 	return &usecase.InteractorParams{
 		Env:            env,
-		OutputPort:     jsonCLIPresenter,
+		OutputPort:     cliPresenter,
 		InputterPort:   promptInputter,
 		GRPCClient:     gRPCClient,
 		DynamicBuilder: dynamicBuilder,
+
+		ResponseMarshaler: responseMarshaler,
 	}, nil
 }
@@ -32,6 +32,12 @@ type Environment interface {
 	AddHeader(header *entity.Header)
 	RemoveHeader(key string)
 
+	// Variable returns the value of a variable which is set by SetVariable.
+	// The value is a decoded JSON value such as map[string]interface{}.
+	Variable(name string) (interface{}, bool)
+	SetVariable(name string, v interface{})
+	VariableNames() []string
+
 	UsePackage(name string) error
 	UseService(name string) error
 
@@ -51,7 +57,8 @@ type state struct {
 }
 
 type option struct {
-	headers sync.Map
+	headers   sync.Map
+	variables sync.Map
 }
 
 type Env struct {
@@ -183,6 +190,24 @@ func (e *Env) RemoveHeader(key string) {
 	e.option.headers.Delete(key)
 }
 
+func (e *Env) Variable(name string) (interface{}, bool) {
+	return e.option.variables.Load(name)
+}
+
+func (e *Env) SetVariable(name string, v interface{}) {
+	e.option.variables.Store(name, v)
+}
+
+// VariableNames returns names of all variables in lexical order.
+func (e *Env) VariableNames() (names []string) {
+	e.option.variables.Range(func(k, v interface{}) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	sort.Strings(names)
+	return
+}
+
 func (e *Env) RPC(name string) (entity.RPC, error) {
 	rpcs, err := e.RPCs()
 	if err != nil {
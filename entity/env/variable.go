@@ -0,0 +1,180 @@
+package env
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LastResponseVariable is the name of the variable which has the last received response.
+const LastResponseVariable = "last"
+
+var (
+	ErrUnknownVariableField = errors.New("unknown variable field")
+
+	// variablePattern matches references to variables such as "$last" and "$last.items.0.id",
+	// or the escaped '$' ("$$").
+	variablePattern = regexp.MustCompile(`\$\$|\$([A-Za-z_][A-Za-z0-9_]*)((?:\.[A-Za-z0-9_]+)*)`)
+)
+
+// escapedDollar is an escaped '$'. It is replaced with a literal '$' instead of being regarded as a reference.
+const escapedDollar = "$$"
+
+// Interpolate replaces references to variables in s with their values.
+// A reference consists of '$', a variable name and a dot-separated field path like "$last.user.id".
+// String values are embedded as it is, other values are embedded as JSON.
+// References to undefined variables are left as it is. A literal '$' is input by escaping it as "$$".
+func Interpolate(e Environment, s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var rerr error
+	res := variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == escapedDollar {
+			return "$"
+		}
+		v, ok, err := resolve(e, ref)
+		if err != nil {
+			rerr = err
+			return ref
+		}
+		if !ok {
+			return ref
+		}
+		if str, ok := v.(string); ok {
+			return str
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			rerr = err
+			return ref
+		}
+		return string(b)
+	})
+	if rerr != nil {
+		return "", rerr
+	}
+	return res, nil
+}
+
+// InterpolateValue replaces references to variables in strings of a decoded JSON value.
+// If a string consists of only a reference, it is replaced with the referenced value itself
+// so that numbers and objects keep their types. Otherwise, the string is interpolated by Interpolate.
+func InterpolateValue(e Environment, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		if ref := variablePattern.FindString(v); ref == v && ref != escapedDollar {
+			rv, ok, err := resolve(e, v)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return rv, nil
+			}
+			return v, nil
+		}
+		return Interpolate(e, v)
+	case map[string]interface{}:
+		for k, e2 := range v {
+			n, err := InterpolateValue(e, e2)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = n
+		}
+		return v, nil
+	case []interface{}:
+		for i, e2 := range v {
+			n, err := InterpolateValue(e, e2)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolve returns the value which is referenced by ref.
+// The second returned value is false if the variable is undefined.
+func resolve(e Environment, ref string) (interface{}, bool, error) {
+	m := variablePattern.FindStringSubmatch(ref)
+	v, ok := e.Variable(m[1])
+	if !ok {
+		return nil, false, nil
+	}
+	if m[2] == "" {
+		return v, true, nil
+	}
+	for _, k := range strings.Split(m[2][1:], ".") {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			v, ok = o[k]
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			ok = err == nil && i >= 0 && i < len(o)
+			if ok {
+				v = o[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, false, errors.Wrapf(ErrUnknownVariableField, "%s", ref)
+		}
+	}
+	return v, true, nil
+}
+
+// CompleteReference returns references which start with the incomplete reference ref.
+// For example, if $last is {"user": {...}, "users": [...]}, "$last.us" is completed to "$last.user" and "$last.users".
+// Array elements are completed with their indices.
+func CompleteReference(e Environment, ref string) []string {
+	if !strings.HasPrefix(ref, "$") {
+		return nil
+	}
+	i := strings.LastIndex(ref, ".")
+	if i == -1 {
+		var refs []string
+		for _, name := range e.VariableNames() {
+			if strings.HasPrefix(name, ref[1:]) {
+				refs = append(refs, "$"+name)
+			}
+		}
+		return refs
+	}
+
+	parent, prefix := ref[:i], ref[i+1:]
+	if variablePattern.FindString(parent) != parent {
+		return nil
+	}
+	v, ok, err := resolve(e, parent)
+	if err != nil || !ok {
+		return nil
+	}
+	var keys []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	case []interface{}:
+		for i := range v {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	}
+	var refs []string
+	for _, k := range keys {
+		if strings.HasPrefix(k, prefix) {
+			refs = append(refs, parent+"."+k)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,113 @@
+package env_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVariableEnv() env.Environment {
+	e := env.New(nil, nil)
+	e.SetVariable("last", map[string]interface{}{
+		"user":  map[string]interface{}{"id": json.Number("100"), "name": "ktr"},
+		"items": []interface{}{"foo", "bar"},
+	})
+	e.SetVariable("token", "abc")
+	return e
+}
+
+func TestInterpolate(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected string
+		err      error
+	}{
+		"no references":         {in: "hello", expected: "hello"},
+		"string field":          {in: "$last.user.name", expected: "ktr"},
+		"number field":          {in: "id=$last.user.id", expected: "id=100"},
+		"array element":         {in: "$last.items.1", expected: "bar"},
+		"object":                {in: "$last.user", expected: `{"id":100,"name":"ktr"}`},
+		"multiple":              {in: "Bearer $token ($last.user.name)", expected: "Bearer abc (ktr)"},
+		"undefined variable":    {in: "$foo.bar", expected: "$foo.bar"},
+		"escaped":               {in: "$$last.png costs $$5", expected: "$last.png costs $5"},
+		"escaped and reference": {in: "$$$token", expected: "$abc"},
+		"unknown field":         {in: "$last.user.email", err: env.ErrUnknownVariableField},
+		"index out of range":    {in: "$last.items.2", err: env.ErrUnknownVariableField},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := env.Interpolate(newVariableEnv(), c.in)
+			if c.err != nil {
+				require.Error(t, err)
+				assert.Equal(t, c.err, errors.Cause(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestCompleteReference(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected []string
+	}{
+		"variable names":     {in: "$", expected: []string{"$last", "$token"}},
+		"variable name":      {in: "$la", expected: []string{"$last"}},
+		"fields":             {in: "$last.", expected: []string{"$last.items", "$last.user"}},
+		"nested fields":      {in: "$last.user.n", expected: []string{"$last.user.name"}},
+		"array elements":     {in: "$last.items.", expected: []string{"$last.items.0", "$last.items.1"}},
+		"not a reference":    {in: "last"},
+		"undefined variable": {in: "$foo."},
+		"unknown field":      {in: "$last.foo."},
+		"scalar":             {in: "$token."},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.expected, env.CompleteReference(newVariableEnv(), c.in))
+		})
+	}
+}
+
+func TestInterpolateValue(t *testing.T) {
+	in := map[string]interface{}{
+		"id":     "$last.user.id",
+		"names":  []interface{}{"$last.user.name", "hi, $last.user.name"},
+		"nested": map[string]interface{}{"user": "$last.user"},
+		"num":    json.Number("1"),
+		"dollar": "$$",
+		"price":  "$$last.price",
+	}
+	actual, err := env.InterpolateValue(newVariableEnv(), in)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"id":     json.Number("100"),
+		"names":  []interface{}{"ktr", "hi, ktr"},
+		"nested": map[string]interface{}{"user": map[string]interface{}{"id": json.Number("100"), "name": "ktr"}},
+		"num":    json.Number("1"),
+		"dollar": "$",
+		"price":  "$last.price",
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestEnv_Variables(t *testing.T) {
+	e := env.New(nil, nil)
+	_, ok := e.Variable("last")
+	assert.False(t, ok)
+
+	e.SetVariable("b", 1)
+	e.SetVariable("a", "foo")
+	v, ok := e.Variable("a")
+	require.True(t, ok)
+	assert.Equal(t, "foo", v)
+	assert.Equal(t, []string{"a", "b"}, e.VariableNames())
+}
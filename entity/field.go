@@ -7,11 +7,12 @@ const (
 	FieldTypeEnum
 	FieldTypeOneOf
 	FieldTypeMessage
+	FieldTypeMap
 )
 
 // Field represents base field features.
 // fieldable types:
-//	enum, oneof, message, primitive
+//	enum, oneof, message, primitive, map
 type Field interface {
 	FieldName() string
 	FQRN() string
@@ -41,4 +42,18 @@ type OneOfField interface {
 type MessageField interface {
 	Field
 	Message
+
+	// WellKnownType returns the fully-qualified name of the message type if it is
+	// one of well-known types which can be input as a single value like google.protobuf.Timestamp.
+	// Otherwise, it returns an empty string.
+	WellKnownType() string
+}
+
+// MapField is a map field. Its key is a primitive field and
+// its value is one of primitive, enum and message fields.
+// IsRepeated of MapField always returns false.
+type MapField interface {
+	Field
+	Key() Field
+	Value() Field
 }
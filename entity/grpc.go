@@ -5,12 +5,15 @@ import (
 	"errors"
 
 	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/metadata"
 )
 
 var ErrMutualAuthParamsAreNotEnough = errors.New("cert and certkey are required to authenticate mutually")
 
 type GRPCClient interface {
-	Invoke(ctx context.Context, fqrn string, req, res interface{}) error
+	// Invoke sends a unary request. The header and trailer metadata which
+	// are returned by the server are also returned even if Invoke fails.
+	Invoke(ctx context.Context, fqrn string, req, res interface{}) (header, trailer metadata.MD, err error)
 	NewClientStream(ctx context.Context, rpc RPC) (ClientStream, error)
 	NewServerStream(ctx context.Context, rpc RPC) (ServerStream, error)
 	NewBidiStream(ctx context.Context, rpc RPC) (BidiStream, error)
@@ -19,20 +22,52 @@ type GRPCClient interface {
 	GRPCReflectionClient
 }
 
+// StreamMetadata provides the header and trailer metadata of a stream.
+type StreamMetadata interface {
+	// Header returns the header metadata received from the server.
+	// It blocks until the header is available.
+	Header() (metadata.MD, error)
+	// Trailer returns the trailer metadata received from the server.
+	// It must be called after the stream is finished.
+	Trailer() metadata.MD
+}
+
 type ClientStream interface {
 	Send(req proto.Message) error
 	CloseAndReceive(res *proto.Message) error
+
+	StreamMetadata
 }
 
 type ServerStream interface {
 	Send(req proto.Message) error
 	Receive(res *proto.Message) error
+
+	StreamMetadata
 }
 
 type BidiStream interface {
 	Send(req proto.Message) error
 	Receive(res *proto.Message) error
 	CloseSend() error
+
+	StreamMetadata
+}
+
+type compressorKey struct{}
+
+// NewCompressorContext returns a copy of ctx which has the name of the compressor.
+// GRPCClient compresses request messages of RPC calls which are started with the context by the compressor
+// instead of the compressor of the client.
+func NewCompressorContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, compressorKey{}, name)
+}
+
+// CompressorFromContext returns the name of the compressor which is set by NewCompressorContext.
+// It returns false if ctx doesn't have it.
+func CompressorFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(compressorKey{}).(string)
+	return name, ok
 }
 
 type GRPCReflectionClient interface {
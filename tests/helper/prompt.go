@@ -2,6 +2,7 @@ package helper
 
 import (
 	prompt "github.com/c-bata/go-prompt"
+	evprompt "github.com/ktr0731/evans/adapter/prompt"
 	"github.com/ktr0731/evans/color"
 	"github.com/pkg/errors"
 )
@@ -46,9 +47,28 @@ func (p *MockPrompt) Input() (string, error) {
 
 	p.history = append(p.history, in)
 
+	if err, ok := navigationInputs[in]; ok {
+		return "", err
+	}
 	return in, nil
 }
 
+// Inputs of MockPrompt which are regarded as navigation keys.
+// Input returns the corresponding error instead of them.
+const (
+	InputBack    = "<back>"
+	InputSkip    = "<skip>"
+	InputPreview = "<preview>"
+	InputClear   = "<clear>"
+)
+
+var navigationInputs = map[string]error{
+	InputBack:    evprompt.ErrBack,
+	InputSkip:    evprompt.ErrSkip,
+	InputPreview: evprompt.ErrPreview,
+	InputClear:   evprompt.ErrClear,
+}
+
 func (p *MockPrompt) Select(_ string, _ []string) (string, error) {
 	in := p.sq[0]
 	if len(p.sq) > 1 {
@@ -57,8 +77,19 @@ func (p *MockPrompt) Select(_ string, _ []string) (string, error) {
 	return in, nil
 }
 
+// SelectWithDefault returns def if the next selection is empty.
+func (p *MockPrompt) SelectWithDefault(msg string, opts []string, def string) (string, error) {
+	in, err := p.Select(msg, opts)
+	if err == nil && in == "" {
+		return def, nil
+	}
+	return in, err
+}
+
 func (p *MockPrompt) SetPrefix(_ string) {}
 
+func (p *MockPrompt) SetCompleter(_ func(string) []evprompt.Suggestion) {}
+
 func (p *MockPrompt) SetPrefixColor(_ color.Color) error {
 	return nil
 }
@@ -110,6 +141,9 @@ func (p *MockRepeatedPrompt) Input() (string, error) {
 
 	p.history = append(p.history, in)
 
+	if err, ok := navigationInputs[in]; ok {
+		return "", err
+	}
 	return in, nil
 }
 
@@ -130,3 +164,11 @@ func (p *MockRepeatedPrompt) Select(s string, _ []string) (string, error) {
 	}
 	return in, nil
 }
+
+func (p *MockRepeatedPrompt) SelectWithDefault(msg string, opts []string, def string) (string, error) {
+	in, err := p.Select(msg, opts)
+	if err == nil && in == "" {
+		return def, nil
+	}
+	return in, err
+}
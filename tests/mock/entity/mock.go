@@ -8,6 +8,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/entity"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"sync"
 )
 
@@ -761,7 +762,9 @@ func (mock *RPCMock) StreamDescCalls() []struct {
 
 var (
 	lockClientStreamMockCloseAndReceive sync.RWMutex
+	lockClientStreamMockHeader          sync.RWMutex
 	lockClientStreamMockSend            sync.RWMutex
+	lockClientStreamMockTrailer         sync.RWMutex
 )
 
 // Ensure, that ClientStreamMock does implement ClientStream.
@@ -777,9 +780,15 @@ var _ entity.ClientStream = &ClientStreamMock{}
 //             CloseAndReceiveFunc: func(res *proto.Message) error {
 // 	               panic("mock out the CloseAndReceive method")
 //             },
+//             HeaderFunc: func() (metadata.MD, error) {
+// 	               panic("mock out the Header method")
+//             },
 //             SendFunc: func(req proto.Message) error {
 // 	               panic("mock out the Send method")
 //             },
+//             TrailerFunc: func() metadata.MD {
+// 	               panic("mock out the Trailer method")
+//             },
 //         }
 //
 //         // use mockedClientStream in code that requires ClientStream
@@ -790,9 +799,15 @@ type ClientStreamMock struct {
 	// CloseAndReceiveFunc mocks the CloseAndReceive method.
 	CloseAndReceiveFunc func(res *proto.Message) error
 
+	// HeaderFunc mocks the Header method.
+	HeaderFunc func() (metadata.MD, error)
+
 	// SendFunc mocks the Send method.
 	SendFunc func(req proto.Message) error
 
+	// TrailerFunc mocks the Trailer method.
+	TrailerFunc func() metadata.MD
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// CloseAndReceive holds details about calls to the CloseAndReceive method.
@@ -800,11 +815,17 @@ type ClientStreamMock struct {
 			// Res is the res argument value.
 			Res *proto.Message
 		}
+		// Header holds details about calls to the Header method.
+		Header []struct {
+		}
 		// Send holds details about calls to the Send method.
 		Send []struct {
 			// Req is the req argument value.
 			Req proto.Message
 		}
+		// Trailer holds details about calls to the Trailer method.
+		Trailer []struct {
+		}
 	}
 }
 
@@ -839,6 +860,32 @@ func (mock *ClientStreamMock) CloseAndReceiveCalls() []struct {
 	return calls
 }
 
+// Header calls HeaderFunc.
+func (mock *ClientStreamMock) Header() (metadata.MD, error) {
+	if mock.HeaderFunc == nil {
+		panic("ClientStreamMock.HeaderFunc: method is nil but ClientStream.Header was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockClientStreamMockHeader.Lock()
+	mock.calls.Header = append(mock.calls.Header, callInfo)
+	lockClientStreamMockHeader.Unlock()
+	return mock.HeaderFunc()
+}
+
+// HeaderCalls gets all the calls that were made to Header.
+// Check the length with:
+//     len(mockedClientStream.HeaderCalls())
+func (mock *ClientStreamMock) HeaderCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockClientStreamMockHeader.RLock()
+	calls = mock.calls.Header
+	lockClientStreamMockHeader.RUnlock()
+	return calls
+}
+
 // Send calls SendFunc.
 func (mock *ClientStreamMock) Send(req proto.Message) error {
 	if mock.SendFunc == nil {
@@ -870,9 +917,37 @@ func (mock *ClientStreamMock) SendCalls() []struct {
 	return calls
 }
 
+// Trailer calls TrailerFunc.
+func (mock *ClientStreamMock) Trailer() metadata.MD {
+	if mock.TrailerFunc == nil {
+		panic("ClientStreamMock.TrailerFunc: method is nil but ClientStream.Trailer was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockClientStreamMockTrailer.Lock()
+	mock.calls.Trailer = append(mock.calls.Trailer, callInfo)
+	lockClientStreamMockTrailer.Unlock()
+	return mock.TrailerFunc()
+}
+
+// TrailerCalls gets all the calls that were made to Trailer.
+// Check the length with:
+//     len(mockedClientStream.TrailerCalls())
+func (mock *ClientStreamMock) TrailerCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockClientStreamMockTrailer.RLock()
+	calls = mock.calls.Trailer
+	lockClientStreamMockTrailer.RUnlock()
+	return calls
+}
+
 var (
+	lockServerStreamMockHeader  sync.RWMutex
 	lockServerStreamMockReceive sync.RWMutex
 	lockServerStreamMockSend    sync.RWMutex
+	lockServerStreamMockTrailer sync.RWMutex
 )
 
 // Ensure, that ServerStreamMock does implement ServerStream.
@@ -885,12 +960,18 @@ var _ entity.ServerStream = &ServerStreamMock{}
 //
 //         // make and configure a mocked ServerStream
 //         mockedServerStream := &ServerStreamMock{
+//             HeaderFunc: func() (metadata.MD, error) {
+// 	               panic("mock out the Header method")
+//             },
 //             ReceiveFunc: func(res *proto.Message) error {
 // 	               panic("mock out the Receive method")
 //             },
 //             SendFunc: func(req proto.Message) error {
 // 	               panic("mock out the Send method")
 //             },
+//             TrailerFunc: func() metadata.MD {
+// 	               panic("mock out the Trailer method")
+//             },
 //         }
 //
 //         // use mockedServerStream in code that requires ServerStream
@@ -898,14 +979,23 @@ var _ entity.ServerStream = &ServerStreamMock{}
 //
 //     }
 type ServerStreamMock struct {
+	// HeaderFunc mocks the Header method.
+	HeaderFunc func() (metadata.MD, error)
+
 	// ReceiveFunc mocks the Receive method.
 	ReceiveFunc func(res *proto.Message) error
 
 	// SendFunc mocks the Send method.
 	SendFunc func(req proto.Message) error
 
+	// TrailerFunc mocks the Trailer method.
+	TrailerFunc func() metadata.MD
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// Header holds details about calls to the Header method.
+		Header []struct {
+		}
 		// Receive holds details about calls to the Receive method.
 		Receive []struct {
 			// Res is the res argument value.
@@ -916,7 +1006,36 @@ type ServerStreamMock struct {
 			// Req is the req argument value.
 			Req proto.Message
 		}
+		// Trailer holds details about calls to the Trailer method.
+		Trailer []struct {
+		}
+	}
+}
+
+// Header calls HeaderFunc.
+func (mock *ServerStreamMock) Header() (metadata.MD, error) {
+	if mock.HeaderFunc == nil {
+		panic("ServerStreamMock.HeaderFunc: method is nil but ServerStream.Header was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockServerStreamMockHeader.Lock()
+	mock.calls.Header = append(mock.calls.Header, callInfo)
+	lockServerStreamMockHeader.Unlock()
+	return mock.HeaderFunc()
+}
+
+// HeaderCalls gets all the calls that were made to Header.
+// Check the length with:
+//     len(mockedServerStream.HeaderCalls())
+func (mock *ServerStreamMock) HeaderCalls() []struct {
+} {
+	var calls []struct {
 	}
+	lockServerStreamMockHeader.RLock()
+	calls = mock.calls.Header
+	lockServerStreamMockHeader.RUnlock()
+	return calls
 }
 
 // Receive calls ReceiveFunc.
@@ -981,10 +1100,38 @@ func (mock *ServerStreamMock) SendCalls() []struct {
 	return calls
 }
 
+// Trailer calls TrailerFunc.
+func (mock *ServerStreamMock) Trailer() metadata.MD {
+	if mock.TrailerFunc == nil {
+		panic("ServerStreamMock.TrailerFunc: method is nil but ServerStream.Trailer was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockServerStreamMockTrailer.Lock()
+	mock.calls.Trailer = append(mock.calls.Trailer, callInfo)
+	lockServerStreamMockTrailer.Unlock()
+	return mock.TrailerFunc()
+}
+
+// TrailerCalls gets all the calls that were made to Trailer.
+// Check the length with:
+//     len(mockedServerStream.TrailerCalls())
+func (mock *ServerStreamMock) TrailerCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockServerStreamMockTrailer.RLock()
+	calls = mock.calls.Trailer
+	lockServerStreamMockTrailer.RUnlock()
+	return calls
+}
+
 var (
 	lockBidiStreamMockCloseSend sync.RWMutex
+	lockBidiStreamMockHeader    sync.RWMutex
 	lockBidiStreamMockReceive   sync.RWMutex
 	lockBidiStreamMockSend      sync.RWMutex
+	lockBidiStreamMockTrailer   sync.RWMutex
 )
 
 // Ensure, that BidiStreamMock does implement BidiStream.
@@ -1000,12 +1147,18 @@ var _ entity.BidiStream = &BidiStreamMock{}
 //             CloseSendFunc: func() error {
 // 	               panic("mock out the CloseSend method")
 //             },
+//             HeaderFunc: func() (metadata.MD, error) {
+// 	               panic("mock out the Header method")
+//             },
 //             ReceiveFunc: func(res *proto.Message) error {
 // 	               panic("mock out the Receive method")
 //             },
 //             SendFunc: func(req proto.Message) error {
 // 	               panic("mock out the Send method")
 //             },
+//             TrailerFunc: func() metadata.MD {
+// 	               panic("mock out the Trailer method")
+//             },
 //         }
 //
 //         // use mockedBidiStream in code that requires BidiStream
@@ -1016,17 +1169,26 @@ type BidiStreamMock struct {
 	// CloseSendFunc mocks the CloseSend method.
 	CloseSendFunc func() error
 
+	// HeaderFunc mocks the Header method.
+	HeaderFunc func() (metadata.MD, error)
+
 	// ReceiveFunc mocks the Receive method.
 	ReceiveFunc func(res *proto.Message) error
 
 	// SendFunc mocks the Send method.
 	SendFunc func(req proto.Message) error
 
+	// TrailerFunc mocks the Trailer method.
+	TrailerFunc func() metadata.MD
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// CloseSend holds details about calls to the CloseSend method.
 		CloseSend []struct {
 		}
+		// Header holds details about calls to the Header method.
+		Header []struct {
+		}
 		// Receive holds details about calls to the Receive method.
 		Receive []struct {
 			// Res is the res argument value.
@@ -1037,6 +1199,9 @@ type BidiStreamMock struct {
 			// Req is the req argument value.
 			Req proto.Message
 		}
+		// Trailer holds details about calls to the Trailer method.
+		Trailer []struct {
+		}
 	}
 }
 
@@ -1066,6 +1231,32 @@ func (mock *BidiStreamMock) CloseSendCalls() []struct {
 	return calls
 }
 
+// Header calls HeaderFunc.
+func (mock *BidiStreamMock) Header() (metadata.MD, error) {
+	if mock.HeaderFunc == nil {
+		panic("BidiStreamMock.HeaderFunc: method is nil but BidiStream.Header was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockBidiStreamMockHeader.Lock()
+	mock.calls.Header = append(mock.calls.Header, callInfo)
+	lockBidiStreamMockHeader.Unlock()
+	return mock.HeaderFunc()
+}
+
+// HeaderCalls gets all the calls that were made to Header.
+// Check the length with:
+//     len(mockedBidiStream.HeaderCalls())
+func (mock *BidiStreamMock) HeaderCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockBidiStreamMockHeader.RLock()
+	calls = mock.calls.Header
+	lockBidiStreamMockHeader.RUnlock()
+	return calls
+}
+
 // Receive calls ReceiveFunc.
 func (mock *BidiStreamMock) Receive(res *proto.Message) error {
 	if mock.ReceiveFunc == nil {
@@ -1128,6 +1319,32 @@ func (mock *BidiStreamMock) SendCalls() []struct {
 	return calls
 }
 
+// Trailer calls TrailerFunc.
+func (mock *BidiStreamMock) Trailer() metadata.MD {
+	if mock.TrailerFunc == nil {
+		panic("BidiStreamMock.TrailerFunc: method is nil but BidiStream.Trailer was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockBidiStreamMockTrailer.Lock()
+	mock.calls.Trailer = append(mock.calls.Trailer, callInfo)
+	lockBidiStreamMockTrailer.Unlock()
+	return mock.TrailerFunc()
+}
+
+// TrailerCalls gets all the calls that were made to Trailer.
+// Check the length with:
+//     len(mockedBidiStream.TrailerCalls())
+func (mock *BidiStreamMock) TrailerCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockBidiStreamMockTrailer.RLock()
+	calls = mock.calls.Trailer
+	lockBidiStreamMockTrailer.RUnlock()
+	return calls
+}
+
 var (
 	lockGRPCClientMockClose             sync.RWMutex
 	lockGRPCClientMockInvoke            sync.RWMutex
@@ -1151,7 +1368,7 @@ var _ entity.GRPCClient = &GRPCClientMock{}
 //             CloseFunc: func(ctx context.Context) error {
 // 	               panic("mock out the Close method")
 //             },
-//             InvokeFunc: func(ctx context.Context, fqrn string, req interface{}, res interface{}) error {
+//             InvokeFunc: func(ctx context.Context, fqrn string, req interface{}, res interface{}) (metadata.MD, metadata.MD, error) {
 // 	               panic("mock out the Invoke method")
 //             },
 //             ListPackagesFunc: func() ([]*entity.Package, error) {
@@ -1180,7 +1397,7 @@ type GRPCClientMock struct {
 	CloseFunc func(ctx context.Context) error
 
 	// InvokeFunc mocks the Invoke method.
-	InvokeFunc func(ctx context.Context, fqrn string, req interface{}, res interface{}) error
+	InvokeFunc func(ctx context.Context, fqrn string, req interface{}, res interface{}) (metadata.MD, metadata.MD, error)
 
 	// ListPackagesFunc mocks the ListPackages method.
 	ListPackagesFunc func() ([]*entity.Package, error)
@@ -1277,7 +1494,7 @@ func (mock *GRPCClientMock) CloseCalls() []struct {
 }
 
 // Invoke calls InvokeFunc.
-func (mock *GRPCClientMock) Invoke(ctx context.Context, fqrn string, req interface{}, res interface{}) error {
+func (mock *GRPCClientMock) Invoke(ctx context.Context, fqrn string, req interface{}, res interface{}) (metadata.MD, metadata.MD, error) {
 	if mock.InvokeFunc == nil {
 		panic("GRPCClientMock.InvokeFunc: method is nil but GRPCClient.Invoke was just called")
 	}
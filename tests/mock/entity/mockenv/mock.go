@@ -9,19 +9,22 @@ import (
 )
 
 var (
-	lockEnvironmentMockAddHeader    sync.RWMutex
-	lockEnvironmentMockDSN          sync.RWMutex
-	lockEnvironmentMockHeaders      sync.RWMutex
-	lockEnvironmentMockMessage      sync.RWMutex
-	lockEnvironmentMockMessages     sync.RWMutex
-	lockEnvironmentMockPackages     sync.RWMutex
-	lockEnvironmentMockRPC          sync.RWMutex
-	lockEnvironmentMockRPCs         sync.RWMutex
-	lockEnvironmentMockRemoveHeader sync.RWMutex
-	lockEnvironmentMockService      sync.RWMutex
-	lockEnvironmentMockServices     sync.RWMutex
-	lockEnvironmentMockUsePackage   sync.RWMutex
-	lockEnvironmentMockUseService   sync.RWMutex
+	lockEnvironmentMockAddHeader     sync.RWMutex
+	lockEnvironmentMockDSN           sync.RWMutex
+	lockEnvironmentMockHeaders       sync.RWMutex
+	lockEnvironmentMockMessage       sync.RWMutex
+	lockEnvironmentMockMessages      sync.RWMutex
+	lockEnvironmentMockPackages      sync.RWMutex
+	lockEnvironmentMockRPC           sync.RWMutex
+	lockEnvironmentMockRPCs          sync.RWMutex
+	lockEnvironmentMockRemoveHeader  sync.RWMutex
+	lockEnvironmentMockService       sync.RWMutex
+	lockEnvironmentMockServices      sync.RWMutex
+	lockEnvironmentMockSetVariable   sync.RWMutex
+	lockEnvironmentMockUsePackage    sync.RWMutex
+	lockEnvironmentMockUseService    sync.RWMutex
+	lockEnvironmentMockVariable      sync.RWMutex
+	lockEnvironmentMockVariableNames sync.RWMutex
 )
 
 // EnvironmentMock is a mock implementation of Environment.
@@ -63,12 +66,21 @@ var (
 //             ServicesFunc: func() ([]entity.Service, error) {
 // 	               panic("TODO: mock out the Services method")
 //             },
+//             SetVariableFunc: func(name string, v interface{})  {
+// 	               panic("TODO: mock out the SetVariable method")
+//             },
 //             UsePackageFunc: func(name string) error {
 // 	               panic("TODO: mock out the UsePackage method")
 //             },
 //             UseServiceFunc: func(name string) error {
 // 	               panic("TODO: mock out the UseService method")
 //             },
+//             VariableFunc: func(name string) (interface{}, bool) {
+// 	               panic("TODO: mock out the Variable method")
+//             },
+//             VariableNamesFunc: func() []string {
+// 	               panic("TODO: mock out the VariableNames method")
+//             },
 //         }
 //
 //         // TODO: use mockedEnvironment in code that requires Environment
@@ -109,12 +121,21 @@ type EnvironmentMock struct {
 	// ServicesFunc mocks the Services method.
 	ServicesFunc func() ([]entity.Service, error)
 
+	// SetVariableFunc mocks the SetVariable method.
+	SetVariableFunc func(name string, v interface{})
+
 	// UsePackageFunc mocks the UsePackage method.
 	UsePackageFunc func(name string) error
 
 	// UseServiceFunc mocks the UseService method.
 	UseServiceFunc func(name string) error
 
+	// VariableFunc mocks the Variable method.
+	VariableFunc func(name string) (interface{}, bool)
+
+	// VariableNamesFunc mocks the VariableNames method.
+	VariableNamesFunc func() []string
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// AddHeader holds details about calls to the AddHeader method.
@@ -160,6 +181,13 @@ type EnvironmentMock struct {
 		// Services holds details about calls to the Services method.
 		Services []struct {
 		}
+		// SetVariable holds details about calls to the SetVariable method.
+		SetVariable []struct {
+			// Name is the name argument value.
+			Name string
+			// V is the v argument value.
+			V interface{}
+		}
 		// UsePackage holds details about calls to the UsePackage method.
 		UsePackage []struct {
 			// Name is the name argument value.
@@ -170,6 +198,14 @@ type EnvironmentMock struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// Variable holds details about calls to the Variable method.
+		Variable []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// VariableNames holds details about calls to the VariableNames method.
+		VariableNames []struct {
+		}
 	}
 }
 
@@ -484,6 +520,41 @@ func (mock *EnvironmentMock) ServicesCalls() []struct {
 	return calls
 }
 
+// SetVariable calls SetVariableFunc.
+func (mock *EnvironmentMock) SetVariable(name string, v interface{}) {
+	if mock.SetVariableFunc == nil {
+		panic("EnvironmentMock.SetVariableFunc: method is nil but Environment.SetVariable was just called")
+	}
+	callInfo := struct {
+		Name string
+		V    interface{}
+	}{
+		Name: name,
+		V:    v,
+	}
+	lockEnvironmentMockSetVariable.Lock()
+	mock.calls.SetVariable = append(mock.calls.SetVariable, callInfo)
+	lockEnvironmentMockSetVariable.Unlock()
+	mock.SetVariableFunc(name, v)
+}
+
+// SetVariableCalls gets all the calls that were made to SetVariable.
+// Check the length with:
+//     len(mockedEnvironment.SetVariableCalls())
+func (mock *EnvironmentMock) SetVariableCalls() []struct {
+	Name string
+	V    interface{}
+} {
+	var calls []struct {
+		Name string
+		V    interface{}
+	}
+	lockEnvironmentMockSetVariable.RLock()
+	calls = mock.calls.SetVariable
+	lockEnvironmentMockSetVariable.RUnlock()
+	return calls
+}
+
 // UsePackage calls UsePackageFunc.
 func (mock *EnvironmentMock) UsePackage(name string) error {
 	if mock.UsePackageFunc == nil {
@@ -545,3 +616,60 @@ func (mock *EnvironmentMock) UseServiceCalls() []struct {
 	lockEnvironmentMockUseService.RUnlock()
 	return calls
 }
+
+// Variable calls VariableFunc.
+func (mock *EnvironmentMock) Variable(name string) (interface{}, bool) {
+	if mock.VariableFunc == nil {
+		panic("EnvironmentMock.VariableFunc: method is nil but Environment.Variable was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockEnvironmentMockVariable.Lock()
+	mock.calls.Variable = append(mock.calls.Variable, callInfo)
+	lockEnvironmentMockVariable.Unlock()
+	return mock.VariableFunc(name)
+}
+
+// VariableCalls gets all the calls that were made to Variable.
+// Check the length with:
+//     len(mockedEnvironment.VariableCalls())
+func (mock *EnvironmentMock) VariableCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockEnvironmentMockVariable.RLock()
+	calls = mock.calls.Variable
+	lockEnvironmentMockVariable.RUnlock()
+	return calls
+}
+
+// VariableNames calls VariableNamesFunc.
+func (mock *EnvironmentMock) VariableNames() []string {
+	if mock.VariableNamesFunc == nil {
+		panic("EnvironmentMock.VariableNamesFunc: method is nil but Environment.VariableNames was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockEnvironmentMockVariableNames.Lock()
+	mock.calls.VariableNames = append(mock.calls.VariableNames, callInfo)
+	lockEnvironmentMockVariableNames.Unlock()
+	return mock.VariableNamesFunc()
+}
+
+// VariableNamesCalls gets all the calls that were made to VariableNames.
+// Check the length with:
+//     len(mockedEnvironment.VariableNamesCalls())
+func (mock *EnvironmentMock) VariableNamesCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockEnvironmentMockVariableNames.RLock()
+	calls = mock.calls.VariableNames
+	lockEnvironmentMockVariableNames.RUnlock()
+	return calls
+}
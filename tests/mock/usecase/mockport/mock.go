@@ -7,17 +7,24 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/usecase/port"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"io"
 	"sync"
+	"time"
 )
 
 var (
+	lockInputPortMockBench    sync.RWMutex
 	lockInputPortMockCall     sync.RWMutex
 	lockInputPortMockDescribe sync.RWMutex
 	lockInputPortMockHeader   sync.RWMutex
 	lockInputPortMockPackage  sync.RWMutex
+	lockInputPortMockReplay   sync.RWMutex
+	lockInputPortMockSave     sync.RWMutex
 	lockInputPortMockService  sync.RWMutex
 	lockInputPortMockShow     sync.RWMutex
+	lockInputPortMockTest     sync.RWMutex
 )
 
 // InputPortMock is a mock implementation of InputPort.
@@ -26,6 +33,9 @@ var (
 //
 //         // make and configure a mocked InputPort
 //         mockedInputPort := &InputPortMock{
+//             BenchFunc: func(in1 *port.BenchParams) (io.Reader, error) {
+// 	               panic("TODO: mock out the Bench method")
+//             },
 //             CallFunc: func(in1 *port.CallParams) (io.Reader, error) {
 // 	               panic("TODO: mock out the Call method")
 //             },
@@ -38,12 +48,21 @@ var (
 //             PackageFunc: func(in1 *port.PackageParams) (io.Reader, error) {
 // 	               panic("TODO: mock out the Package method")
 //             },
+//             ReplayFunc: func(in1 *port.ReplayParams) (io.Reader, error) {
+// 	               panic("TODO: mock out the Replay method")
+//             },
+//             SaveFunc: func(in1 *port.SaveParams) (io.Reader, error) {
+// 	               panic("TODO: mock out the Save method")
+//             },
 //             ServiceFunc: func(in1 *port.ServiceParams) (io.Reader, error) {
 // 	               panic("TODO: mock out the Service method")
 //             },
 //             ShowFunc: func(in1 *port.ShowParams) (io.Reader, error) {
 // 	               panic("TODO: mock out the Show method")
 //             },
+//             TestFunc: func(in1 *port.TestParams) (io.Reader, error) {
+// 	               panic("TODO: mock out the Test method")
+//             },
 //         }
 //
 //         // TODO: use mockedInputPort in code that requires InputPort
@@ -51,6 +70,9 @@ var (
 //
 //     }
 type InputPortMock struct {
+	// BenchFunc mocks the Bench method.
+	BenchFunc func(in1 *port.BenchParams) (io.Reader, error)
+
 	// CallFunc mocks the Call method.
 	CallFunc func(in1 *port.CallParams) (io.Reader, error)
 
@@ -63,14 +85,28 @@ type InputPortMock struct {
 	// PackageFunc mocks the Package method.
 	PackageFunc func(in1 *port.PackageParams) (io.Reader, error)
 
+	// ReplayFunc mocks the Replay method.
+	ReplayFunc func(in1 *port.ReplayParams) (io.Reader, error)
+
+	// SaveFunc mocks the Save method.
+	SaveFunc func(in1 *port.SaveParams) (io.Reader, error)
+
 	// ServiceFunc mocks the Service method.
 	ServiceFunc func(in1 *port.ServiceParams) (io.Reader, error)
 
 	// ShowFunc mocks the Show method.
 	ShowFunc func(in1 *port.ShowParams) (io.Reader, error)
 
+	// TestFunc mocks the Test method.
+	TestFunc func(in1 *port.TestParams) (io.Reader, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// Bench holds details about calls to the Bench method.
+		Bench []struct {
+			// In1 is the in1 argument value.
+			In1 *port.BenchParams
+		}
 		// Call holds details about calls to the Call method.
 		Call []struct {
 			// In1 is the in1 argument value.
@@ -91,6 +127,16 @@ type InputPortMock struct {
 			// In1 is the in1 argument value.
 			In1 *port.PackageParams
 		}
+		// Replay holds details about calls to the Replay method.
+		Replay []struct {
+			// In1 is the in1 argument value.
+			In1 *port.ReplayParams
+		}
+		// Save holds details about calls to the Save method.
+		Save []struct {
+			// In1 is the in1 argument value.
+			In1 *port.SaveParams
+		}
 		// Service holds details about calls to the Service method.
 		Service []struct {
 			// In1 is the in1 argument value.
@@ -101,9 +147,45 @@ type InputPortMock struct {
 			// In1 is the in1 argument value.
 			In1 *port.ShowParams
 		}
+		// Test holds details about calls to the Test method.
+		Test []struct {
+			// In1 is the in1 argument value.
+			In1 *port.TestParams
+		}
 	}
 }
 
+// Bench calls BenchFunc.
+func (mock *InputPortMock) Bench(in1 *port.BenchParams) (io.Reader, error) {
+	if mock.BenchFunc == nil {
+		panic("InputPortMock.BenchFunc: method is nil but InputPort.Bench was just called")
+	}
+	callInfo := struct {
+		In1 *port.BenchParams
+	}{
+		In1: in1,
+	}
+	lockInputPortMockBench.Lock()
+	mock.calls.Bench = append(mock.calls.Bench, callInfo)
+	lockInputPortMockBench.Unlock()
+	return mock.BenchFunc(in1)
+}
+
+// BenchCalls gets all the calls that were made to Bench.
+// Check the length with:
+//     len(mockedInputPort.BenchCalls())
+func (mock *InputPortMock) BenchCalls() []struct {
+	In1 *port.BenchParams
+} {
+	var calls []struct {
+		In1 *port.BenchParams
+	}
+	lockInputPortMockBench.RLock()
+	calls = mock.calls.Bench
+	lockInputPortMockBench.RUnlock()
+	return calls
+}
+
 // Call calls CallFunc.
 func (mock *InputPortMock) Call(in1 *port.CallParams) (io.Reader, error) {
 	if mock.CallFunc == nil {
@@ -228,6 +310,68 @@ func (mock *InputPortMock) PackageCalls() []struct {
 	return calls
 }
 
+// Replay calls ReplayFunc.
+func (mock *InputPortMock) Replay(in1 *port.ReplayParams) (io.Reader, error) {
+	if mock.ReplayFunc == nil {
+		panic("InputPortMock.ReplayFunc: method is nil but InputPort.Replay was just called")
+	}
+	callInfo := struct {
+		In1 *port.ReplayParams
+	}{
+		In1: in1,
+	}
+	lockInputPortMockReplay.Lock()
+	mock.calls.Replay = append(mock.calls.Replay, callInfo)
+	lockInputPortMockReplay.Unlock()
+	return mock.ReplayFunc(in1)
+}
+
+// ReplayCalls gets all the calls that were made to Replay.
+// Check the length with:
+//     len(mockedInputPort.ReplayCalls())
+func (mock *InputPortMock) ReplayCalls() []struct {
+	In1 *port.ReplayParams
+} {
+	var calls []struct {
+		In1 *port.ReplayParams
+	}
+	lockInputPortMockReplay.RLock()
+	calls = mock.calls.Replay
+	lockInputPortMockReplay.RUnlock()
+	return calls
+}
+
+// Save calls SaveFunc.
+func (mock *InputPortMock) Save(in1 *port.SaveParams) (io.Reader, error) {
+	if mock.SaveFunc == nil {
+		panic("InputPortMock.SaveFunc: method is nil but InputPort.Save was just called")
+	}
+	callInfo := struct {
+		In1 *port.SaveParams
+	}{
+		In1: in1,
+	}
+	lockInputPortMockSave.Lock()
+	mock.calls.Save = append(mock.calls.Save, callInfo)
+	lockInputPortMockSave.Unlock()
+	return mock.SaveFunc(in1)
+}
+
+// SaveCalls gets all the calls that were made to Save.
+// Check the length with:
+//     len(mockedInputPort.SaveCalls())
+func (mock *InputPortMock) SaveCalls() []struct {
+	In1 *port.SaveParams
+} {
+	var calls []struct {
+		In1 *port.SaveParams
+	}
+	lockInputPortMockSave.RLock()
+	calls = mock.calls.Save
+	lockInputPortMockSave.RUnlock()
+	return calls
+}
+
 // Service calls ServiceFunc.
 func (mock *InputPortMock) Service(in1 *port.ServiceParams) (io.Reader, error) {
 	if mock.ServiceFunc == nil {
@@ -290,6 +434,37 @@ func (mock *InputPortMock) ShowCalls() []struct {
 	return calls
 }
 
+// Test calls TestFunc.
+func (mock *InputPortMock) Test(in1 *port.TestParams) (io.Reader, error) {
+	if mock.TestFunc == nil {
+		panic("InputPortMock.TestFunc: method is nil but InputPort.Test was just called")
+	}
+	callInfo := struct {
+		In1 *port.TestParams
+	}{
+		In1: in1,
+	}
+	lockInputPortMockTest.Lock()
+	mock.calls.Test = append(mock.calls.Test, callInfo)
+	lockInputPortMockTest.Unlock()
+	return mock.TestFunc(in1)
+}
+
+// TestCalls gets all the calls that were made to Test.
+// Check the length with:
+//     len(mockedInputPort.TestCalls())
+func (mock *InputPortMock) TestCalls() []struct {
+	In1 *port.TestParams
+} {
+	var calls []struct {
+		In1 *port.TestParams
+	}
+	lockInputPortMockTest.RLock()
+	calls = mock.calls.Test
+	lockInputPortMockTest.RUnlock()
+	return calls
+}
+
 var (
 	lockShowableMockShow sync.RWMutex
 )
@@ -412,12 +587,23 @@ func (mock *InputterMock) InputCalls() []struct {
 }
 
 var (
-	lockOutputPortMockCall     sync.RWMutex
-	lockOutputPortMockDescribe sync.RWMutex
-	lockOutputPortMockHeader   sync.RWMutex
-	lockOutputPortMockPackage  sync.RWMutex
-	lockOutputPortMockService  sync.RWMutex
-	lockOutputPortMockShow     sync.RWMutex
+	lockOutputPortMockBench             sync.RWMutex
+	lockOutputPortMockCall              sync.RWMutex
+	lockOutputPortMockCallElapsed       sync.RWMutex
+	lockOutputPortMockCallError         sync.RWMutex
+	lockOutputPortMockCallFiltered      sync.RWMutex
+	lockOutputPortMockCallHeader        sync.RWMutex
+	lockOutputPortMockCallReceived      sync.RWMutex
+	lockOutputPortMockCallStreamSummary sync.RWMutex
+	lockOutputPortMockCallTrailer       sync.RWMutex
+	lockOutputPortMockDescribe          sync.RWMutex
+	lockOutputPortMockHeader            sync.RWMutex
+	lockOutputPortMockPackage           sync.RWMutex
+	lockOutputPortMockReplay            sync.RWMutex
+	lockOutputPortMockSave              sync.RWMutex
+	lockOutputPortMockService           sync.RWMutex
+	lockOutputPortMockShow              sync.RWMutex
+	lockOutputPortMockTest              sync.RWMutex
 )
 
 // OutputPortMock is a mock implementation of OutputPort.
@@ -426,9 +612,33 @@ var (
 //
 //         // make and configure a mocked OutputPort
 //         mockedOutputPort := &OutputPortMock{
+//             BenchFunc: func(report *port.BenchReport) (io.Reader, error) {
+// 	               panic("TODO: mock out the Bench method")
+//             },
 //             CallFunc: func(res proto.Message) (io.Reader, error) {
 // 	               panic("TODO: mock out the Call method")
 //             },
+//             CallElapsedFunc: func(elapsed time.Duration) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallElapsed method")
+//             },
+//             CallErrorFunc: func(st *status.Status) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallError method")
+//             },
+//             CallFilteredFunc: func(v interface{}) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallFiltered method")
+//             },
+//             CallHeaderFunc: func(header metadata.MD) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallHeader method")
+//             },
+//             CallReceivedFunc: func(seq int, receivedAt time.Time) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallReceived method")
+//             },
+//             CallStreamSummaryFunc: func(summary *port.StreamSummary) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallStreamSummary method")
+//             },
+//             CallTrailerFunc: func(trailer metadata.MD) (io.Reader, error) {
+// 	               panic("TODO: mock out the CallTrailer method")
+//             },
 //             DescribeFunc: func(showable port.Showable) (io.Reader, error) {
 // 	               panic("TODO: mock out the Describe method")
 //             },
@@ -438,12 +648,21 @@ var (
 //             PackageFunc: func() (io.Reader, error) {
 // 	               panic("TODO: mock out the Package method")
 //             },
+//             ReplayFunc: func(report *port.ReplayReport) (io.Reader, error) {
+// 	               panic("TODO: mock out the Replay method")
+//             },
+//             SaveFunc: func() (io.Reader, error) {
+// 	               panic("TODO: mock out the Save method")
+//             },
 //             ServiceFunc: func() (io.Reader, error) {
 // 	               panic("TODO: mock out the Service method")
 //             },
 //             ShowFunc: func(showable port.Showable) (io.Reader, error) {
 // 	               panic("TODO: mock out the Show method")
 //             },
+//             TestFunc: func(report *port.TestReport) (io.Reader, error) {
+// 	               panic("TODO: mock out the Test method")
+//             },
 //         }
 //
 //         // TODO: use mockedOutputPort in code that requires OutputPort
@@ -451,9 +670,33 @@ var (
 //
 //     }
 type OutputPortMock struct {
+	// BenchFunc mocks the Bench method.
+	BenchFunc func(report *port.BenchReport) (io.Reader, error)
+
 	// CallFunc mocks the Call method.
 	CallFunc func(res proto.Message) (io.Reader, error)
 
+	// CallElapsedFunc mocks the CallElapsed method.
+	CallElapsedFunc func(elapsed time.Duration) (io.Reader, error)
+
+	// CallErrorFunc mocks the CallError method.
+	CallErrorFunc func(st *status.Status) (io.Reader, error)
+
+	// CallFilteredFunc mocks the CallFiltered method.
+	CallFilteredFunc func(v interface{}) (io.Reader, error)
+
+	// CallHeaderFunc mocks the CallHeader method.
+	CallHeaderFunc func(header metadata.MD) (io.Reader, error)
+
+	// CallReceivedFunc mocks the CallReceived method.
+	CallReceivedFunc func(seq int, receivedAt time.Time) (io.Reader, error)
+
+	// CallStreamSummaryFunc mocks the CallStreamSummary method.
+	CallStreamSummaryFunc func(summary *port.StreamSummary) (io.Reader, error)
+
+	// CallTrailerFunc mocks the CallTrailer method.
+	CallTrailerFunc func(trailer metadata.MD) (io.Reader, error)
+
 	// DescribeFunc mocks the Describe method.
 	DescribeFunc func(showable port.Showable) (io.Reader, error)
 
@@ -463,19 +706,70 @@ type OutputPortMock struct {
 	// PackageFunc mocks the Package method.
 	PackageFunc func() (io.Reader, error)
 
+	// ReplayFunc mocks the Replay method.
+	ReplayFunc func(report *port.ReplayReport) (io.Reader, error)
+
+	// SaveFunc mocks the Save method.
+	SaveFunc func() (io.Reader, error)
+
 	// ServiceFunc mocks the Service method.
 	ServiceFunc func() (io.Reader, error)
 
 	// ShowFunc mocks the Show method.
 	ShowFunc func(showable port.Showable) (io.Reader, error)
 
+	// TestFunc mocks the Test method.
+	TestFunc func(report *port.TestReport) (io.Reader, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// Bench holds details about calls to the Bench method.
+		Bench []struct {
+			// Report is the report argument value.
+			Report *port.BenchReport
+		}
 		// Call holds details about calls to the Call method.
 		Call []struct {
 			// Res is the res argument value.
 			Res proto.Message
 		}
+		// CallElapsed holds details about calls to the CallElapsed method.
+		CallElapsed []struct {
+			// Elapsed is the elapsed argument value.
+			Elapsed time.Duration
+		}
+		// CallError holds details about calls to the CallError method.
+		CallError []struct {
+			// St is the st argument value.
+			St *status.Status
+		}
+		// CallFiltered holds details about calls to the CallFiltered method.
+		CallFiltered []struct {
+			// V is the v argument value.
+			V interface{}
+		}
+		// CallHeader holds details about calls to the CallHeader method.
+		CallHeader []struct {
+			// Header is the header argument value.
+			Header metadata.MD
+		}
+		// CallReceived holds details about calls to the CallReceived method.
+		CallReceived []struct {
+			// Seq is the seq argument value.
+			Seq int
+			// ReceivedAt is the receivedAt argument value.
+			ReceivedAt time.Time
+		}
+		// CallStreamSummary holds details about calls to the CallStreamSummary method.
+		CallStreamSummary []struct {
+			// Summary is the summary argument value.
+			Summary *port.StreamSummary
+		}
+		// CallTrailer holds details about calls to the CallTrailer method.
+		CallTrailer []struct {
+			// Trailer is the trailer argument value.
+			Trailer metadata.MD
+		}
 		// Describe holds details about calls to the Describe method.
 		Describe []struct {
 			// Showable is the showable argument value.
@@ -487,6 +781,14 @@ type OutputPortMock struct {
 		// Package holds details about calls to the Package method.
 		Package []struct {
 		}
+		// Replay holds details about calls to the Replay method.
+		Replay []struct {
+			// Report is the report argument value.
+			Report *port.ReplayReport
+		}
+		// Save holds details about calls to the Save method.
+		Save []struct {
+		}
 		// Service holds details about calls to the Service method.
 		Service []struct {
 		}
@@ -495,9 +797,45 @@ type OutputPortMock struct {
 			// Showable is the showable argument value.
 			Showable port.Showable
 		}
+		// Test holds details about calls to the Test method.
+		Test []struct {
+			// Report is the report argument value.
+			Report *port.TestReport
+		}
 	}
 }
 
+// Bench calls BenchFunc.
+func (mock *OutputPortMock) Bench(report *port.BenchReport) (io.Reader, error) {
+	if mock.BenchFunc == nil {
+		panic("OutputPortMock.BenchFunc: method is nil but OutputPort.Bench was just called")
+	}
+	callInfo := struct {
+		Report *port.BenchReport
+	}{
+		Report: report,
+	}
+	lockOutputPortMockBench.Lock()
+	mock.calls.Bench = append(mock.calls.Bench, callInfo)
+	lockOutputPortMockBench.Unlock()
+	return mock.BenchFunc(report)
+}
+
+// BenchCalls gets all the calls that were made to Bench.
+// Check the length with:
+//     len(mockedOutputPort.BenchCalls())
+func (mock *OutputPortMock) BenchCalls() []struct {
+	Report *port.BenchReport
+} {
+	var calls []struct {
+		Report *port.BenchReport
+	}
+	lockOutputPortMockBench.RLock()
+	calls = mock.calls.Bench
+	lockOutputPortMockBench.RUnlock()
+	return calls
+}
+
 // Call calls CallFunc.
 func (mock *OutputPortMock) Call(res proto.Message) (io.Reader, error) {
 	if mock.CallFunc == nil {
@@ -529,6 +867,227 @@ func (mock *OutputPortMock) CallCalls() []struct {
 	return calls
 }
 
+// CallElapsed calls CallElapsedFunc.
+func (mock *OutputPortMock) CallElapsed(elapsed time.Duration) (io.Reader, error) {
+	if mock.CallElapsedFunc == nil {
+		panic("OutputPortMock.CallElapsedFunc: method is nil but OutputPort.CallElapsed was just called")
+	}
+	callInfo := struct {
+		Elapsed time.Duration
+	}{
+		Elapsed: elapsed,
+	}
+	lockOutputPortMockCallElapsed.Lock()
+	mock.calls.CallElapsed = append(mock.calls.CallElapsed, callInfo)
+	lockOutputPortMockCallElapsed.Unlock()
+	return mock.CallElapsedFunc(elapsed)
+}
+
+// CallElapsedCalls gets all the calls that were made to CallElapsed.
+// Check the length with:
+//     len(mockedOutputPort.CallElapsedCalls())
+func (mock *OutputPortMock) CallElapsedCalls() []struct {
+	Elapsed time.Duration
+} {
+	var calls []struct {
+		Elapsed time.Duration
+	}
+	lockOutputPortMockCallElapsed.RLock()
+	calls = mock.calls.CallElapsed
+	lockOutputPortMockCallElapsed.RUnlock()
+	return calls
+}
+
+// CallError calls CallErrorFunc.
+func (mock *OutputPortMock) CallError(st *status.Status) (io.Reader, error) {
+	if mock.CallErrorFunc == nil {
+		panic("OutputPortMock.CallErrorFunc: method is nil but OutputPort.CallError was just called")
+	}
+	callInfo := struct {
+		St *status.Status
+	}{
+		St: st,
+	}
+	lockOutputPortMockCallError.Lock()
+	mock.calls.CallError = append(mock.calls.CallError, callInfo)
+	lockOutputPortMockCallError.Unlock()
+	return mock.CallErrorFunc(st)
+}
+
+// CallErrorCalls gets all the calls that were made to CallError.
+// Check the length with:
+//     len(mockedOutputPort.CallErrorCalls())
+func (mock *OutputPortMock) CallErrorCalls() []struct {
+	St *status.Status
+} {
+	var calls []struct {
+		St *status.Status
+	}
+	lockOutputPortMockCallError.RLock()
+	calls = mock.calls.CallError
+	lockOutputPortMockCallError.RUnlock()
+	return calls
+}
+
+// CallFiltered calls CallFilteredFunc.
+func (mock *OutputPortMock) CallFiltered(v interface{}) (io.Reader, error) {
+	if mock.CallFilteredFunc == nil {
+		panic("OutputPortMock.CallFilteredFunc: method is nil but OutputPort.CallFiltered was just called")
+	}
+	callInfo := struct {
+		V interface{}
+	}{
+		V: v,
+	}
+	lockOutputPortMockCallFiltered.Lock()
+	mock.calls.CallFiltered = append(mock.calls.CallFiltered, callInfo)
+	lockOutputPortMockCallFiltered.Unlock()
+	return mock.CallFilteredFunc(v)
+}
+
+// CallFilteredCalls gets all the calls that were made to CallFiltered.
+// Check the length with:
+//     len(mockedOutputPort.CallFilteredCalls())
+func (mock *OutputPortMock) CallFilteredCalls() []struct {
+	V interface{}
+} {
+	var calls []struct {
+		V interface{}
+	}
+	lockOutputPortMockCallFiltered.RLock()
+	calls = mock.calls.CallFiltered
+	lockOutputPortMockCallFiltered.RUnlock()
+	return calls
+}
+
+// CallHeader calls CallHeaderFunc.
+func (mock *OutputPortMock) CallHeader(header metadata.MD) (io.Reader, error) {
+	if mock.CallHeaderFunc == nil {
+		panic("OutputPortMock.CallHeaderFunc: method is nil but OutputPort.CallHeader was just called")
+	}
+	callInfo := struct {
+		Header metadata.MD
+	}{
+		Header: header,
+	}
+	lockOutputPortMockCallHeader.Lock()
+	mock.calls.CallHeader = append(mock.calls.CallHeader, callInfo)
+	lockOutputPortMockCallHeader.Unlock()
+	return mock.CallHeaderFunc(header)
+}
+
+// CallHeaderCalls gets all the calls that were made to CallHeader.
+// Check the length with:
+//     len(mockedOutputPort.CallHeaderCalls())
+func (mock *OutputPortMock) CallHeaderCalls() []struct {
+	Header metadata.MD
+} {
+	var calls []struct {
+		Header metadata.MD
+	}
+	lockOutputPortMockCallHeader.RLock()
+	calls = mock.calls.CallHeader
+	lockOutputPortMockCallHeader.RUnlock()
+	return calls
+}
+
+// CallReceived calls CallReceivedFunc.
+func (mock *OutputPortMock) CallReceived(seq int, receivedAt time.Time) (io.Reader, error) {
+	if mock.CallReceivedFunc == nil {
+		panic("OutputPortMock.CallReceivedFunc: method is nil but OutputPort.CallReceived was just called")
+	}
+	callInfo := struct {
+		Seq        int
+		ReceivedAt time.Time
+	}{
+		Seq:        seq,
+		ReceivedAt: receivedAt,
+	}
+	lockOutputPortMockCallReceived.Lock()
+	mock.calls.CallReceived = append(mock.calls.CallReceived, callInfo)
+	lockOutputPortMockCallReceived.Unlock()
+	return mock.CallReceivedFunc(seq, receivedAt)
+}
+
+// CallReceivedCalls gets all the calls that were made to CallReceived.
+// Check the length with:
+//     len(mockedOutputPort.CallReceivedCalls())
+func (mock *OutputPortMock) CallReceivedCalls() []struct {
+	Seq        int
+	ReceivedAt time.Time
+} {
+	var calls []struct {
+		Seq        int
+		ReceivedAt time.Time
+	}
+	lockOutputPortMockCallReceived.RLock()
+	calls = mock.calls.CallReceived
+	lockOutputPortMockCallReceived.RUnlock()
+	return calls
+}
+
+// CallStreamSummary calls CallStreamSummaryFunc.
+func (mock *OutputPortMock) CallStreamSummary(summary *port.StreamSummary) (io.Reader, error) {
+	if mock.CallStreamSummaryFunc == nil {
+		panic("OutputPortMock.CallStreamSummaryFunc: method is nil but OutputPort.CallStreamSummary was just called")
+	}
+	callInfo := struct {
+		Summary *port.StreamSummary
+	}{
+		Summary: summary,
+	}
+	lockOutputPortMockCallStreamSummary.Lock()
+	mock.calls.CallStreamSummary = append(mock.calls.CallStreamSummary, callInfo)
+	lockOutputPortMockCallStreamSummary.Unlock()
+	return mock.CallStreamSummaryFunc(summary)
+}
+
+// CallStreamSummaryCalls gets all the calls that were made to CallStreamSummary.
+// Check the length with:
+//     len(mockedOutputPort.CallStreamSummaryCalls())
+func (mock *OutputPortMock) CallStreamSummaryCalls() []struct {
+	Summary *port.StreamSummary
+} {
+	var calls []struct {
+		Summary *port.StreamSummary
+	}
+	lockOutputPortMockCallStreamSummary.RLock()
+	calls = mock.calls.CallStreamSummary
+	lockOutputPortMockCallStreamSummary.RUnlock()
+	return calls
+}
+
+// CallTrailer calls CallTrailerFunc.
+func (mock *OutputPortMock) CallTrailer(trailer metadata.MD) (io.Reader, error) {
+	if mock.CallTrailerFunc == nil {
+		panic("OutputPortMock.CallTrailerFunc: method is nil but OutputPort.CallTrailer was just called")
+	}
+	callInfo := struct {
+		Trailer metadata.MD
+	}{
+		Trailer: trailer,
+	}
+	lockOutputPortMockCallTrailer.Lock()
+	mock.calls.CallTrailer = append(mock.calls.CallTrailer, callInfo)
+	lockOutputPortMockCallTrailer.Unlock()
+	return mock.CallTrailerFunc(trailer)
+}
+
+// CallTrailerCalls gets all the calls that were made to CallTrailer.
+// Check the length with:
+//     len(mockedOutputPort.CallTrailerCalls())
+func (mock *OutputPortMock) CallTrailerCalls() []struct {
+	Trailer metadata.MD
+} {
+	var calls []struct {
+		Trailer metadata.MD
+	}
+	lockOutputPortMockCallTrailer.RLock()
+	calls = mock.calls.CallTrailer
+	lockOutputPortMockCallTrailer.RUnlock()
+	return calls
+}
+
 // Describe calls DescribeFunc.
 func (mock *OutputPortMock) Describe(showable port.Showable) (io.Reader, error) {
 	if mock.DescribeFunc == nil {
@@ -612,6 +1171,63 @@ func (mock *OutputPortMock) PackageCalls() []struct {
 	return calls
 }
 
+// Replay calls ReplayFunc.
+func (mock *OutputPortMock) Replay(report *port.ReplayReport) (io.Reader, error) {
+	if mock.ReplayFunc == nil {
+		panic("OutputPortMock.ReplayFunc: method is nil but OutputPort.Replay was just called")
+	}
+	callInfo := struct {
+		Report *port.ReplayReport
+	}{
+		Report: report,
+	}
+	lockOutputPortMockReplay.Lock()
+	mock.calls.Replay = append(mock.calls.Replay, callInfo)
+	lockOutputPortMockReplay.Unlock()
+	return mock.ReplayFunc(report)
+}
+
+// ReplayCalls gets all the calls that were made to Replay.
+// Check the length with:
+//     len(mockedOutputPort.ReplayCalls())
+func (mock *OutputPortMock) ReplayCalls() []struct {
+	Report *port.ReplayReport
+} {
+	var calls []struct {
+		Report *port.ReplayReport
+	}
+	lockOutputPortMockReplay.RLock()
+	calls = mock.calls.Replay
+	lockOutputPortMockReplay.RUnlock()
+	return calls
+}
+
+// Save calls SaveFunc.
+func (mock *OutputPortMock) Save() (io.Reader, error) {
+	if mock.SaveFunc == nil {
+		panic("OutputPortMock.SaveFunc: method is nil but OutputPort.Save was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockOutputPortMockSave.Lock()
+	mock.calls.Save = append(mock.calls.Save, callInfo)
+	lockOutputPortMockSave.Unlock()
+	return mock.SaveFunc()
+}
+
+// SaveCalls gets all the calls that were made to Save.
+// Check the length with:
+//     len(mockedOutputPort.SaveCalls())
+func (mock *OutputPortMock) SaveCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockOutputPortMockSave.RLock()
+	calls = mock.calls.Save
+	lockOutputPortMockSave.RUnlock()
+	return calls
+}
+
 // Service calls ServiceFunc.
 func (mock *OutputPortMock) Service() (io.Reader, error) {
 	if mock.ServiceFunc == nil {
@@ -669,6 +1285,37 @@ func (mock *OutputPortMock) ShowCalls() []struct {
 	return calls
 }
 
+// Test calls TestFunc.
+func (mock *OutputPortMock) Test(report *port.TestReport) (io.Reader, error) {
+	if mock.TestFunc == nil {
+		panic("OutputPortMock.TestFunc: method is nil but OutputPort.Test was just called")
+	}
+	callInfo := struct {
+		Report *port.TestReport
+	}{
+		Report: report,
+	}
+	lockOutputPortMockTest.Lock()
+	mock.calls.Test = append(mock.calls.Test, callInfo)
+	lockOutputPortMockTest.Unlock()
+	return mock.TestFunc(report)
+}
+
+// TestCalls gets all the calls that were made to Test.
+// Check the length with:
+//     len(mockedOutputPort.TestCalls())
+func (mock *OutputPortMock) TestCalls() []struct {
+	Report *port.TestReport
+} {
+	var calls []struct {
+		Report *port.TestReport
+	}
+	lockOutputPortMockTest.RLock()
+	calls = mock.calls.Test
+	lockOutputPortMockTest.RUnlock()
+	return calls
+}
+
 var (
 	lockDynamicBuilderMockNewMessage sync.RWMutex
 )
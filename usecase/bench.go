@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultBenchConcurrency is the default number of workers of a load test.
+	DefaultBenchConcurrency = 10
+	// DefaultBenchCount is the number of RPC calls which is used
+	// if neither Count nor Duration of port.BenchParams is specified.
+	DefaultBenchCount = 200
+)
+
+var (
+	benchPercentiles = []int{10, 25, 50, 75, 90, 95, 99}
+	// benchBuckets is the number of buckets of the latency histogram.
+	benchBuckets = 10
+	// benchGracePeriod is the time which in-flight RPC calls are allowed to take after Duration is elapsed.
+	// After that, they are canceled.
+	benchGracePeriod = 5 * time.Second
+)
+
+// benchResult is the result of a RPC call in a load test.
+type benchResult struct {
+	latency time.Duration
+	code    codes.Code
+}
+
+// Bench calls the RPC repeatedly with the same request message, then reports its performance.
+// The request message is inputted only once by inputter.
+// The load test can be stopped by an interrupt signal. In that case, in-flight RPC calls are canceled and
+// the finished RPC calls are reported.
+func Bench(
+	params *port.BenchParams,
+	outputPort port.OutputPort,
+	inputter port.Inputter,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) (io.Reader, error) {
+	if params.Concurrency < 1 {
+		return nil, errors.New("concurrency must be greater than 0")
+	}
+	if params.Count < 0 || params.Duration < 0 || params.QPS < 0 {
+		return nil, errors.New("count, duration and QPS must not be negative")
+	}
+	count := params.Count
+	if count == 0 && params.Duration == 0 {
+		count = DefaultBenchCount
+	}
+
+	rpc, err := env.RPC(params.RPCName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := inputter.Input(rpc.RequestMessage())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := newOutgoingContext(env)
+	if err != nil {
+		return nil, err
+	}
+	invoke := newBenchInvoker(grpcClient, builder, rpc, req)
+
+	// stopCtx stops dispatching RPC calls.
+	// callCtx cancels in-flight RPC calls. It is canceled on an interrupt signal, or when
+	// benchGracePeriod is elapsed after stopCtx is done.
+	stopCtx, stop := withTimeout(context.Background(), params.Duration)
+	defer stop()
+	callCtx, cancelCalls := context.WithCancel(ctx)
+	defer cancelCalls()
+	done := make(chan struct{})
+	gracePeriod := benchGracePeriod
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	go func() {
+		defer cancelCalls()
+		select {
+		case <-sigCh:
+			stop()
+			return
+		case <-stopCtx.Done():
+		}
+		t := time.NewTimer(gracePeriod)
+		defer t.Stop()
+		select {
+		case <-sigCh:
+		case <-t.C:
+		case <-done:
+		}
+	}()
+
+	jobs := make(chan struct{})
+	go dispatchBenchJobs(stopCtx, jobs, count, params.QPS)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+		// results isn't preallocated by count because count is specified by users.
+		results = make([]*benchResult, 0, 1024)
+	)
+	start := time.Now()
+	for i := 0; i < params.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				ctx, cancel := withTimeout(callCtx, params.Timeout)
+				s := time.Now()
+				err := invoke(ctx)
+				r := &benchResult{latency: time.Since(s), code: status.Code(errors.Cause(err))}
+				cancel()
+				if r.code == codes.Canceled && callCtx.Err() != nil {
+					// the RPC call is canceled by stopping the load test, so it is not reported.
+					continue
+				}
+
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+
+	return outputPort.Bench(newBenchReport(results, time.Since(start)))
+}
+
+// dispatchBenchJobs sends count jobs to jobs. If count is zero, it sends jobs until ctx is done.
+// If qps is positive, jobs are sent qps times per second at most.
+func dispatchBenchJobs(ctx context.Context, jobs chan<- struct{}, count, qps int) {
+	defer close(jobs)
+
+	var tick <-chan time.Time
+	if qps > 0 {
+		if interval := time.Second / time.Duration(qps); interval > 0 {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			tick = t.C
+		}
+	}
+
+	for i := 0; count == 0 || i < count; i++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newBenchInvoker returns a function which calls rpc with req once.
+// Streaming RPCs send req once, then receive all responses.
+func newBenchInvoker(
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	rpc entity.RPC,
+	req proto.Message,
+) func(context.Context) error {
+	newMessage := func() proto.Message {
+		return builder.NewMessage(rpc.ResponseMessage())
+	}
+	switch {
+	case rpc.IsClientStreaming() && rpc.IsServerStreaming():
+		return func(ctx context.Context) error {
+			st, err := grpcClient.NewBidiStream(ctx, rpc)
+			if err != nil {
+				return err
+			}
+			if err := st.Send(req); err != nil {
+				return err
+			}
+			if err := st.CloseSend(); err != nil {
+				return err
+			}
+			return receiveAll(st.Receive, newMessage)
+		}
+	case rpc.IsClientStreaming():
+		return func(ctx context.Context) error {
+			st, err := grpcClient.NewClientStream(ctx, rpc)
+			if err != nil {
+				return err
+			}
+			if err := st.Send(req); err != nil {
+				return err
+			}
+			res := newMessage()
+			return st.CloseAndReceive(&res)
+		}
+	case rpc.IsServerStreaming():
+		return func(ctx context.Context) error {
+			st, err := grpcClient.NewServerStream(ctx, rpc)
+			if err != nil {
+				return err
+			}
+			if err := st.Send(req); err != nil {
+				return err
+			}
+			return receiveAll(st.Receive, newMessage)
+		}
+	default:
+		return func(ctx context.Context) error {
+			_, _, err := grpcClient.Invoke(ctx, rpc.FQRN(), req, newMessage())
+			return err
+		}
+	}
+}
+
+// receiveAll receives responses until the stream is closed.
+func receiveAll(receive func(*proto.Message) error, newMessage func() proto.Message) error {
+	for {
+		res := newMessage()
+		err := receive(&res)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func newBenchReport(results []*benchResult, total time.Duration) *port.BenchReport {
+	report := &port.BenchReport{
+		Count:       len(results),
+		Total:       total,
+		StatusCodes: map[codes.Code]int{},
+	}
+	if len(results) == 0 {
+		return report
+	}
+
+	lats := make([]time.Duration, 0, len(results))
+	var sum time.Duration
+	for _, r := range results {
+		lats = append(lats, r.latency)
+		sum += r.latency
+		report.StatusCodes[r.code]++
+	}
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+
+	if total > 0 {
+		report.RPS = float64(len(results)) / total.Seconds()
+	}
+	report.Fastest = lats[0]
+	report.Slowest = lats[len(lats)-1]
+	report.Average = sum / time.Duration(len(lats))
+
+	for _, p := range benchPercentiles {
+		i := int(math.Ceil(float64(p)/100*float64(len(lats)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		report.Latencies = append(report.Latencies, &port.BenchLatency{Percentile: p, Latency: lats[i]})
+	}
+
+	report.Histogram = newBenchHistogram(lats)
+
+	return report
+}
+
+// newBenchHistogram divides the range from the fastest latency to the slowest latency into
+// equal-width buckets. lats must be sorted.
+func newBenchHistogram(lats []time.Duration) []*port.BenchBucket {
+	fastest, slowest := lats[0], lats[len(lats)-1]
+	width := (slowest - fastest) / time.Duration(benchBuckets)
+
+	buckets := make([]*port.BenchBucket, 0, benchBuckets+1)
+	for i := 0; i < benchBuckets; i++ {
+		buckets = append(buckets, &port.BenchBucket{Mark: fastest + width*time.Duration(i)})
+	}
+	buckets = append(buckets, &port.BenchBucket{Mark: slowest})
+
+	var bi int
+	for _, lat := range lats {
+		for lat > buckets[bi].Mark && bi < len(buckets)-1 {
+			bi++
+		}
+		buckets[bi].Count++
+	}
+	for _, b := range buckets {
+		b.Frequency = float64(b.Count) / float64(len(lats))
+	}
+	return buckets
+}
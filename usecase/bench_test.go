@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/testentity"
+	"github.com/ktr0731/evans/tests/mock/entity/mockenv"
+	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestBench(t *testing.T) {
+	env := &mockenv.EnvironmentMock{
+		RPCFunc:     func(name string) (entity.RPC, error) { return testentity.NewRPC(), nil },
+		HeadersFunc: func() []*entity.Header { return []*entity.Header{} },
+	}
+	inputter := &mockport.InputterMock{
+		InputFunc: func(entity.Message) (proto.Message, error) { return nil, nil },
+	}
+	builder := newDynamicBuilder(t)
+
+	t.Run("normal", func(t *testing.T) {
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		grpcClient := newGRPCClient(t)
+		var n int
+		grpcClient.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+			n++
+			if n%2 == 0 {
+				return nil, nil, status.Error(codes.Unavailable, "unavailable")
+			}
+			return nil, nil, nil
+		}
+
+		params := &port.BenchParams{RPCName: "SayHello", Concurrency: 1, Count: 10}
+		_, err := Bench(params, presenter, inputter, grpcClient, builder, env)
+		require.NoError(t, err)
+
+		assert.Len(t, inputter.InputCalls(), 1, "the request message must be inputted only once")
+		assert.Len(t, grpcClient.InvokeCalls(), 10)
+		require.Len(t, presenter.BenchCalls(), 1)
+		report := presenter.BenchCalls()[0].Report
+		assert.Equal(t, 10, report.Count)
+		assert.Equal(t, map[codes.Code]int{codes.OK: 5, codes.Unavailable: 5}, report.StatusCodes)
+	})
+
+	t.Run("the server hangs", func(t *testing.T) {
+		old := benchGracePeriod
+		benchGracePeriod = 10 * time.Millisecond
+		defer func() { benchGracePeriod = old }()
+
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		grpcClient := newGRPCClient(t)
+		grpcClient.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+			<-ctx.Done()
+			return nil, nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		params := &port.BenchParams{RPCName: "SayHello", Concurrency: 2, Duration: 10 * time.Millisecond}
+		_, err := Bench(params, presenter, inputter, grpcClient, builder, env)
+		require.NoError(t, err)
+
+		require.Len(t, presenter.BenchCalls(), 1)
+		assert.Equal(t, 0, presenter.BenchCalls()[0].Report.Count, "canceled calls must not be reported")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		cases := map[string]*port.BenchParams{
+			"no concurrency":    {RPCName: "SayHello"},
+			"negative count":    {RPCName: "SayHello", Concurrency: 1, Count: -1},
+			"negative duration": {RPCName: "SayHello", Concurrency: 1, Duration: -time.Second},
+		}
+		for name, params := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, err := Bench(params, usecasetest.NewPresenter(), inputter, newGRPCClient(t), builder, env)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func Test_newBenchReport(t *testing.T) {
+	var results []*benchResult
+	for i := 1; i <= 10; i++ {
+		results = append(results, &benchResult{latency: time.Duration(i) * time.Millisecond})
+	}
+	results[0].code = codes.Unavailable
+
+	report := newBenchReport(results, 2*time.Second)
+	assert.Equal(t, 10, report.Count)
+	assert.Equal(t, 5.0, report.RPS)
+	assert.Equal(t, time.Millisecond, report.Fastest)
+	assert.Equal(t, 10*time.Millisecond, report.Slowest)
+	assert.Equal(t, 5500*time.Microsecond, report.Average)
+	assert.Equal(t, map[codes.Code]int{codes.OK: 9, codes.Unavailable: 1}, report.StatusCodes)
+
+	expectedLatencies := []*port.BenchLatency{
+		{Percentile: 10, Latency: 1 * time.Millisecond},
+		{Percentile: 25, Latency: 3 * time.Millisecond},
+		{Percentile: 50, Latency: 5 * time.Millisecond},
+		{Percentile: 75, Latency: 8 * time.Millisecond},
+		{Percentile: 90, Latency: 9 * time.Millisecond},
+		{Percentile: 95, Latency: 10 * time.Millisecond},
+		{Percentile: 99, Latency: 10 * time.Millisecond},
+	}
+	assert.Equal(t, expectedLatencies, report.Latencies)
+
+	var total int
+	for _, b := range report.Histogram {
+		total += b.Count
+	}
+	assert.Equal(t, 10, total)
+
+	t.Run("no results", func(t *testing.T) {
+		report := newBenchReport(nil, time.Second)
+		assert.Equal(t, 0, report.Count)
+		assert.Empty(t, report.Latencies)
+	})
+}
@@ -3,15 +3,21 @@ package usecase
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
 	"github.com/ktr0731/evans/usecase/port"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func Call(
@@ -27,66 +33,222 @@ func Call(
 		return nil, err
 	}
 
-	data := map[string]string{}
-	for _, pair := range env.Headers() {
-		if pair.Key != "user-agent" {
-			data[pair.Key] = pair.Val
-		}
+	ctx, err := newOutgoingContext(env)
+	if err != nil {
+		return nil, err
+	}
+	if params.Compression != "" {
+		ctx = entity.NewCompressorContext(ctx, params.Compression)
+	}
+
+	outputPort, err = newFilterOutputPort(outputPort, params.Filter, params.Marshaler)
+	if err != nil {
+		return nil, err
 	}
-	ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(data))
+	// the last response is kept as a variable to be referenced by following requests.
+	// it is not filtered.
+	outputPort = &variableOutputPort{OutputPort: outputPort, env: env, marshaler: params.Marshaler}
 
-	var res proto.Message
+	var res *callResult
 	switch {
 	case rpc.IsClientStreaming() && rpc.IsServerStreaming():
-		return callBidiStreaming(ctx, outputPort, inputter, grpcClient, builder, rpc)
+		return callBidiStreaming(ctx, params.Timeout, params.Stream, outputPort, inputter, grpcClient, builder, rpc)
 	case rpc.IsClientStreaming():
-		res, err = callClientStreaming(ctx, inputter, grpcClient, builder, rpc)
+		res, err = callClientStreaming(ctx, params.Timeout, inputter, grpcClient, builder, rpc)
 	case rpc.IsServerStreaming():
-		return callServerStreaming(ctx, outputPort, inputter, grpcClient, builder, rpc)
+		return callServerStreaming(ctx, params.Timeout, params.Stream, outputPort, inputter, grpcClient, builder, rpc)
 	default:
-		res, err = callUnary(ctx, inputter, grpcClient, builder, rpc)
+		res, err = callUnary(ctx, params.Timeout, inputter, grpcClient, builder, rpc)
 	}
 	if err := errors.Cause(err); err == context.Canceled {
 		return nil, err
 	}
+	if err != nil {
+		// the header and trailer are also formatted if the RPC call failed after they were received.
+		var header, trailer metadata.MD
+		if res != nil {
+			header, trailer = res.header, res.trailer
+		}
+		return nil, newRPCError(outputPort, err, header, trailer)
+	}
+	return outputResponse(outputPort, res)
+}
+
+// newOutgoingContext returns a context which has headers in env as the outgoing metadata.
+// References to variables in header values are interpolated.
+func newOutgoingContext(e env.Environment) (context.Context, error) {
+	data := map[string]string{}
+	for _, pair := range e.Headers() {
+		if pair.Key == "user-agent" {
+			continue
+		}
+		v, err := env.Interpolate(e, pair.Val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to interpolate the header '%s'", pair.Key)
+		}
+		data[pair.Key] = v
+	}
+	return metadata.NewOutgoingContext(context.Background(), metadata.New(data)), nil
+}
+
+// RPCError is returned when an RPC call finished with a non-OK gRPC status.
+// Error returns the status formatted by port.OutputPort with the header and trailer metadata.
+// The original error can be retrieved by errors.Cause.
+type RPCError struct {
+	Status *status.Status
+	// Header and Trailer are the metadata which were received before the RPC call failed.
+	Header, Trailer metadata.MD
+
+	err error
+	out string
+}
+
+func (e *RPCError) Error() string {
+	return e.out
+}
+
+func (e *RPCError) Cause() error {
+	return e.err
+}
+
+// newRPCError formats err, header and trailer by outputPort if err has a gRPC status.
+// Otherwise, newRPCError returns err as it is.
+func newRPCError(outputPort port.OutputPort, err error, header, trailer metadata.MD) error {
+	st, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return err
+	}
+	h, perr := outputPort.CallHeader(header)
+	if perr != nil {
+		logger.Printf("failed to format header metadata: %s", perr)
+		return err
+	}
+	e, perr := outputPort.CallError(st)
+	if perr != nil {
+		logger.Printf("failed to format the error status: %s", perr)
+		return err
+	}
+	t, perr := outputPort.CallTrailer(trailer)
+	if perr != nil {
+		logger.Printf("failed to format trailer metadata: %s", perr)
+		return err
+	}
+	b, perr := ioutil.ReadAll(io.MultiReader(h, e, t))
+	if perr != nil {
+		logger.Printf("failed to read the formatted error status: %s", perr)
+		return err
+	}
+	return &RPCError{Status: st, Header: header, Trailer: trailer, err: err, out: strings.TrimRight(string(b), "\n")}
+}
+
+// withTimeout returns a copy of ctx with the timeout.
+// If timeout is zero or negative, the returned context doesn't have any deadline.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// deadlineExceeded replaces err with a DeadlineExceeded status which describes the timeout
+// if the deadline of ctx has been exceeded. Otherwise, deadlineExceeded returns err as it is.
+func deadlineExceeded(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil || ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return status.Errorf(codes.DeadlineExceeded, "the RPC call didn't finish within the timeout (%s)", timeout)
+}
+
+// callResult is the result of unary and client streaming RPCs.
+type callResult struct {
+	res             proto.Message
+	header, trailer metadata.MD
+
+	// elapsed is the time which is taken to communicate with the server.
+	elapsed time.Duration
+}
+
+// outputResponse formats a response message, its metadata and the elapsed time.
+// The header metadata is formatted before the message, the trailer metadata is after it.
+func outputResponse(outputPort port.OutputPort, res *callResult) (io.Reader, error) {
+	h, err := outputPort.CallHeader(res.header)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to output header metadata")
+	}
+	r, err := outputPort.Call(res.res)
 	if err != nil {
 		return nil, err
 	}
-	return outputPort.Call(res)
+	t, err := outputPort.CallTrailer(res.trailer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to output trailer metadata")
+	}
+	e, err := outputPort.CallElapsed(res.elapsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to output the elapsed time")
+	}
+	return io.MultiReader(h, r, t, e), nil
 }
 
 func callUnary(
 	ctx context.Context,
+	timeout time.Duration,
 	inputter port.Inputter,
 	grpcClient entity.GRPCClient,
 	builder port.DynamicBuilder,
 	rpc entity.RPC,
-) (proto.Message, error) {
+) (*callResult, error) {
 	req, err := inputter.Input(rpc.RequestMessage())
 	if err != nil {
 		return nil, err
 	}
+
+	// The deadline is set after inputting because the request message is
+	// inputted interactively in REPL mode.
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
 	res := builder.NewMessage(rpc.ResponseMessage())
-	if err := grpcClient.Invoke(ctx, rpc.FQRN(), req, res); err != nil {
-		return nil, err
+	start := time.Now()
+	header, trailer, err := grpcClient.Invoke(ctx, rpc.FQRN(), req, res)
+	if err != nil {
+		// Invoke returns the header and trailer even if the RPC call failed.
+		return &callResult{header: header, trailer: trailer}, deadlineExceeded(ctx, timeout, err)
 	}
-	return res, nil
+	return &callResult{
+		res:     res,
+		header:  header,
+		trailer: trailer,
+		elapsed: time.Since(start),
+	}, nil
 }
 
 func callClientStreaming(
 	ctx context.Context,
+	timeout time.Duration,
 	inputter port.Inputter,
 	grpcClient entity.GRPCClient,
 	builder port.DynamicBuilder,
 	rpc entity.RPC,
-) (proto.Message, error) {
-	ctx, cancel := context.WithCancel(ctx)
+) (*callResult, error) {
+	ctx, cancel := withTimeout(ctx, timeout)
 	defer cancel()
 
 	st, err := grpcClient.NewClientStream(ctx, rpc)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create client stream")
 	}
+
+	// elapsed doesn't contain the time of inputting.
+	var elapsed time.Duration
+	measure := func(f func() error) error {
+		start := time.Now()
+		defer func() {
+			elapsed += time.Since(start)
+		}()
+		return f()
+	}
+
 	for {
 		req, err := inputter.Input(rpc.RequestMessage())
 		if err := errors.Cause(err); err == io.EOF {
@@ -97,40 +259,72 @@ func callClientStreaming(
 			return nil, errors.Wrap(err, "failed to input request message")
 		}
 
-		if err := st.Send(req); err != nil {
-			return nil, errors.Wrap(err, "failed to send message")
+		if err := measure(func() error { return st.Send(req) }); err != nil {
+			return nil, errors.Wrap(deadlineExceeded(ctx, timeout, err), "failed to send message")
 		}
 	}
 
 	res := builder.NewMessage(rpc.ResponseMessage())
-	if err := st.CloseAndReceive(&res); err != nil {
-		return nil, errors.Wrap(err, "stream closed with abnormal status")
+	if err := measure(func() error { return st.CloseAndReceive(&res) }); err != nil {
+		// the header isn't available if the stream failed without it.
+		header, _ := st.Header()
+		return &callResult{header: header, trailer: st.Trailer()},
+			errors.Wrap(deadlineExceeded(ctx, timeout, err), "stream closed with abnormal status")
 	}
-	return res, nil
+	header, err := st.Header()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to receive header metadata")
+	}
+	return &callResult{
+		res:     res,
+		header:  header,
+		trailer: st.Trailer(),
+		elapsed: elapsed,
+	}, nil
 }
 
 type serverStreamingResultWriter struct {
 	s entity.ServerStream
 
-	output func(proto.Message) (io.Reader, error)
+	outputPort port.OutputPort
 
 	newMessage func() proto.Message
 
+	// cancel cancels the context of the stream.
+	// It is called when receiving responses is finished.
+	cancel context.CancelFunc
+	// timeout is used to describe DeadlineExceeded errors.
+	timeout time.Duration
+	// params controls when receiving responses is stopped and what is outputted.
+	params port.StreamParams
+	// start is the time when the stream is started.
+	start time.Time
+
+	// count and bytes are the number and the total size of received messages.
+	count, bytes int
+
 	r *io.PipeReader
 	w *io.PipeWriter
 }
 
 func newServerStramingResultWriter(
 	ctx context.Context,
+	cancel context.CancelFunc,
+	timeout time.Duration,
+	params port.StreamParams,
 	s entity.ServerStream,
-	outputPort func(proto.Message) (io.Reader, error),
+	outputPort port.OutputPort,
 	newMessage func() proto.Message,
 ) *serverStreamingResultWriter {
 	r, w := io.Pipe()
 	writer := &serverStreamingResultWriter{
 		s:          s,
-		output:     outputPort,
+		outputPort: outputPort,
 		newMessage: newMessage,
+		cancel:     cancel,
+		timeout:    timeout,
+		params:     params,
+		start:      time.Now(),
 		r:          r,
 		w:          w,
 	}
@@ -138,78 +332,181 @@ func newServerStramingResultWriter(
 	return writer
 }
 
+// receivedMessage is a response which is received from the stream.
+type receivedMessage struct {
+	res        proto.Message
+	receivedAt time.Time
+}
+
+// receiveResponse writes received responses to the pipe until the stream is closed.
+// Receiving is stopped by the client when an interrupt signal is sent, the number of messages
+// reaches params.MaxMessages or params.Duration is elapsed. In that case, the pipe is closed with io.EOF.
 func (w *serverStreamingResultWriter) receiveResponse(ctx context.Context) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	defer w.cancel()
 
-	sigCh := make(chan os.Signal)
+	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt)
-	defer close(sigCh)
+	defer signal.Stop(sigCh)
+
+	var stopCh <-chan time.Time
+	if w.params.Duration > 0 {
+		t := time.NewTimer(w.params.Duration)
+		defer t.Stop()
+		stopCh = t.C
+	}
 
+	// recvErr is the error that finished receiving responses.
+	// It must be read after resCh is closed.
+	var recvErr error
+	// the header is also received in the goroutine because Header blocks until the server sends it.
+	// responses are sent to resCh after the header is sent to headerCh.
+	headerCh := make(chan metadata.MD)
+	resCh := make(chan *receivedMessage)
 	go func() {
-		defer cancel()
-		for {
-			select {
-			case <-sigCh:
-				w.w.CloseWithError(io.EOF)
-			case <-ctx.Done():
-				w.w.CloseWithError(io.EOF)
-			}
+		header, err := w.s.Header()
+		if err != nil {
+			recvErr = errors.Wrap(err, "failed to receive header metadata")
+			close(resCh)
+			return
+		}
+		select {
+		case headerCh <- header:
+		case <-ctx.Done():
 			return
 		}
-	}()
-
-	resCh := make(chan proto.Message)
-	go func() {
-		defer cancel()
 		for {
-			select {
-			case <-sigCh:
+			res := w.newMessage()
+			err := w.s.Receive(&res)
+			if err != nil {
+				recvErr = err
+				close(resCh)
 				return
+			}
+			select {
+			case resCh <- &receivedMessage{res: res, receivedAt: time.Now()}:
 			case <-ctx.Done():
 				return
-			default:
-				res := w.newMessage()
-				err := w.s.Receive(&res)
-				if err != nil {
-					w.w.CloseWithError(err)
-					close(resCh)
-					return
-				}
-				resCh <- res
 			}
 		}
 	}()
 
 	for {
 		select {
+		case <-sigCh:
+			w.stop()
+			return
+		case <-stopCh:
+			w.stop()
+			return
 		case <-ctx.Done():
+			if err := deadlineExceeded(ctx, w.timeout, ctx.Err()); err != ctx.Err() {
+				w.finish(err, nil)
+				return
+			}
+			w.w.CloseWithError(io.EOF)
 			return
+		case header := <-headerCh:
+			if err := w.write(w.outputPort.CallHeader(header)); err != nil {
+				w.w.CloseWithError(errors.Wrap(err, "failed to write header metadata"))
+				return
+			}
 		case r, ok := <-resCh:
 			if !ok {
-				w.w.CloseWithError(io.EOF)
+				if recvErr != io.EOF {
+					w.finish(deadlineExceeded(ctx, w.timeout, recvErr), w.s.Trailer())
+					return
+				}
+				if err := w.write(w.outputPort.CallTrailer(w.s.Trailer())); err != nil {
+					w.w.CloseWithError(errors.Wrap(err, "failed to write trailer metadata"))
+					return
+				}
+				w.finish(nil, w.s.Trailer())
 				return
 			}
 
-			res, err := w.output(r)
-			if err != nil {
-				w.w.CloseWithError(errors.Wrap(err, "failed to output server streaming response"))
+			if err := w.writeMessage(r); err != nil {
+				w.w.CloseWithError(err)
 				return
 			}
-			_, err = io.Copy(w.w, res)
-			if err != nil {
-				w.w.CloseWithError(errors.Wrap(err, "failed to write server streaming response"))
+			if w.params.MaxMessages > 0 && w.count >= w.params.MaxMessages {
+				w.stop()
 				return
 			}
-			_, err = io.WriteString(w.w, "\n")
-			if err != nil {
-				w.w.CloseWithError(err)
-				return
-			}
-		default:
 		}
 	}
+}
 
+// writeMessage writes a received message with its sequence number and receive time if required.
+func (w *serverStreamingResultWriter) writeMessage(r *receivedMessage) error {
+	w.count++
+	w.bytes += proto.Size(r.res)
+
+	if w.params.ShowTimestamp {
+		if err := w.write(w.outputPort.CallReceived(w.count, r.receivedAt)); err != nil {
+			return errors.Wrap(err, "failed to write the receive time")
+		}
+	}
+	if err := w.write(w.outputPort.Call(r.res)); err != nil {
+		return errors.Wrap(err, "failed to write server streaming response")
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+// stop finishes receiving responses without any error.
+func (w *serverStreamingResultWriter) stop() {
+	w.finish(nil, nil)
+}
+
+// finish writes the elapsed time and the summary, then closes the pipe.
+// If err is not nil, the pipe is closed with err formatted as a gRPC status.
+// The stream is regarded as stopped by the client if both of err and trailer are nil.
+func (w *serverStreamingResultWriter) finish(err error, trailer metadata.MD) {
+	elapsed := time.Since(w.start)
+	if err == nil {
+		if err := w.write(w.outputPort.CallElapsed(elapsed)); err != nil {
+			w.w.CloseWithError(errors.Wrap(err, "failed to write the elapsed time"))
+			return
+		}
+	}
+
+	if w.params.ShowSummary {
+		summary := &port.StreamSummary{
+			Count:   w.count,
+			Bytes:   w.bytes,
+			Elapsed: elapsed,
+			Trailer: trailer,
+		}
+		if elapsed > 0 {
+			summary.Rate = float64(w.count) / elapsed.Seconds()
+		}
+		switch {
+		case err != nil:
+			summary.Status, _ = status.FromError(errors.Cause(err))
+		case trailer != nil:
+			summary.Status = status.New(codes.OK, "")
+		}
+		if werr := w.write(w.outputPort.CallStreamSummary(summary)); werr != nil {
+			w.w.CloseWithError(errors.Wrap(werr, "failed to write the stream summary"))
+			return
+		}
+	}
+
+	if err != nil {
+		// the header has been written already.
+		w.w.CloseWithError(newRPCError(w.outputPort, err, nil, trailer))
+		return
+	}
+	w.w.CloseWithError(io.EOF)
+}
+
+// write copies r to the pipe. If err is not nil, write returns it as it is.
+func (w *serverStreamingResultWriter) write(r io.Reader, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w.w, r)
+	return err
 }
 
 func (w *serverStreamingResultWriter) Read(b []byte) (int, error) {
@@ -218,29 +515,40 @@ func (w *serverStreamingResultWriter) Read(b []byte) (int, error) {
 
 func callServerStreaming(
 	ctx context.Context,
+	timeout time.Duration,
+	params port.StreamParams,
 	outputPort port.OutputPort,
 	inputter port.Inputter,
 	grpcClient entity.GRPCClient,
 	builder port.DynamicBuilder,
 	rpc entity.RPC,
 ) (io.Reader, error) {
-	st, err := grpcClient.NewServerStream(ctx, rpc)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create client stream")
-	}
+	// The request message is inputted before creating the stream
+	// because the deadline should not contain the time of inputting.
 	req, err := inputter.Input(rpc.RequestMessage())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to input request message")
 	}
 
+	ctx, cancel := withTimeout(ctx, timeout)
+	st, err := grpcClient.NewServerStream(ctx, rpc)
+	if err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "failed to create client stream")
+	}
+
 	if err := st.Send(req); err != nil {
+		cancel()
 		return nil, errors.Wrap(err, "failed to send server streaming request")
 	}
 
 	return newServerStramingResultWriter(
 		ctx,
+		cancel,
+		timeout,
+		params,
 		st,
-		outputPort.Call,
+		outputPort,
 		func() proto.Message {
 			return builder.NewMessage(rpc.ResponseMessage())
 		}), nil
@@ -284,13 +592,16 @@ func (sw *bidiStreamSendWriter) sendRequest(ctx context.Context) {
 
 func newBidiStramingResultWriter(
 	ctx context.Context,
+	cancel context.CancelFunc,
+	timeout time.Duration,
+	params port.StreamParams,
 	s entity.BidiStream,
-	outputPort func(proto.Message) (io.Reader, error),
+	outputPort port.OutputPort,
 	newMessage func() proto.Message,
 	inputter port.Inputter,
 	rpc entity.RPC,
 ) *bidiStreamSendWriter {
-	ssw := newServerStramingResultWriter(ctx, s, outputPort, newMessage)
+	ssw := newServerStramingResultWriter(ctx, cancel, timeout, params, s, outputPort, newMessage)
 
 	w := &bidiStreamSendWriter{
 		serverStreamingResultWriter: ssw,
@@ -305,23 +616,33 @@ func newBidiStramingResultWriter(
 	return w
 }
 
+// callBidiStreaming calls a bidirectional streaming RPC.
+// Note that the deadline and the elapsed time contain the time of inputting
+// because requests are sent while responses are received.
 func callBidiStreaming(
 	ctx context.Context,
+	timeout time.Duration,
+	params port.StreamParams,
 	outputPort port.OutputPort,
 	inputter port.Inputter,
 	grpcClient entity.GRPCClient,
 	builder port.DynamicBuilder,
 	rpc entity.RPC,
 ) (io.Reader, error) {
+	ctx, cancel := withTimeout(ctx, timeout)
 	st, err := grpcClient.NewBidiStream(ctx, rpc)
 	if err != nil {
+		cancel()
 		return nil, errors.Wrap(err, "failed to create client stream")
 	}
 
 	w := newBidiStramingResultWriter(
 		ctx,
+		cancel,
+		timeout,
+		params,
 		st,
-		outputPort.Call,
+		outputPort,
 		func() proto.Message {
 			return builder.NewMessage(rpc.ResponseMessage())
 		},
@@ -3,10 +3,12 @@ package usecase
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/entity/testentity"
 	mockentity "github.com/ktr0731/evans/tests/mock/entity"
@@ -17,27 +19,33 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func newGRPCClient(t *testing.T) *mockentity.GRPCClientMock {
 	return &mockentity.GRPCClientMock{
-		InvokeFunc: func(ctx context.Context, fqrn string, req, res interface{}) error {
+		InvokeFunc: func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
 			resText := "this is a response"
 			res = &resText
-			return nil
+			return metadata.Pairs("foo", "bar"), metadata.Pairs("hoge", "fuga"), nil
 		},
 		CloseFunc: func(context.Context) error { return nil },
 		NewClientStreamFunc: func(ctx context.Context, rpc entity.RPC) (entity.ClientStream, error) {
 			return &mockentity.ClientStreamMock{
 				SendFunc:            func(req proto.Message) error { return nil },
 				CloseAndReceiveFunc: func(res *proto.Message) error { return nil },
+				HeaderFunc:          func() (metadata.MD, error) { return nil, nil },
+				TrailerFunc:         func() metadata.MD { return nil },
 			}, nil
 		},
 		NewServerStreamFunc: func(ctx context.Context, rpc entity.RPC) (entity.ServerStream, error) {
 			return &mockentity.ServerStreamMock{
 				SendFunc:    func(req proto.Message) error { return nil },
 				ReceiveFunc: func(res *proto.Message) error { return nil },
+				HeaderFunc:  func() (metadata.MD, error) { return nil, nil },
+				TrailerFunc: func() metadata.MD { return nil },
 			}, nil
 		},
 		NewBidiStreamFunc: func(ctx context.Context, rpc entity.RPC) (entity.BidiStream, error) {
@@ -45,6 +53,8 @@ func newGRPCClient(t *testing.T) *mockentity.GRPCClientMock {
 				SendFunc:      func(req proto.Message) error { return nil },
 				ReceiveFunc:   func(res *proto.Message) error { return nil },
 				CloseSendFunc: func() error { return nil },
+				HeaderFunc:    func() (metadata.MD, error) { return nil, nil },
+				TrailerFunc:   func() metadata.MD { return nil },
 			}, nil
 		},
 	}
@@ -64,8 +74,9 @@ func TestCall(t *testing.T) {
 
 	newEnv := func(t *testing.T) *mockenv.EnvironmentMock {
 		return &mockenv.EnvironmentMock{
-			RPCFunc:     func(name string) (entity.RPC, error) { return testentity.NewRPC(), nil },
-			HeadersFunc: func() []*entity.Header { return []*entity.Header{} },
+			RPCFunc:         func(name string) (entity.RPC, error) { return testentity.NewRPC(), nil },
+			HeadersFunc:     func() []*entity.Header { return []*entity.Header{} },
+			SetVariableFunc: func(string, interface{}) {},
 		}
 	}
 	inputter := &mockport.InputterMock{
@@ -74,12 +85,17 @@ func TestCall(t *testing.T) {
 	builder := newDynamicBuilder(t)
 
 	t.Run("normal", func(t *testing.T) {
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
 		grpcClient := newGRPCClient(t)
 		env := newEnv(t)
 		res, err := Call(params, presenter, inputter, grpcClient, builder, env)
 		assert.NoError(t, err)
+		assert.NotNil(t, res)
 
-		assert.Equal(t, nil, res)
+		require.Len(t, presenter.CallHeaderCalls(), 1)
+		assert.Equal(t, metadata.Pairs("foo", "bar"), presenter.CallHeaderCalls()[0].Header)
+		require.Len(t, presenter.CallTrailerCalls(), 1)
+		assert.Equal(t, metadata.Pairs("hoge", "fuga"), presenter.CallTrailerCalls()[0].Trailer)
 	})
 
 	t.Run("with headers", func(t *testing.T) {
@@ -95,8 +111,7 @@ func TestCall(t *testing.T) {
 
 		res, err := Call(params, presenter, inputter, grpcClient, builder, env)
 		assert.NoError(t, err)
-
-		assert.Equal(t, nil, res)
+		assert.NotNil(t, res)
 
 		require.Len(t, grpcClient.InvokeCalls(), 1)
 		actualCtx := grpcClient.InvokeCalls()[0].Ctx
@@ -110,6 +125,76 @@ func TestCall(t *testing.T) {
 		_, ok = md["user-agent"]
 		assert.False(t, ok)
 	})
+
+	t.Run("with compression", func(t *testing.T) {
+		grpcClient := newGRPCClient(t)
+		env := newEnv(t)
+
+		params := &port.CallParams{RPCName: "SayHello", Compression: "gzip"}
+		_, err := Call(params, presenter, inputter, grpcClient, builder, env)
+		require.NoError(t, err)
+
+		require.Len(t, grpcClient.InvokeCalls(), 1)
+		name, ok := entity.CompressorFromContext(grpcClient.InvokeCalls()[0].Ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "gzip", name)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		grpcClient := newGRPCClient(t)
+		serr := status.Error(codes.NotFound, "not found")
+		grpcClient.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+			return nil, nil, serr
+		}
+		env := newEnv(t)
+
+		_, err := Call(params, presenter, inputter, grpcClient, builder, env)
+		require.Error(t, err)
+		rerr, ok := err.(*RPCError)
+		require.True(t, ok, "Call must return *RPCError if the RPC returned a gRPC status")
+		assert.Equal(t, codes.NotFound, rerr.Status.Code())
+		assert.Equal(t, "not found", rerr.Error())
+		assert.Equal(t, serr, errors.Cause(err))
+	})
+
+	t.Run("error status with metadata", func(t *testing.T) {
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		grpcClient := newGRPCClient(t)
+		grpcClient.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+			return metadata.Pairs("foo", "bar"), metadata.Pairs("retry-after", "10"), status.Error(codes.ResourceExhausted, "rate limited")
+		}
+		env := newEnv(t)
+
+		_, err := Call(params, presenter, inputter, grpcClient, builder, env)
+		require.Error(t, err)
+		rerr, ok := err.(*RPCError)
+		require.True(t, ok, "Call must return *RPCError if the RPC returned a gRPC status")
+		assert.Equal(t, metadata.Pairs("foo", "bar"), rerr.Header)
+		assert.Equal(t, metadata.Pairs("retry-after", "10"), rerr.Trailer)
+		require.Len(t, presenter.CallHeaderCalls(), 1)
+		assert.Equal(t, metadata.Pairs("foo", "bar"), presenter.CallHeaderCalls()[0].Header)
+		require.Len(t, presenter.CallTrailerCalls(), 1)
+		assert.Equal(t, metadata.Pairs("retry-after", "10"), presenter.CallTrailerCalls()[0].Trailer)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		grpcClient := newGRPCClient(t)
+		grpcClient.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+			_, ok := ctx.Deadline()
+			require.True(t, ok, "the context must have a deadline")
+			<-ctx.Done()
+			return nil, nil, status.FromContextError(ctx.Err()).Err()
+		}
+		env := newEnv(t)
+
+		params := &port.CallParams{RPCName: "SayHello", Timeout: time.Millisecond}
+		_, err := Call(params, presenter, inputter, grpcClient, builder, env)
+		require.Error(t, err)
+		rerr, ok := err.(*RPCError)
+		require.True(t, ok, "Call must return *RPCError if the deadline is exceeded")
+		assert.Equal(t, codes.DeadlineExceeded, rerr.Status.Code())
+		assert.Equal(t, "the RPC call didn't finish within the timeout (1ms)", rerr.Error())
+	})
 }
 
 func TestCall_ClientStream(t *testing.T) {
@@ -121,8 +206,9 @@ func TestCall_ClientStream(t *testing.T) {
 
 	newEnv := func(t *testing.T) *mockenv.EnvironmentMock {
 		return &mockenv.EnvironmentMock{
-			RPCFunc:     func(name string) (entity.RPC, error) { return rpc, nil },
-			HeadersFunc: func() []*entity.Header { return []*entity.Header{} },
+			RPCFunc:         func(name string) (entity.RPC, error) { return rpc, nil },
+			HeadersFunc:     func() []*entity.Header { return []*entity.Header{} },
+			SetVariableFunc: func(string, interface{}) {},
 		}
 	}
 	env := newEnv(t)
@@ -136,7 +222,7 @@ func TestCall_ClientStream(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		res, err := Call(params, presenter, inputter, grpcClient, builder, env)
 		assert.NoError(t, err)
-		assert.Equal(t, nil, res)
+		assert.NotNil(t, res)
 	})
 }
 
@@ -153,7 +239,7 @@ func TestCall_ServerStream(t *testing.T) {
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
 		defer cancel()
-		_, err := callServerStreaming(ctx, presenter, inputter, grpcClient, builder, rpc)
+		_, err := callServerStreaming(ctx, 0, port.StreamParams{}, presenter, inputter, grpcClient, builder, rpc)
 		assert.NoError(t, err)
 	})
 
@@ -161,9 +247,131 @@ func TestCall_ServerStream(t *testing.T) {
 		inputter := &mockport.InputterMock{
 			InputFunc: func(entity.Message) (proto.Message, error) { return nil, io.EOF },
 		}
-		_, err := callServerStreaming(context.Background(), presenter, inputter, grpcClient, builder, rpc)
+		_, err := callServerStreaming(context.Background(), 0, port.StreamParams{}, presenter, inputter, grpcClient, builder, rpc)
 		assert.Equal(t, io.EOF, errors.Cause(err))
 	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		inputter := &mockport.InputterMock{
+			InputFunc: func(entity.Message) (proto.Message, error) { return nil, nil },
+		}
+		grpcClient := newGRPCClient(t)
+		grpcClient.NewServerStreamFunc = func(ctx context.Context, rpc entity.RPC) (entity.ServerStream, error) {
+			return &mockentity.ServerStreamMock{
+				SendFunc: func(req proto.Message) error { return nil },
+				ReceiveFunc: func(res *proto.Message) error {
+					<-ctx.Done()
+					return status.FromContextError(ctx.Err()).Err()
+				},
+				HeaderFunc:  func() (metadata.MD, error) { return nil, nil },
+				TrailerFunc: func() metadata.MD { return nil },
+			}, nil
+		}
+		r, err := callServerStreaming(context.Background(), time.Millisecond, port.StreamParams{}, presenter, inputter, grpcClient, builder, rpc)
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(r)
+		require.Error(t, err)
+		rerr, ok := err.(*RPCError)
+		require.True(t, ok, "reading the result must return *RPCError if the deadline is exceeded")
+		assert.Equal(t, codes.DeadlineExceeded, rerr.Status.Code())
+	})
+}
+
+func TestCall_ServerStreamControls(t *testing.T) {
+	rpc := testentity.NewRPC()
+	rpc.FIsServerStreaming = true
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+	inputter := &mockport.InputterMock{
+		InputFunc: func(entity.Message) (proto.Message, error) { return &wrappers.StringValue{}, nil },
+	}
+	// newGRPCClient returns a client which sends n messages. If n is negative, messages are sent endlessly.
+	// If noHeader is true, the server never sends the header.
+	newGRPCClient := func(n int, noHeader bool) *mockentity.GRPCClientMock {
+		c := newGRPCClient(t)
+		c.NewServerStreamFunc = func(ctx context.Context, rpc entity.RPC) (entity.ServerStream, error) {
+			var sent int
+			return &mockentity.ServerStreamMock{
+				SendFunc: func(req proto.Message) error { return nil },
+				ReceiveFunc: func(res *proto.Message) error {
+					if n >= 0 && sent >= n {
+						return io.EOF
+					}
+					sent++
+					(*res).(*wrappers.StringValue).Value = "foo"
+					return nil
+				},
+				HeaderFunc: func() (metadata.MD, error) {
+					if noHeader {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+					return nil, nil
+				},
+				TrailerFunc: func() metadata.MD { return metadata.Pairs("foo", "bar") },
+			}, nil
+		}
+		return c
+	}
+
+	cases := map[string]struct {
+		n        int
+		noHeader bool
+		params   port.StreamParams
+
+		expectedCount int
+		stopped       bool
+	}{
+		"max messages":      {n: -1, params: port.StreamParams{MaxMessages: 3, ShowSummary: true}, expectedCount: 3, stopped: true},
+		"duration":          {n: -1, params: port.StreamParams{Duration: 10 * time.Millisecond, ShowSummary: true}, stopped: true},
+		"finished":          {n: 2, params: port.StreamParams{MaxMessages: 3, ShowSummary: true}, expectedCount: 2},
+		"without summary":   {n: 2, params: port.StreamParams{}, expectedCount: 2},
+		"with timestamp":    {n: 2, params: port.StreamParams{ShowTimestamp: true}, expectedCount: 2},
+		"limit not reached": {n: 0, params: port.StreamParams{MaxMessages: 1, ShowSummary: true}},
+		"no header":         {n: -1, noHeader: true, params: port.StreamParams{Duration: 10 * time.Millisecond, ShowSummary: true}, stopped: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+			r, err := callServerStreaming(context.Background(), 0, c.params, presenter, inputter, newGRPCClient(c.n, c.noHeader), builder, rpc)
+			require.NoError(t, err)
+			_, err = ioutil.ReadAll(r)
+			require.NoError(t, err)
+
+			if c.params.Duration == 0 {
+				assert.Len(t, presenter.CallCalls(), c.expectedCount)
+			}
+			if c.noHeader {
+				assert.Len(t, presenter.CallHeaderCalls(), 0)
+				assert.Len(t, presenter.CallCalls(), 0)
+			}
+			if c.params.ShowTimestamp {
+				require.Len(t, presenter.CallReceivedCalls(), c.expectedCount)
+				assert.Equal(t, 2, presenter.CallReceivedCalls()[1].Seq)
+			} else {
+				assert.Len(t, presenter.CallReceivedCalls(), 0)
+			}
+			if !c.params.ShowSummary {
+				assert.Len(t, presenter.CallStreamSummaryCalls(), 0)
+				return
+			}
+
+			require.Len(t, presenter.CallStreamSummaryCalls(), 1)
+			summary := presenter.CallStreamSummaryCalls()[0].Summary
+			assert.Equal(t, len(presenter.CallCalls()), summary.Count)
+			if c.stopped {
+				assert.Nil(t, summary.Status)
+				assert.Nil(t, summary.Trailer)
+				return
+			}
+			require.NotNil(t, summary.Status)
+			assert.Equal(t, codes.OK, summary.Status.Code())
+			assert.Equal(t, metadata.Pairs("foo", "bar"), summary.Trailer)
+			assert.Equal(t, summary.Count*proto.Size(&wrappers.StringValue{Value: "foo"}), summary.Bytes)
+		})
+	}
 }
 
 func TestCall_BidiStream(t *testing.T) {
@@ -180,7 +388,7 @@ func TestCall_BidiStream(t *testing.T) {
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
 		defer cancel()
-		_, err := callBidiStreaming(ctx, presenter, inputter, grpcClient, builder, rpc)
+		_, err := callBidiStreaming(ctx, 0, port.StreamParams{}, presenter, inputter, grpcClient, builder, rpc)
 		assert.NoError(t, err)
 	})
 }
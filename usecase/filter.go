@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidFilter is returned when a filter expression has a syntax error.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// filter extracts values from a decoded JSON value.
+// A filter may return zero or more values like jq.
+type filter func(v interface{}) ([]interface{}, error)
+
+// parseFilter parses a jq-style filter expression. The following subset of jq is supported.
+//
+//   .             the identity
+//   .foo, ."foo"  the value of the field (null if it is missing)
+//   .[0], .[-1]   the element of the array (null if it is out of range)
+//   .["foo"]      the value of the field
+//   .[]           all elements of the array or all values of the object
+//   a | b         passes each output of a to b
+//   a, b          outputs of a, then outputs of b
+//
+// Successive accesses can be chained like ".items[].name".
+func parseFilter(expr string) (filter, error) {
+	p := &filterParser{s: expr}
+	f, err := p.parsePipe()
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidFilter, "%s: %s", expr, err)
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, errors.Wrapf(ErrInvalidFilter, "%s: unexpected '%c' at %d", expr, p.s[p.pos], p.pos+1)
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *filterParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips spaces, then consumes c if the next character is c.
+func (p *filterParser) consume(c byte) bool {
+	p.skipSpaces()
+	if !p.eof() && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parsePipe() (filter, error) {
+	lhs, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume('|') {
+		rhs, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		lhs = pipeFilter(lhs, rhs)
+	}
+	return lhs, nil
+}
+
+func (p *filterParser) parseComma() (filter, error) {
+	f, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	filters := []filter{f}
+	for p.consume(',') {
+		f, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return func(v interface{}) ([]interface{}, error) {
+		var out []interface{}
+		for _, f := range filters {
+			res, err := f(v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, res...)
+		}
+		return out, nil
+	}, nil
+}
+
+// parsePath parses a path which starts with '.' like ".items[0].name".
+func (p *filterParser) parsePath() (filter, error) {
+	if !p.consume('.') {
+		if p.eof() {
+			return nil, errors.New("unexpected end of the expression")
+		}
+		return nil, errors.Errorf("unexpected '%c' at %d", p.s[p.pos], p.pos+1)
+	}
+	f := identityFilter
+	// the first access may follow the leading '.' directly like ".foo" and ".[0]".
+	first := true
+	for {
+		var (
+			next filter
+			err  error
+		)
+		switch {
+		case first && p.peekIdentOrString():
+			next, err = p.parseField()
+		case !p.eof() && p.s[p.pos] == '[':
+			next, err = p.parseBracket()
+		case !first && !p.eof() && p.s[p.pos] == '.':
+			p.pos++
+			if p.peekIdentOrString() {
+				next, err = p.parseField()
+			} else if !p.eof() && p.s[p.pos] == '[' {
+				next, err = p.parseBracket()
+			} else {
+				err = errors.Errorf("a field name is expected at %d", p.pos+1)
+			}
+		default:
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f = pipeFilter(f, next)
+		first = false
+	}
+}
+
+func (p *filterParser) peekIdentOrString() bool {
+	if p.eof() {
+		return false
+	}
+	c := rune(p.s[p.pos])
+	return c == '"' || c == '_' || unicode.IsLetter(c)
+}
+
+// parseField parses a field name which is an identifier or a quoted string.
+func (p *filterParser) parseField() (filter, error) {
+	if p.s[p.pos] == '"' {
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return fieldFilter(name), nil
+	}
+	start := p.pos
+	for !p.eof() {
+		c := rune(p.s[p.pos])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		p.pos++
+	}
+	return fieldFilter(p.s[start:p.pos]), nil
+}
+
+func (p *filterParser) parseString() (string, error) {
+	start := p.pos
+	for p.pos++; !p.eof(); p.pos++ {
+		switch p.s[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.s[start:p.pos])
+			if err != nil {
+				return "", errors.Errorf("invalid string at %d", start+1)
+			}
+			return s, nil
+		}
+	}
+	return "", errors.Errorf("unterminated string at %d", start+1)
+}
+
+// parseBracket parses "[]", "[<index>]" or "[<string>]".
+func (p *filterParser) parseBracket() (filter, error) {
+	p.pos++ // '['
+	if p.consume(']') {
+		return iterateFilter, nil
+	}
+	p.skipSpaces()
+	var f filter
+	if !p.eof() && p.s[p.pos] == '"' {
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		f = fieldFilter(name)
+	} else {
+		start := p.pos
+		if !p.eof() && p.s[p.pos] == '-' {
+			p.pos++
+		}
+		for !p.eof() && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		i, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil {
+			return nil, errors.Errorf("an index or a field name is expected at %d", start+1)
+		}
+		f = indexFilter(i)
+	}
+	if !p.consume(']') {
+		return nil, errors.Errorf("']' is expected at %d", p.pos+1)
+	}
+	return f, nil
+}
+
+func identityFilter(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+func pipeFilter(lhs, rhs filter) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		res, err := lhs(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, r := range res {
+			o, err := rhs(r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, o...)
+		}
+		return out, nil
+	}
+}
+
+func fieldFilter(name string) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch o := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case map[string]interface{}:
+			return []interface{}{o[name]}, nil
+		default:
+			return nil, errors.Errorf("cannot get the field %q of %s", name, typeName(v))
+		}
+	}
+}
+
+func indexFilter(i int) filter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch a := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			j := i
+			if j < 0 {
+				j += len(a)
+			}
+			if j < 0 || j >= len(a) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{a[j]}, nil
+		default:
+			return nil, errors.Errorf("cannot get the element %d of %s", i, typeName(v))
+		}
+	}
+}
+
+func iterateFilter(v interface{}) ([]interface{}, error) {
+	switch o := v.(type) {
+	case []interface{}:
+		return o, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, 0, len(o))
+		for _, k := range keys {
+			out = append(out, o[k])
+		}
+		return out, nil
+	default:
+		return nil, errors.Errorf("cannot iterate over %s", typeName(v))
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// filterOutputPort applies a filter to each response. Each filtered value is
+// formatted by port.OutputPort.CallFiltered.
+type filterOutputPort struct {
+	port.OutputPort
+	filter    filter
+	marshaler *jsonpb.Marshaler
+}
+
+// newFilterOutputPort wraps outputPort with the filter expression.
+// If expr is empty or the identity, outputPort is returned as it is
+// so that responses are formatted as messages.
+// Responses are decoded by marshaler. See decodeMessage.
+func newFilterOutputPort(outputPort port.OutputPort, expr string, marshaler *jsonpb.Marshaler) (port.OutputPort, error) {
+	if expr = strings.TrimSpace(expr); expr == "" || expr == "." {
+		return outputPort, nil
+	}
+	f, err := parseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &filterOutputPort{OutputPort: outputPort, filter: f, marshaler: marshaler}, nil
+}
+
+// withFilter applies the filter of params to outputPort, and returns params without the filter.
+// Callers which observe responses by wrapping the returned OutputPort receive them before they are filtered.
+func withFilter(params *port.CallParams, outputPort port.OutputPort) (*port.CallParams, port.OutputPort, error) {
+	outputPort, err := newFilterOutputPort(outputPort, params.Filter, params.Marshaler)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := *params
+	p.Filter = ""
+	return &p, outputPort, nil
+}
+
+func (p *filterOutputPort) Call(res proto.Message) (io.Reader, error) {
+	v, err := decodeMessage(res, p.marshaler)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the response")
+	}
+	values, err := p.filter(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter the response")
+	}
+	readers := make([]io.Reader, 0, len(values))
+	for _, v := range values {
+		r, err := p.OutputPort.CallFiltered(v)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, r)
+	}
+	return io.MultiReader(readers...), nil
+}
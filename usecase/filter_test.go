@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseFilter(t *testing.T) {
+	const in = `{
+  "total": 2,
+  "items": [{"name": "okabe", "id": 1}, {"name": "makise", "id": 2}],
+  "meta": {"next page": "abc"}
+}`
+
+	cases := map[string]struct {
+		expr     string
+		expected string
+		hasError bool
+	}{
+		"identity":         {expr: ".", expected: `[` + in + `]`},
+		"field":            {expr: ".total", expected: `[2]`},
+		"quoted field":     {expr: `.meta."next page"`, expected: `["abc"]`},
+		"bracket field":    {expr: `.meta["next page"]`, expected: `["abc"]`},
+		"missing field":    {expr: ".foo.bar", expected: `[null]`},
+		"index":            {expr: ".items[1].name", expected: `["makise"]`},
+		"negative index":   {expr: ".items[-1].id", expected: `[2]`},
+		"out of range":     {expr: ".items[2]", expected: `[null]`},
+		"iterate":          {expr: ".items[].name", expected: `["okabe", "makise"]`},
+		"iterate (dot)":    {expr: ".items.[].id", expected: `[1, 2]`},
+		"iterate object":   {expr: ".items[0][]", expected: `[1, "okabe"]`},
+		"pipe":             {expr: ".items | .[0] | .name", expected: `["okabe"]`},
+		"comma":            {expr: ".total, .items[0].id", expected: `[2, 1]`},
+		"pipe and comma":   {expr: ".items[] | .id, .name", expected: `[1, "okabe", 2, "makise"]`},
+		"field of number":  {expr: ".total.foo", hasError: true},
+		"iterate a string": {expr: ".meta[\"next page\"][]", hasError: true},
+		"no dot":           {expr: "total", hasError: true},
+		"unclosed bracket": {expr: ".items[0", hasError: true},
+		"unclosed string":  {expr: `."foo`, hasError: true},
+		"trailing dot":     {expr: ".items.", hasError: true},
+		"empty":            {expr: "", hasError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			dec := json.NewDecoder(strings.NewReader(in))
+			dec.UseNumber()
+			var v interface{}
+			require.NoError(t, dec.Decode(&v))
+
+			f, err := parseFilter(c.expr)
+			if err == nil {
+				var res []interface{}
+				res, err = f(v)
+				if err == nil {
+					b, err := json.Marshal(res)
+					require.NoError(t, err)
+					assert.JSONEq(t, c.expected, string(b))
+				}
+			}
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_newFilterOutputPort(t *testing.T) {
+	presenter := usecasetest.NewPresenter()
+
+	for _, expr := range []string{"", ".", " . "} {
+		p, err := newFilterOutputPort(presenter, expr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, presenter, p, "the identity filter must not wrap the output port")
+	}
+
+	_, err := newFilterOutputPort(presenter, ".[", nil)
+	assert.Error(t, err)
+
+	var filtered []interface{}
+	presenter.(*mockport.OutputPortMock).CallFilteredFunc = func(v interface{}) (io.Reader, error) {
+		filtered = append(filtered, v)
+		return strings.NewReader("filtered\n"), nil
+	}
+	p, err := newFilterOutputPort(presenter, ".name, .number", nil)
+	require.NoError(t, err)
+	r, err := p.Call(&descriptor.FieldDescriptorProto{Name: proto.String("foo"), Number: proto.Int32(1)})
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "filtered\nfiltered\n", string(b))
+	assert.Equal(t, []interface{}{"foo", json.Number("1")}, filtered)
+}
+
+func Test_newFilterOutputPort_marshaler(t *testing.T) {
+	presenter := usecasetest.NewPresenter()
+	var filtered []interface{}
+	presenter.(*mockport.OutputPortMock).CallFilteredFunc = func(v interface{}) (io.Reader, error) {
+		filtered = append(filtered, v)
+		return strings.NewReader(""), nil
+	}
+
+	// field names must be the same as formatted responses.
+	p, err := newFilterOutputPort(presenter, ".type_name, .typeName", &jsonpb.Marshaler{OrigName: true})
+	require.NoError(t, err)
+	_, err = p.Call(&descriptor.FieldDescriptorProto{TypeName: proto.String(".foo.Bar")})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{".foo.Bar", nil}, filtered)
+}
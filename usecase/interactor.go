@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/ktr0731/evans/entity"
 	"github.com/ktr0731/evans/entity/env"
 	"github.com/ktr0731/evans/usecase/pbusecase"
@@ -21,6 +22,10 @@ type Interactor struct {
 	inputterPort   port.Inputter
 	grpcPort       entity.GRPCClient
 	dynamicBuilder port.DynamicBuilder
+
+	recorder port.Recorder
+
+	responseMarshaler *jsonpb.Marshaler
 }
 
 type InteractorParams struct {
@@ -30,6 +35,13 @@ type InteractorParams struct {
 	InputterPort   port.Inputter
 	DynamicBuilder port.DynamicBuilder
 	GRPCClient     entity.GRPCClient
+
+	// Recorder records each RPC call if it isn't nil.
+	Recorder port.Recorder
+
+	// ResponseMarshaler marshals responses which are filtered or kept as variables.
+	// It should have the same options as OutputPort. See port.CallParams.
+	ResponseMarshaler *jsonpb.Marshaler
 }
 
 func (p *InteractorParams) Cleanup(ctx context.Context) error {
@@ -46,6 +58,9 @@ func NewInteractor(params *InteractorParams) *Interactor {
 		inputterPort:   params.InputterPort,
 		grpcPort:       params.GRPCClient,
 		dynamicBuilder: params.DynamicBuilder,
+		recorder:       params.Recorder,
+
+		responseMarshaler: params.ResponseMarshaler,
 	}
 }
 
@@ -69,6 +84,51 @@ func (i *Interactor) Header(params *port.HeaderParams) (io.Reader, error) {
 	return Header(params, i.outputPort, i.env)
 }
 
+func (i *Interactor) Save(params *port.SaveParams) (io.Reader, error) {
+	return Save(params, i.outputPort, i.env)
+}
+
 func (i *Interactor) Call(params *port.CallParams) (io.Reader, error) {
-	return Call(params, i.outputPort, i.inputterPort, i.grpcPort, i.dynamicBuilder, i.env)
+	if params.Marshaler == nil {
+		p := *params
+		p.Marshaler = i.responseMarshaler
+		params = &p
+	}
+	if params.Script != nil {
+		return callScript(params, i.outputPort, i.grpcPort, i.dynamicBuilder, i.env)
+	}
+	inputter := i.inputterFor(params.RPCName)
+	if params.Inputter != nil {
+		inputter = params.Inputter
+	}
+	if i.recorder != nil {
+		return recordCall(params, i.recorder, i.outputPort, inputter, i.grpcPort, i.dynamicBuilder, i.env)
+	}
+	return Call(params, i.outputPort, inputter, i.grpcPort, i.dynamicBuilder, i.env)
+}
+
+func (i *Interactor) Bench(params *port.BenchParams) (io.Reader, error) {
+	return Bench(params, i.outputPort, i.inputterFor(params.RPCName), i.grpcPort, i.dynamicBuilder, i.env)
+}
+
+// inputterFor returns the default inputter which is bound to the RPC if it is a port.RPCInputter.
+func (i *Interactor) inputterFor(rpcName string) port.Inputter {
+	ri, ok := i.inputterPort.(port.RPCInputter)
+	if !ok {
+		return i.inputterPort
+	}
+	rpc, err := i.env.RPC(rpcName)
+	if err != nil {
+		// the error is reported by the usecase.
+		return i.inputterPort
+	}
+	return ri.ForRPC(rpc.FQRN())
+}
+
+func (i *Interactor) Replay(params *port.ReplayParams) (io.Reader, error) {
+	return Replay(params, i.outputPort, i.grpcPort, i.dynamicBuilder, i.env)
+}
+
+func (i *Interactor) Test(params *port.TestParams) (io.Reader, error) {
+	return RunTests(params, i.outputPort, i.grpcPort, i.dynamicBuilder, i.env)
 }
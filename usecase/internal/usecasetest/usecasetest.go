@@ -2,10 +2,14 @@ package usecasetest
 
 import (
 	"io"
+	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
 	"github.com/ktr0731/evans/usecase/port"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func NewPresenter() port.OutputPort {
@@ -16,6 +20,9 @@ func NewPresenter() port.OutputPort {
 		HeaderFunc: func() (io.Reader, error) {
 			return nil, nil
 		},
+		SaveFunc: func() (io.Reader, error) {
+			return nil, nil
+		},
 		PackageFunc: func() (io.Reader, error) {
 			return nil, nil
 		},
@@ -26,7 +33,37 @@ func NewPresenter() port.OutputPort {
 			return nil, nil
 		},
 		CallFunc: func(res proto.Message) (io.Reader, error) {
-			return nil, nil
+			return strings.NewReader(""), nil
+		},
+		CallFilteredFunc: func(v interface{}) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		CallHeaderFunc: func(header metadata.MD) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		CallTrailerFunc: func(trailer metadata.MD) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		CallElapsedFunc: func(elapsed time.Duration) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		CallErrorFunc: func(st *status.Status) (io.Reader, error) {
+			return strings.NewReader(st.Message()), nil
+		},
+		CallReceivedFunc: func(seq int, receivedAt time.Time) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		CallStreamSummaryFunc: func(summary *port.StreamSummary) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		BenchFunc: func(report *port.BenchReport) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		ReplayFunc: func(report *port.ReplayReport) (io.Reader, error) {
+			return strings.NewReader(""), nil
+		},
+		TestFunc: func(report *port.TestReport) (io.Reader, error) {
+			return strings.NewReader(""), nil
 		},
 	}
 }
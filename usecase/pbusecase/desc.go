@@ -12,5 +12,8 @@ func Describe(params *port.DescribeParams, outputPort port.OutputPort, env env.E
 	if err != nil {
 		return nil, err
 	}
+	if params.Template {
+		return outputPort.Describe(&messageTemplate{msg})
+	}
 	return outputPort.Describe(&message{msg})
 }
@@ -2,6 +2,7 @@ package pbusecase
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 
@@ -36,6 +37,13 @@ func Show(params *port.ShowParams, outputPort port.OutputPort, env env.Environme
 		showable = rpcs(r)
 	case port.ShowTypeHeader:
 		showable = headers(env.Headers())
+	case port.ShowTypeVariable:
+		vars := variables{}
+		for _, name := range env.VariableNames() {
+			v, _ := env.Variable(name)
+			vars = append(vars, &variable{name: name, val: v})
+		}
+		showable = vars
 	default:
 		return nil, errors.New("unknown showable type")
 	}
@@ -154,3 +162,29 @@ func (h headers) Show() string {
 
 	return buf.String()
 }
+
+type variable struct {
+	name string
+	val  interface{}
+}
+
+type variables []*variable
+
+func (v variables) Show() string {
+	buf := new(bytes.Buffer)
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"name", "val"})
+	rows := [][]string{}
+	for _, variable := range v {
+		b, err := json.Marshal(variable.val)
+		if err != nil {
+			b = []byte(err.Error())
+		}
+		row := []string{variable.name, string(b)}
+		rows = append(rows, row)
+	}
+	table.AppendBulk(rows)
+	table.Render()
+
+	return buf.String()
+}
@@ -0,0 +1,218 @@
+package pbusecase
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ktr0731/evans/entity"
+)
+
+const templateIndent = "  "
+
+// messageTemplate shows a JSON template of the message. See MessageTemplate.
+type messageTemplate struct {
+	entity.Message
+}
+
+func (m *messageTemplate) Show() string {
+	return MessageTemplate(m.Message)
+}
+
+// MessageTemplate returns an example JSON document of m.
+// Each field is populated with a placeholder which is the default value of its type,
+// and repeated and map fields have one element. One branch of each oneof is chosen.
+// Fields of circulated messages are cut off at the second occurrence.
+//
+// Each field also has a comment which describes its type, enum values or oneof choices.
+// Comments are lines which start with "//". They are ignored by JSON inputs.
+func MessageTemplate(m entity.Message) string {
+	w := &templateWriter{ancestors: map[string]bool{}}
+	w.writeMessage(m, 0)
+	w.buf.WriteByte('\n')
+	return w.buf.String()
+}
+
+type templateWriter struct {
+	buf bytes.Buffer
+
+	// ancestors holds names of messages which are being written.
+	ancestors map[string]bool
+}
+
+func (w *templateWriter) indent(depth int) {
+	w.buf.WriteString(strings.Repeat(templateIndent, depth))
+}
+
+func (w *templateWriter) writeMessage(m entity.Message, depth int) {
+	fields := m.Fields()
+	if len(fields) == 0 {
+		w.buf.WriteString("{}")
+		return
+	}
+
+	w.ancestors[m.Name()] = true
+	defer delete(w.ancestors, m.Name())
+
+	w.buf.WriteString("{\n")
+	for n, f := range fields {
+		if oneof, ok := f.(entity.OneOfField); ok {
+			choices := oneof.Choices()
+			names := make([]string, 0, len(choices))
+			for _, c := range choices {
+				names = append(names, c.FieldName())
+			}
+			w.indent(depth + 1)
+			fmt.Fprintf(&w.buf, "// oneof %s: only one of %s can be set\n", oneof.FieldName(), strings.Join(names, ", "))
+			if len(choices) == 0 {
+				continue
+			}
+			f = choices[0]
+		}
+		w.indent(depth + 1)
+		fmt.Fprintf(&w.buf, "// %s\n", w.describe(f))
+		w.indent(depth + 1)
+		fmt.Fprintf(&w.buf, "%q: ", f.FieldName())
+		w.writeField(f, depth+1)
+		if n != len(fields)-1 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.WriteByte('\n')
+	}
+	w.indent(depth)
+	w.buf.WriteByte('}')
+}
+
+func (w *templateWriter) writeField(f entity.Field, depth int) {
+	if w.isCirculated(f) {
+		if f.IsRepeated() {
+			w.buf.WriteString("[]")
+		} else {
+			w.buf.WriteString("{}")
+		}
+		return
+	}
+	if m, ok := f.(entity.MapField); ok {
+		w.buf.WriteString("{\n")
+		w.indent(depth + 1)
+		fmt.Fprintf(&w.buf, "%q: ", mapKeyPlaceholder(m.Key()))
+		w.writeField(m.Value(), depth+1)
+		w.buf.WriteByte('\n')
+		w.indent(depth)
+		w.buf.WriteByte('}')
+		return
+	}
+	if !f.IsRepeated() {
+		w.writeValue(f, depth)
+		return
+	}
+	w.buf.WriteString("[\n")
+	w.indent(depth + 1)
+	w.writeValue(f, depth+1)
+	w.buf.WriteByte('\n')
+	w.indent(depth)
+	w.buf.WriteByte(']')
+}
+
+func (w *templateWriter) writeValue(f entity.Field, depth int) {
+	switch f := f.(type) {
+	case entity.EnumField:
+		if vals := f.Values(); len(vals) != 0 {
+			fmt.Fprintf(&w.buf, "%q", vals[0].Name())
+			return
+		}
+		w.buf.WriteString("0")
+	case entity.MessageField:
+		// FieldMask is written as a normal message because its JSON mapping
+		// is not supported by JSON inputs.
+		if wkt := f.WellKnownType(); wkt != "" && wkt != "google.protobuf.FieldMask" {
+			w.writeWellKnownValue(f)
+			return
+		}
+		w.writeMessage(f, depth)
+	default:
+		w.writePrimitiveValue(f)
+	}
+}
+
+func (w *templateWriter) writePrimitiveValue(f entity.Field) {
+	switch f.PBType() {
+	case "TYPE_STRING", "TYPE_BYTES":
+		w.buf.WriteString(`""`)
+	case "TYPE_BOOL":
+		w.buf.WriteString("false")
+	default:
+		w.buf.WriteString("0")
+	}
+}
+
+// writeWellKnownValue writes a placeholder of the well-known type in its JSON mapping.
+func (w *templateWriter) writeWellKnownValue(f entity.MessageField) {
+	switch f.WellKnownType() {
+	case "google.protobuf.Timestamp":
+		w.buf.WriteString(`"1970-01-01T00:00:00Z"`)
+	case "google.protobuf.Duration":
+		w.buf.WriteString(`"0s"`)
+	case "google.protobuf.Struct":
+		w.buf.WriteString("{}")
+	case "google.protobuf.ListValue":
+		w.buf.WriteString("[]")
+	case "google.protobuf.Value":
+		w.buf.WriteString("null")
+	default: // wrapper types
+		if fields := f.Fields(); len(fields) != 0 {
+			w.writePrimitiveValue(fields[0])
+			return
+		}
+		w.buf.WriteString("null")
+	}
+}
+
+// mapKeyPlaceholder returns a placeholder of the map key f. JSON object keys are always strings.
+func mapKeyPlaceholder(f entity.Field) string {
+	switch f.PBType() {
+	case "TYPE_STRING":
+		return ""
+	case "TYPE_BOOL":
+		return "false"
+	default:
+		return "0"
+	}
+}
+
+// isCirculated returns whether f is a field of a circulated message which is already being written.
+func (w *templateWriter) isCirculated(f entity.Field) bool {
+	m, ok := f.(entity.MessageField)
+	return ok && m.IsCycled() && w.ancestors[m.Name()]
+}
+
+// describe returns the comment of f.
+func (w *templateWriter) describe(f entity.Field) string {
+	var s string
+	if f.IsRepeated() {
+		s = "repeated "
+	}
+	s += f.PBType()
+	if m, ok := f.(entity.MapField); ok {
+		f = m.Value()
+	}
+	switch f := f.(type) {
+	case entity.EnumField:
+		vals := f.Values()
+		names := make([]string, 0, len(vals))
+		for _, v := range vals {
+			names = append(names, fmt.Sprintf("%s = %d", v.Name(), v.Number()))
+		}
+		s += ": " + strings.Join(names, ", ")
+	case entity.MessageField:
+		if f.WellKnownType() != "" {
+			s += " " + f.WellKnownType()
+			break
+		}
+		s += " " + f.Name()
+		if w.isCirculated(f) {
+			s += " (circulated)"
+		}
+	}
+	return s
+}
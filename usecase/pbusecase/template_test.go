@@ -0,0 +1,76 @@
+package pbusecase
+
+import (
+	"testing"
+
+	"github.com/ktr0731/evans/adapter/presenter"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/tests/helper"
+	"github.com/ktr0731/evans/tests/mock/entity/mockenv"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe_template(t *testing.T) {
+	pkgs := helper.ReadProto(t, "template.proto")
+	var msg entity.Message
+	for _, pkg := range pkgs {
+		for _, m := range pkg.Messages {
+			if pkg.Name == "example" && m.Name() == "CreateUserRequest" {
+				msg = m
+			}
+		}
+	}
+	require.NotNil(t, msg)
+
+	params := &port.DescribeParams{MsgName: "CreateUserRequest", Template: true}
+	env := &mockenv.EnvironmentMock{
+		MessageFunc: func(name string) (entity.Message, error) { return msg, nil },
+	}
+
+	res, err := Describe(params, presenter.NewJSON(), env)
+	require.NoError(t, err)
+
+	expected := `{
+  // oneof contact: only one of email, phone can be set
+  // TYPE_STRING
+  "email": "",
+  // TYPE_STRING
+  "name": "",
+  // TYPE_ENUM: MEMBER = 0, ADMIN = 1
+  "role": "MEMBER",
+  // repeated TYPE_STRING
+  "tags": [
+    ""
+  ],
+  // TYPE_MESSAGE Profile
+  "profile": {
+    // TYPE_INT64
+    "age": 0,
+    // TYPE_BOOL
+    "active": false
+  },
+  // TYPE_MESSAGE Filter
+  "filter": {
+    // TYPE_STRING
+    "name": "",
+    // repeated TYPE_MESSAGE Filter (circulated)
+    "and": []
+  },
+  // map<TYPE_STRING, TYPE_MESSAGE> Profile
+  "profiles": {
+    "": {
+      // TYPE_INT64
+      "age": 0,
+      // TYPE_BOOL
+      "active": false
+    }
+  },
+  // TYPE_MESSAGE google.protobuf.Timestamp
+  "created_at": "1970-01-01T00:00:00Z",
+  // TYPE_MESSAGE google.protobuf.Int32Value
+  "age": 0
+}
+`
+	require.Equal(t, expected, helper.ReadAllAsStr(t, res))
+}
@@ -0,0 +1,42 @@
+package port
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// BenchReport is the result of a load test.
+type BenchReport struct {
+	// Count is the number of finished RPC calls.
+	Count int
+	// Total is the time which is taken to finish all RPC calls.
+	Total time.Duration
+	// RPS is the number of finished RPC calls per second.
+	RPS float64
+
+	Fastest time.Duration
+	Slowest time.Duration
+	Average time.Duration
+
+	// Latencies is the latency distribution sorted by the percentile.
+	Latencies []*BenchLatency
+	// Histogram is the latency histogram sorted by the mark.
+	Histogram []*BenchBucket
+	// StatusCodes is the number of RPC calls per status code.
+	StatusCodes map[codes.Code]int
+}
+
+// BenchLatency represents that Percentile percent of RPC calls finished in Latency.
+type BenchLatency struct {
+	Percentile int
+	Latency    time.Duration
+}
+
+// BenchBucket is a bucket of the latency histogram.
+// It has RPC calls which finished in Mark and didn't finish in the mark of the previous bucket.
+type BenchBucket struct {
+	Mark      time.Duration
+	Count     int
+	Frequency float64
+}
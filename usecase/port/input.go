@@ -2,7 +2,9 @@ package port
 
 import (
 	"io"
+	"time"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/ktr0731/evans/entity"
 )
 
@@ -15,15 +17,106 @@ type InputPort interface {
 
 	Header(*HeaderParams) (io.Reader, error)
 
+	Save(*SaveParams) (io.Reader, error)
+
 	Call(*CallParams) (io.Reader, error)
+
+	Bench(*BenchParams) (io.Reader, error)
+
+	Replay(*ReplayParams) (io.Reader, error)
+
+	Test(*TestParams) (io.Reader, error)
 }
 
 type CallParams struct {
 	RPCName string
+
+	// Timeout is the deadline of the RPC call.
+	// If it is zero, the RPC call doesn't have any deadline.
+	Timeout time.Duration
+
+	// Stream controls receiving responses of server streaming and bidirectional streaming RPCs.
+	Stream StreamParams
+
+	// Script describes requests of a bidirectional streaming RPC instead of the inputter.
+	// If it is nil, requests are inputted by the inputter.
+	Script *BidiScript
+
+	// Filter is a jq-style expression like ".items[].name" which extracts values from each response.
+	// If it is empty, responses are formatted as it is.
+	Filter string
+
+	// Inputter inputs requests instead of the default inputter of the InputPort.
+	// It is ignored if Script is specified.
+	Inputter Inputter
+
+	// Marshaler marshals responses which are filtered or kept as variables.
+	// It should have the same options as the OutputPort. If it is nil, the default options are used.
+	Marshaler *jsonpb.Marshaler
+
+	// Compression is the name of the compressor which compresses request messages of the RPC call.
+	// If it is empty, the compressor of the gRPC client is used.
+	Compression string
+}
+
+// StreamParams is the parameters to receive responses of streaming RPCs.
+type StreamParams struct {
+	// MaxMessages is the number of messages which are received before stopping the stream.
+	// If it is zero, messages are received until the stream is closed.
+	MaxMessages int
+	// Duration is the duration of receiving messages. Unlike the timeout, the stream is
+	// stopped without any error when the duration is elapsed. If it is zero, there is no limit.
+	Duration time.Duration
+	// ShowTimestamp enables to output the sequence number and the receive time of each message.
+	ShowTimestamp bool
+	// ShowSummary enables to output the summary when the stream is finished.
+	ShowSummary bool
+}
+
+// BenchParams is the parameters of a load test.
+type BenchParams struct {
+	RPCName string
+
+	// Concurrency is the number of workers which call the RPC concurrently.
+	Concurrency int
+	// Count is the total number of RPC calls.
+	Count int
+	// Duration is the duration of the load test.
+	// If both of Count and Duration are specified, the load test finishes
+	// when either of them is reached.
+	Duration time.Duration
+	// QPS is the rate limit of RPC calls per second in total.
+	// If it is zero, RPC calls are not limited.
+	QPS int
+	// Timeout is the deadline of each RPC call.
+	Timeout time.Duration
+}
+
+// ReplayParams is the parameters to replay a recorded session.
+type ReplayParams struct {
+	// Calls are the recorded RPC calls. They are replayed in order.
+	Calls []*RecordedCall
+
+	// Timeout is the deadline of each RPC call.
+	Timeout time.Duration
+}
+
+// TestParams is the parameters to run a test suite.
+type TestParams struct {
+	Suite *TestSuite
+
+	// Timeout is the default deadline of each RPC call.
+	Timeout time.Duration
+
+	// Reporters receive the result in addition to OutputPort.
+	Reporters []TestReporter
 }
 
 type DescribeParams struct {
 	MsgName string
+
+	// Template shows an example JSON document of the message instead of its structure.
+	Template bool
 }
 
 type PackageParams struct {
@@ -42,6 +135,7 @@ const (
 	ShowTypeMessage
 	ShowTypeRPC
 	ShowTypeHeader
+	ShowTypeVariable
 )
 
 type Showable interface {
@@ -55,3 +149,8 @@ type ShowParams struct {
 type HeaderParams struct {
 	Headers []*entity.Header
 }
+
+// SaveParams is the parameters to save the last response as a variable.
+type SaveParams struct {
+	Name string
+}
@@ -8,3 +8,12 @@ import (
 type Inputter interface {
 	Input(reqMsg entity.Message) (proto.Message, error)
 }
+
+// RPCInputter is an Inputter which inputs requests differently per RPC.
+// For example, the prompt inputter remembers the last input values of each RPC.
+type RPCInputter interface {
+	Inputter
+
+	// ForRPC returns an Inputter which inputs requests of the RPC. rpcName is the fully-qualified name of the RPC.
+	ForRPC(rpcName string) Inputter
+}
@@ -2,8 +2,11 @@ package port
 
 import (
 	"io"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type OutputPort interface {
@@ -12,5 +15,32 @@ type OutputPort interface {
 	Describe(showable Showable) (io.Reader, error)
 	Show(showable Showable) (io.Reader, error)
 	Header() (io.Reader, error)
+	Save() (io.Reader, error)
 	Call(res proto.Message) (io.Reader, error)
+	// CallFiltered formats a value which is extracted from a response by a filter.
+	// v is a decoded JSON value. Numbers are represented as json.Number.
+	CallFiltered(v interface{}) (io.Reader, error)
+
+	// CallHeader and CallTrailer format the header and trailer metadata which are
+	// received from the server. They are called with each RPC call.
+	CallHeader(header metadata.MD) (io.Reader, error)
+	CallTrailer(trailer metadata.MD) (io.Reader, error)
+
+	// CallElapsed formats the time which is taken to call a RPC.
+	CallElapsed(elapsed time.Duration) (io.Reader, error)
+
+	// CallError formats the status of a failed RPC call.
+	CallError(st *status.Status) (io.Reader, error)
+
+	// CallReceived formats the sequence number and the receive time of a message
+	// which is received from a stream. It is called before Call.
+	CallReceived(seq int, receivedAt time.Time) (io.Reader, error)
+	// CallStreamSummary formats the summary of a stream when the stream is finished.
+	CallStreamSummary(summary *StreamSummary) (io.Reader, error)
+
+	Bench(report *BenchReport) (io.Reader, error)
+
+	Replay(report *ReplayReport) (io.Reader, error)
+
+	Test(report *TestReport) (io.Reader, error)
 }
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BidiScript describes a sequence of sends interleaved with waits for a bidirectional streaming RPC.
+// Steps are processed in order. After the last step, the client closes sending.
+type BidiScript struct {
+	Steps []*BidiStep
+}
+
+// BidiStep is a step of BidiScript. Exactly one of the fields is set.
+type BidiStep struct {
+	// Send is a request message which is sent to the server.
+	Send json.RawMessage
+	// Sleep is a fixed delay before the next step.
+	Sleep time.Duration
+	// Receive waits for the number of responses.
+	Receive int
+	// Match waits for a response which satisfies the matcher. Index of the matcher is ignored.
+	Match *FieldMatcher
+}
@@ -0,0 +1,55 @@
+package port
+
+import "encoding/json"
+
+// RecordedCall is a RPC call which is recorded in a session.
+// A session is a sequence of RecordedCall.
+type RecordedCall struct {
+	// Package and Service are the package and the service which the RPC belongs to.
+	Package string `json:"package"`
+	Service string `json:"service"`
+	RPC     string `json:"rpc"`
+
+	// Headers are the headers which were set when the RPC was called.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Requests and Responses are JSON encoded messages.
+	// Unary RPCs have only one request and one response.
+	Requests  []json.RawMessage `json:"requests"`
+	Responses []json.RawMessage `json:"responses"`
+
+	// Status is the status of the RPC call.
+	// If the RPC call finished successfully, it is nil.
+	Status *RecordedStatus `json:"status,omitempty"`
+}
+
+// RecordedStatus is the gRPC status of a recorded RPC call.
+type RecordedStatus struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Recorder records RPC calls.
+type Recorder interface {
+	Record(call *RecordedCall) error
+}
+
+// ReplayReport is the result of replaying a session.
+type ReplayReport struct {
+	Passed  int
+	Failed  int
+	Results []*ReplayResult
+}
+
+// ReplayResult is the result of replaying a recorded RPC call.
+type ReplayResult struct {
+	Call *RecordedCall
+	// Diffs describe differences between the recorded call and the replayed call.
+	// If the replayed call has the same responses and status, Diffs is empty.
+	Diffs []string
+}
+
+// Passed returns whether the replayed call matched with the recorded one.
+func (r *ReplayResult) Passed() bool {
+	return len(r.Diffs) == 0
+}
@@ -0,0 +1,27 @@
+package port
+
+import (
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// StreamSummary is the summary of responses which are received from a server streaming
+// or bidirectional streaming RPC.
+type StreamSummary struct {
+	// Count is the number of received messages.
+	Count int
+	// Bytes is the total size of received messages in the wire format.
+	Bytes int
+	// Elapsed is the time from starting the stream to finishing receiving.
+	Elapsed time.Duration
+	// Rate is the number of received messages per second.
+	Rate float64
+
+	// Status is the final status of the stream.
+	// It is nil if receiving was stopped by the client
+	// (e.g. the message limit was reached or interrupted).
+	Status  *status.Status
+	Trailer metadata.MD
+}
@@ -0,0 +1,93 @@
+package port
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TestSuite is a list of RPC calls and their expected results.
+type TestSuite struct {
+	Name  string
+	Cases []*TestCase
+}
+
+// TestCase is a RPC call and its expected result.
+type TestCase struct {
+	Name string
+
+	// Package, Service and RPC are the location of the RPC.
+	Package string
+	Service string
+	RPC     string
+
+	// Headers are set to the RPC call in addition to the default headers.
+	Headers map[string]string
+
+	// Requests are JSON encoded request messages.
+	// Unary and server streaming RPCs have only one request.
+	Requests []json.RawMessage
+
+	// Timeout is the deadline of the RPC call.
+	// If it is zero, the default timeout is used.
+	Timeout time.Duration
+
+	Expect *TestExpectation
+}
+
+// TestExpectation is the expected result of a TestCase.
+type TestExpectation struct {
+	// Code is the name of the expected status code such as "OK" or "NotFound".
+	Code string
+	// Message is the expected status message. If it is empty, the message is not checked.
+	Message string
+
+	// Responses are JSON objects which must be subsets of received responses.
+	// Responses[i] is compared with the i-th received response.
+	Responses []json.RawMessage
+
+	// Fields are matchers for fields of received responses.
+	Fields []*FieldMatcher
+}
+
+// FieldMatcher asserts a field of a received response.
+// Either Equals or Regex must be specified.
+type FieldMatcher struct {
+	// Index is the index of the response which has the field.
+	Index int
+	// Path is a dot-separated path to the field such as "items.0.name".
+	Path string
+
+	// Equals is the expected JSON encoded value of the field.
+	Equals json.RawMessage
+	// Regex is a regular expression which the field must match.
+	// If the field isn't a string, it is matched with its JSON representation.
+	Regex string
+}
+
+// TestReporter receives the result of a test suite.
+// It is used to write the result in other formats such as JUnit XML.
+type TestReporter interface {
+	Report(report *TestReport) error
+}
+
+// TestReport is the result of a test suite.
+type TestReport struct {
+	Name    string
+	Passed  int
+	Failed  int
+	Elapsed time.Duration
+	Results []*TestResult
+}
+
+// TestResult is the result of a TestCase.
+type TestResult struct {
+	Case    *TestCase
+	Elapsed time.Duration
+	// Failures describe assertions which were not satisfied.
+	Failures []string
+}
+
+// Passed returns whether all assertions of the test case were satisfied.
+func (r *TestResult) Passed() bool {
+	return len(r.Failures) == 0
+}
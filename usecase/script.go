@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+var errStreamClosed = errors.New("the stream was closed")
+
+// callScript calls a bidirectional streaming RPC. Requests are sent according to params.Script
+// instead of the inputter.
+func callScript(
+	params *port.CallParams,
+	outputPort port.OutputPort,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) (io.Reader, error) {
+	rpc, err := env.RPC(params.RPCName)
+	if err != nil {
+		return nil, err
+	}
+	if !rpc.IsClientStreaming() || !rpc.IsServerStreaming() {
+		return nil, errors.Errorf("scripts are supported only by bidirectional streaming RPCs, but %s is not", rpc.Name())
+	}
+
+	// the script matches responses which are not filtered.
+	params, outputPort, err = withFilter(params, outputPort)
+	if err != nil {
+		return nil, err
+	}
+	inputter := newScriptInputter(params.Script, builder)
+	r, err := Call(
+		params,
+		&scriptOutputPort{OutputPort: outputPort, inputter: inputter},
+		inputter,
+		grpcClient,
+		builder,
+		env,
+	)
+	if err != nil {
+		inputter.close()
+		return nil, err
+	}
+	return &scriptReader{r: r, inputter: inputter}, nil
+}
+
+// scriptInputter inputs requests according to a script.
+// Wait steps consume received responses in order, so the following wait steps don't see them.
+// After all steps are processed, it returns io.EOF.
+type scriptInputter struct {
+	steps   []*port.BidiStep
+	builder port.DynamicBuilder
+
+	mu sync.Mutex
+	// responses are received responses which are not consumed by wait steps yet.
+	responses []interface{}
+	// received is notified when a response is received.
+	received chan struct{}
+
+	// done is closed when the stream is finished.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newScriptInputter(script *port.BidiScript, builder port.DynamicBuilder) *scriptInputter {
+	return &scriptInputter{
+		steps:    script.Steps,
+		builder:  builder,
+		received: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+func (i *scriptInputter) Input(reqType entity.Message) (proto.Message, error) {
+	for len(i.steps) != 0 {
+		step := i.steps[0]
+		i.steps = i.steps[1:]
+
+		switch {
+		case step.Send != nil:
+			req := i.builder.NewMessage(reqType)
+			if err := jsonpb.Unmarshal(bytes.NewReader(step.Send), req); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal the request in the script")
+			}
+			return req, nil
+		case step.Sleep > 0:
+			select {
+			case <-time.After(step.Sleep):
+			case <-i.done:
+				return nil, errStreamClosed
+			}
+		case step.Receive > 0:
+			for n := 0; n < step.Receive; n++ {
+				if _, err := i.next(); err != nil {
+					return nil, errors.Wrapf(err, "failed to wait for %d responses", step.Receive)
+				}
+			}
+		case step.Match != nil:
+			m := *step.Match
+			m.Index = 0
+			for {
+				res, err := i.next()
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to wait for a response which matches with '%s'", m.Path)
+				}
+				if matchField(&m, []interface{}{res}) == "" {
+					break
+				}
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+// next returns the oldest response which is not consumed yet.
+// If there is no such response, next blocks until a response is received or the stream is finished.
+func (i *scriptInputter) next() (interface{}, error) {
+	for {
+		if res, ok := i.pop(); ok {
+			return res, nil
+		}
+		select {
+		case <-i.received:
+		case <-i.done:
+			// a response may be received just before the stream is finished.
+			if res, ok := i.pop(); ok {
+				return res, nil
+			}
+			return nil, errStreamClosed
+		}
+	}
+}
+
+func (i *scriptInputter) pop() (interface{}, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.responses) == 0 {
+		return nil, false
+	}
+	res := i.responses[0]
+	i.responses = i.responses[1:]
+	return res, true
+}
+
+func (i *scriptInputter) addResponse(res proto.Message) {
+	b, err := marshalMessage(res)
+	if err != nil {
+		logger.Printf("failed to marshal the response message: %s", err)
+		return
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		logger.Printf("failed to unmarshal the response message: %s", err)
+		return
+	}
+
+	i.mu.Lock()
+	i.responses = append(i.responses, v)
+	i.mu.Unlock()
+
+	select {
+	case i.received <- struct{}{}:
+	default:
+	}
+}
+
+func (i *scriptInputter) close() {
+	i.closeOnce.Do(func() {
+		close(i.done)
+	})
+}
+
+// scriptOutputPort passes received responses to the script.
+type scriptOutputPort struct {
+	port.OutputPort
+	inputter *scriptInputter
+}
+
+func (p *scriptOutputPort) Call(res proto.Message) (io.Reader, error) {
+	p.inputter.addResponse(res)
+	return p.OutputPort.Call(res)
+}
+
+// scriptReader finishes the script when the result is read to the end.
+type scriptReader struct {
+	r        io.Reader
+	inputter *scriptInputter
+}
+
+func (r *scriptReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil {
+		r.inputter.close()
+	}
+	return n, err
+}
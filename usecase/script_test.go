@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/testentity"
+	mockentity "github.com/ktr0731/evans/tests/mock/entity"
+	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// newEchoBidiStream returns a stream which echoes back each request.
+// The order of sent requests is recorded to sent.
+func newEchoBidiStream(mu *sync.Mutex, sent *[]string) *mockentity.BidiStreamMock {
+	ch := make(chan string, 10)
+	return &mockentity.BidiStreamMock{
+		SendFunc: func(req proto.Message) error {
+			v := req.(*wrappers.StringValue).GetValue()
+			mu.Lock()
+			*sent = append(*sent, v)
+			mu.Unlock()
+			ch <- v
+			return nil
+		},
+		ReceiveFunc: func(res *proto.Message) error {
+			v, ok := <-ch
+			if !ok {
+				return io.EOF
+			}
+			(*res).(*wrappers.StringValue).Value = v
+			return nil
+		},
+		CloseSendFunc: func() error { close(ch); return nil },
+		HeaderFunc:    func() (metadata.MD, error) { return nil, nil },
+		TrailerFunc:   func() metadata.MD { return nil },
+	}
+}
+
+func Test_callScript(t *testing.T) {
+	rpc := testentity.NewRPC()
+	rpc.FIsClientStreaming = true
+	rpc.FIsServerStreaming = true
+	env := newSessionEnv(t, rpc)
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+	grpcClient := newGRPCClient(t)
+	grpcClient.NewBidiStreamFunc = func(ctx context.Context, rpc entity.RPC) (entity.BidiStream, error) {
+		return newEchoBidiStream(&mu, &sent), nil
+	}
+
+	script := &port.BidiScript{
+		Steps: []*port.BidiStep{
+			{Send: json.RawMessage(`"foo"`)},
+			{Send: json.RawMessage(`"bar"`)},
+			{Receive: 2},
+			{Sleep: time.Millisecond},
+			{Send: json.RawMessage(`"baz"`)},
+			{Match: &port.FieldMatcher{Index: 3, Regex: "^ba"}},
+			{Send: json.RawMessage(`"qux"`)},
+		},
+	}
+	presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+	r, err := callScript(&port.CallParams{RPCName: "Bidi", Script: script}, presenter, grpcClient, builder, env)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, sent)
+	require.Len(t, presenter.CallCalls(), 4)
+
+	t.Run("with filter", func(t *testing.T) {
+		sent = nil
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		params := &port.CallParams{RPCName: "Bidi", Script: script, Filter: ". , ."}
+		r, err := callScript(params, presenter, grpcClient, builder, env)
+		require.NoError(t, err)
+		_, err = ioutil.ReadAll(r)
+		require.NoError(t, err)
+
+		// the script receives and matches responses which are not filtered.
+		assert.Equal(t, []string{"foo", "bar", "baz", "qux"}, sent)
+		assert.Len(t, presenter.CallFilteredCalls(), 8)
+		assert.Len(t, presenter.CallCalls(), 0)
+	})
+}
+
+func Test_scriptInputter(t *testing.T) {
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+
+	t.Run("wait for a matched response", func(t *testing.T) {
+		in := newScriptInputter(&port.BidiScript{Steps: []*port.BidiStep{
+			{Match: &port.FieldMatcher{Equals: json.RawMessage(`"bar"`)}},
+			{Send: json.RawMessage(`"baz"`)},
+		}}, builder)
+
+		go func() {
+			in.addResponse(&wrappers.StringValue{Value: "foo"})
+			in.addResponse(&wrappers.StringValue{Value: "bar"})
+		}()
+		req, err := in.Input(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "baz", req.(*wrappers.StringValue).GetValue())
+
+		_, err = in.Input(nil)
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("the stream is closed while waiting", func(t *testing.T) {
+		in := newScriptInputter(&port.BidiScript{Steps: []*port.BidiStep{{Receive: 2}}}, builder)
+		in.addResponse(&wrappers.StringValue{Value: "foo"})
+		in.close()
+
+		_, err := in.Input(nil)
+		assert.Error(t, err)
+	})
+}
+
+func Test_callScript_notBidi(t *testing.T) {
+	rpc := testentity.NewRPC()
+	rpc.FIsServerStreaming = true
+	_, err := callScript(
+		&port.CallParams{RPCName: "ServerStreaming", Script: &port.BidiScript{}},
+		usecasetest.NewPresenter(),
+		newGRPCClient(t),
+		newDynamicBuilder(t),
+		newSessionEnv(t, rpc),
+	)
+	assert.Error(t, err)
+}
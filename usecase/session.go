@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/status"
+)
+
+// recordCall calls the RPC same as Call, then records the call by recorder.
+// The call is recorded when the result is read to the end or the RPC finished with a gRPC status.
+// Canceled calls are not recorded.
+func recordCall(
+	params *port.CallParams,
+	recorder port.Recorder,
+	outputPort port.OutputPort,
+	inputter port.Inputter,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) (io.Reader, error) {
+	rpc, err := env.RPC(params.RPCName)
+	if err != nil {
+		return nil, err
+	}
+	c := &capture{call: newRecordedCall(env, rpc)}
+
+	// responses are recorded as they are even if the filter is specified.
+	params, outputPort, err = withFilter(params, outputPort)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Call(
+		params,
+		&capturingOutputPort{OutputPort: outputPort, capture: c},
+		&capturingInputter{Inputter: inputter, capture: c},
+		grpcClient,
+		builder,
+		env,
+	)
+	if err != nil {
+		if c.finishedWithStatus() {
+			c.record(recorder)
+		}
+		return nil, err
+	}
+	return &recordingReader{r: r, capture: c, recorder: recorder}, nil
+}
+
+// newRecordedCall returns a RecordedCall which has the location of rpc and the current headers.
+func newRecordedCall(env env.Environment, rpc entity.RPC) *port.RecordedCall {
+	call := &port.RecordedCall{
+		RPC:       rpc.Name(),
+		Headers:   map[string]string{},
+		Requests:  []json.RawMessage{},
+		Responses: []json.RawMessage{},
+	}
+	for _, pkg := range env.Packages() {
+		for _, svc := range pkg.Services {
+			for _, r := range svc.RPCs() {
+				if r.FQRN() == rpc.FQRN() {
+					call.Package, call.Service = pkg.Name, svc.Name()
+				}
+			}
+		}
+	}
+	for _, h := range env.Headers() {
+		call.Headers[h.Key] = h.Val
+	}
+	return call
+}
+
+// capture collects messages and the status of a RPC call.
+// Requests and responses may be collected from different goroutines in streaming RPCs.
+type capture struct {
+	mu   sync.Mutex
+	call *port.RecordedCall
+	once sync.Once
+}
+
+func (c *capture) addRequest(req proto.Message) {
+	b, err := marshalMessage(req)
+	if err != nil {
+		logger.Printf("failed to marshal the request message: %s", err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.call.Requests = append(c.call.Requests, b)
+}
+
+func (c *capture) addResponse(res proto.Message) {
+	b, err := marshalMessage(res)
+	if err != nil {
+		logger.Printf("failed to marshal the response message: %s", err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.call.Responses = append(c.call.Responses, b)
+}
+
+func (c *capture) setStatus(st *status.Status) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.call.Status = &port.RecordedStatus{Code: st.Code().String(), Message: st.Message()}
+}
+
+func (c *capture) finishedWithStatus() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.call.Status != nil
+}
+
+// record records the captured call only once.
+// A recording error doesn't fail the RPC call, so it is only logged.
+func (c *capture) record(recorder port.Recorder) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err := recorder.Record(c.call); err != nil {
+			logger.Printf("failed to record the RPC call: %s", err)
+		}
+	})
+}
+
+type capturingInputter struct {
+	port.Inputter
+	capture *capture
+}
+
+func (i *capturingInputter) Input(reqType entity.Message) (proto.Message, error) {
+	req, err := i.Inputter.Input(reqType)
+	if err != nil {
+		return nil, err
+	}
+	i.capture.addRequest(req)
+	return req, nil
+}
+
+type capturingOutputPort struct {
+	port.OutputPort
+	capture *capture
+}
+
+func (p *capturingOutputPort) Call(res proto.Message) (io.Reader, error) {
+	p.capture.addResponse(res)
+	return p.OutputPort.Call(res)
+}
+
+func (p *capturingOutputPort) CallError(st *status.Status) (io.Reader, error) {
+	p.capture.setStatus(st)
+	return p.OutputPort.CallError(st)
+}
+
+// recordingReader records the call when the result of the call is read to the end.
+type recordingReader struct {
+	r        io.Reader
+	capture  *capture
+	recorder port.Recorder
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF || (err != nil && r.capture.finishedWithStatus()) {
+		r.capture.record(r.recorder)
+	}
+	return n, err
+}
+
+// ReplayError is returned when some of replayed RPC calls didn't match with the recorded calls.
+// Error returns the report formatted by port.OutputPort.
+type ReplayError struct {
+	Report *port.ReplayReport
+
+	out string
+}
+
+func (e *ReplayError) Error() string {
+	return e.out
+}
+
+// Replay calls recorded RPCs in order with the recorded package, service, headers and requests.
+// Then, Replay compares responses and the status of each call with the recorded ones.
+// If some of them are different, Replay returns *ReplayError.
+func Replay(
+	params *port.ReplayParams,
+	outputPort port.OutputPort,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) (io.Reader, error) {
+	report := &port.ReplayReport{}
+	for i, call := range params.Calls {
+		diffs, err := replayCall(call, params.Timeout, outputPort, grpcClient, builder, env)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to replay the call #%d (%s)", i+1, call.RPC)
+		}
+		result := &port.ReplayResult{Call: call, Diffs: diffs}
+		if result.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	r, err := outputPort.Replay(report)
+	if err != nil {
+		return nil, err
+	}
+	if report.Failed == 0 {
+		return r, nil
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the formatted report")
+	}
+	return nil, &ReplayError{Report: report, out: strings.TrimRight(string(b), "\n")}
+}
+
+func replayCall(
+	expected *port.RecordedCall,
+	timeout time.Duration,
+	outputPort port.OutputPort,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) ([]string, error) {
+	rpc, err := useRPC(env, expected.Package, expected.Service, expected.RPC)
+	if err != nil {
+		return nil, err
+	}
+	defer setHeaders(env, expected.Headers, true)()
+
+	c := &capture{call: newRecordedCall(env, rpc)}
+	r, err := Call(
+		&port.CallParams{RPCName: expected.RPC, Timeout: timeout},
+		&capturingOutputPort{OutputPort: outputPort, capture: c},
+		&replayInputter{requests: expected.Requests, builder: builder},
+		grpcClient,
+		builder,
+		env,
+	)
+	if err == nil {
+		_, err = io.Copy(ioutil.Discard, r)
+	}
+	if err != nil && !c.finishedWithStatus() {
+		return nil, err
+	}
+	return diffCalls(expected, c.call), nil
+}
+
+// useRPC selects the package and the service, then returns the RPC.
+func useRPC(env env.Environment, pkg, svc, rpc string) (entity.RPC, error) {
+	if err := env.UsePackage(pkg); err != nil {
+		return nil, err
+	}
+	if err := env.UseService(svc); err != nil {
+		return nil, err
+	}
+	return env.RPC(rpc)
+}
+
+// setHeaders sets headers to env. If replace is true, the current headers are removed before that.
+// The returned function restores the previous headers.
+func setHeaders(env env.Environment, headers map[string]string, replace bool) func() {
+	prev := env.Headers()
+	if replace {
+		for _, h := range prev {
+			env.RemoveHeader(h.Key)
+		}
+	}
+	for k, v := range headers {
+		env.AddHeader(&entity.Header{Key: k, Val: v})
+	}
+	return func() {
+		for _, h := range env.Headers() {
+			env.RemoveHeader(h.Key)
+		}
+		for _, h := range prev {
+			env.AddHeader(h)
+		}
+	}
+}
+
+// replayInputter inputs recorded requests in order.
+// After all requests are inputted, it returns io.EOF.
+type replayInputter struct {
+	requests []json.RawMessage
+	builder  port.DynamicBuilder
+}
+
+func (i *replayInputter) Input(reqType entity.Message) (proto.Message, error) {
+	if len(i.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := i.builder.NewMessage(reqType)
+	if err := jsonpb.Unmarshal(bytes.NewReader(i.requests[0]), req); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the recorded request")
+	}
+	i.requests = i.requests[1:]
+	return req, nil
+}
+
+// diffCalls describes differences of responses and the status between expected and actual.
+func diffCalls(expected, actual *port.RecordedCall) []string {
+	var diffs []string
+	if len(expected.Responses) != len(actual.Responses) {
+		diffs = append(diffs, fmt.Sprintf("the number of responses: expected %d, but got %d", len(expected.Responses), len(actual.Responses)))
+	}
+	for i := 0; i < len(expected.Responses) && i < len(actual.Responses); i++ {
+		if !equalJSON(expected.Responses[i], actual.Responses[i]) {
+			diffs = append(diffs, fmt.Sprintf("responses[%d]: expected %s, but got %s", i, expected.Responses[i], actual.Responses[i]))
+		}
+	}
+	if !reflect.DeepEqual(expected.Status, actual.Status) {
+		diffs = append(diffs, fmt.Sprintf("status: expected %s, but got %s", formatRecordedStatus(expected.Status), formatRecordedStatus(actual.Status)))
+	}
+	return diffs
+}
+
+// equalJSON compares a and b ignoring the order of keys and spaces.
+func equalJSON(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func formatRecordedStatus(st *port.RecordedStatus) string {
+	if st == nil {
+		return "OK"
+	}
+	return fmt.Sprintf("%s (%q)", st.Code, st.Message)
+}
+
+// marshalMessage marshals m in JSON. Fields which have zero values are also emitted
+// so that recorded messages and expectations can refer to them.
+func marshalMessage(m proto.Message) (json.RawMessage, error) {
+	s, err := (&jsonpb.Marshaler{EmitDefaults: true}).MarshalToString(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(s), nil
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/testentity"
+	mockentity "github.com/ktr0731/evans/tests/mock/entity"
+	"github.com/ktr0731/evans/tests/mock/entity/mockenv"
+	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newSessionEnv(t *testing.T, rpc entity.RPC) *mockenv.EnvironmentMock {
+	svc := &mockentity.ServiceMock{
+		NameFunc: func() string { return "Example" },
+		RPCsFunc: func() []entity.RPC { return []entity.RPC{rpc} },
+	}
+	headers := map[string]string{"foo": "bar"}
+	return &mockenv.EnvironmentMock{
+		PackagesFunc: func() []*entity.Package {
+			return []*entity.Package{{Name: "api", Services: []entity.Service{svc}}}
+		},
+		RPCFunc: func(name string) (entity.RPC, error) { return rpc, nil },
+		HeadersFunc: func() []*entity.Header {
+			var hs []*entity.Header
+			for k, v := range headers {
+				hs = append(hs, &entity.Header{Key: k, Val: v})
+			}
+			return hs
+		},
+		AddHeaderFunc:    func(h *entity.Header) { headers[h.Key] = h.Val },
+		RemoveHeaderFunc: func(key string) { delete(headers, key) },
+		UsePackageFunc:   func(string) error { return nil },
+		UseServiceFunc:   func(string) error { return nil },
+		SetVariableFunc:  func(string, interface{}) {},
+	}
+}
+
+// newSessionGRPCClient returns a client which echoes back the request message.
+func newSessionGRPCClient(t *testing.T) *mockentity.GRPCClientMock {
+	c := newGRPCClient(t)
+	c.InvokeFunc = func(ctx context.Context, fqrn string, req, res interface{}) (metadata.MD, metadata.MD, error) {
+		v := req.(*wrappers.StringValue).GetValue()
+		if v == "" {
+			return nil, nil, status.Error(codes.InvalidArgument, "value is required")
+		}
+		res.(*wrappers.StringValue).Value = v
+		return nil, nil, nil
+	}
+	return c
+}
+
+type recorderMock struct {
+	calls []*port.RecordedCall
+}
+
+func (r *recorderMock) Record(call *port.RecordedCall) error {
+	r.calls = append(r.calls, call)
+	return nil
+}
+
+func Test_recordCall(t *testing.T) {
+	rpc := testentity.NewRPC()
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		recorder := &recorderMock{}
+		inputter := &mockport.InputterMock{
+			InputFunc: func(entity.Message) (proto.Message, error) { return &wrappers.StringValue{Value: "maho"}, nil },
+		}
+		r, err := recordCall(&port.CallParams{RPCName: rpc.Name()}, recorder, usecasetest.NewPresenter(), inputter, newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.NoError(t, err)
+		assert.Empty(t, recorder.calls, "the call must be recorded after the result is read")
+
+		_, err = ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Len(t, recorder.calls, 1)
+		expected := &port.RecordedCall{
+			Package:   "api",
+			Service:   "Example",
+			RPC:       rpc.Name(),
+			Headers:   map[string]string{"foo": "bar"},
+			Requests:  []json.RawMessage{json.RawMessage(`"maho"`)},
+			Responses: []json.RawMessage{json.RawMessage(`"maho"`)},
+		}
+		assert.Equal(t, expected, recorder.calls[0])
+	})
+
+	t.Run("with filter", func(t *testing.T) {
+		recorder := &recorderMock{}
+		inputter := &mockport.InputterMock{
+			InputFunc: func(entity.Message) (proto.Message, error) { return &wrappers.StringValue{Value: "maho"}, nil },
+		}
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		params := &port.CallParams{RPCName: rpc.Name(), Filter: ". , ."}
+		r, err := recordCall(params, recorder, presenter, inputter, newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.NoError(t, err)
+		_, err = ioutil.ReadAll(r)
+		require.NoError(t, err)
+
+		require.Len(t, recorder.calls, 1)
+		assert.Equal(t, []json.RawMessage{json.RawMessage(`"maho"`)}, recorder.calls[0].Responses, "responses must be recorded before they are filtered")
+		assert.Len(t, presenter.CallFilteredCalls(), 2)
+		assert.Len(t, presenter.CallCalls(), 0)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		recorder := &recorderMock{}
+		inputter := &mockport.InputterMock{
+			InputFunc: func(entity.Message) (proto.Message, error) { return &wrappers.StringValue{}, nil },
+		}
+		_, err := recordCall(&port.CallParams{RPCName: rpc.Name()}, recorder, usecasetest.NewPresenter(), inputter, newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.Error(t, err)
+		require.Len(t, recorder.calls, 1)
+		assert.Equal(t, &port.RecordedStatus{Code: "InvalidArgument", Message: "value is required"}, recorder.calls[0].Status)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		recorder := &recorderMock{}
+		inputter := &mockport.InputterMock{
+			InputFunc: func(entity.Message) (proto.Message, error) { return nil, io.EOF },
+		}
+		_, err := recordCall(&port.CallParams{RPCName: rpc.Name()}, recorder, usecasetest.NewPresenter(), inputter, newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.Error(t, err)
+		assert.Empty(t, recorder.calls)
+	})
+}
+
+func TestReplay(t *testing.T) {
+	rpc := testentity.NewRPC()
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+	newCall := func(req, res string, st *port.RecordedStatus) *port.RecordedCall {
+		c := &port.RecordedCall{
+			Package:   "api",
+			Service:   "Example",
+			RPC:       rpc.Name(),
+			Headers:   map[string]string{"hoge": "fuga"},
+			Requests:  []json.RawMessage{json.RawMessage(req)},
+			Responses: []json.RawMessage{},
+			Status:    st,
+		}
+		if res != "" {
+			c.Responses = append(c.Responses, json.RawMessage(res))
+		}
+		return c
+	}
+
+	t.Run("passed", func(t *testing.T) {
+		env := newSessionEnv(t, rpc)
+		grpcClient := newSessionGRPCClient(t)
+		params := &port.ReplayParams{
+			Calls: []*port.RecordedCall{
+				newCall(`"maho"`, `"maho"`, nil),
+				newCall(`""`, "", &port.RecordedStatus{Code: "InvalidArgument", Message: "value is required"}),
+			},
+		}
+		_, err := Replay(params, usecasetest.NewPresenter(), grpcClient, builder, env)
+		require.NoError(t, err)
+
+		require.Len(t, grpcClient.InvokeCalls(), 2)
+		md, ok := metadata.FromOutgoingContext(grpcClient.InvokeCalls()[0].Ctx)
+		require.True(t, ok)
+		assert.Equal(t, metadata.Pairs("hoge", "fuga"), md, "headers must be replaced with the recorded ones")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		presenter := usecasetest.NewPresenter().(*mockport.OutputPortMock)
+		params := &port.ReplayParams{
+			Calls: []*port.RecordedCall{
+				newCall(`"maho"`, `"kurisu"`, nil),
+				newCall(`"maho"`, "", &port.RecordedStatus{Code: "NotFound", Message: "not found"}),
+			},
+		}
+		_, err := Replay(params, presenter, newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.Error(t, err)
+		rerr, ok := err.(*ReplayError)
+		require.True(t, ok)
+		assert.Equal(t, 2, rerr.Report.Failed)
+		assert.Equal(t, []string{`responses[0]: expected "kurisu", but got "maho"`}, rerr.Report.Results[0].Diffs)
+		assert.Equal(t, []string{
+			"the number of responses: expected 0, but got 1",
+			`status: expected NotFound ("not found"), but got OK`,
+		}, rerr.Report.Results[1].Diffs)
+		require.Len(t, presenter.ReplayCalls(), 1)
+	})
+}
+
+func Test_equalJSON(t *testing.T) {
+	assert.True(t, equalJSON(json.RawMessage(`{"a": 1, "b": "c"}`), json.RawMessage(`{"b":"c","a":1}`)))
+	assert.False(t, equalJSON(json.RawMessage(`{"a": 1}`), json.RawMessage(`{"a": 2}`)))
+}
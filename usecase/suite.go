@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// TestError is returned when some of test cases failed.
+// Error returns the report formatted by port.OutputPort.
+type TestError struct {
+	Report *port.TestReport
+
+	out string
+}
+
+func (e *TestError) Error() string {
+	return e.out
+}
+
+// RunTests calls each RPC in the test suite in order, then checks its result.
+// The report is passed to params.Reporters and formatted by outputPort.
+// If some of test cases failed, RunTests returns *TestError.
+func RunTests(
+	params *port.TestParams,
+	outputPort port.OutputPort,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) (io.Reader, error) {
+	report := &port.TestReport{Name: params.Suite.Name}
+	start := time.Now()
+	for _, tc := range params.Suite.Cases {
+		result := runTestCase(tc, params.Timeout, outputPort, grpcClient, builder, env)
+		if result.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Elapsed = time.Since(start)
+
+	for _, r := range params.Reporters {
+		if err := r.Report(report); err != nil {
+			return nil, errors.Wrap(err, "failed to report the test result")
+		}
+	}
+
+	r, err := outputPort.Test(report)
+	if err != nil {
+		return nil, err
+	}
+	if report.Failed == 0 {
+		return r, nil
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the formatted report")
+	}
+	return nil, &TestError{Report: report, out: strings.TrimRight(string(b), "\n")}
+}
+
+// runTestCase calls the RPC of tc. Errors which are not gRPC statuses are regarded as failures.
+func runTestCase(
+	tc *port.TestCase,
+	timeout time.Duration,
+	outputPort port.OutputPort,
+	grpcClient entity.GRPCClient,
+	builder port.DynamicBuilder,
+	env env.Environment,
+) *port.TestResult {
+	result := &port.TestResult{Case: tc}
+	start := time.Now()
+	defer func() {
+		result.Elapsed = time.Since(start)
+	}()
+
+	rpc, err := useRPC(env, tc.Package, tc.Service, tc.RPC)
+	if err != nil {
+		result.Failures = []string{fmt.Sprintf("failed to find the RPC: %s", err)}
+		return result
+	}
+	defer setHeaders(env, tc.Headers, false)()
+
+	if tc.Timeout > 0 {
+		timeout = tc.Timeout
+	}
+
+	c := &capture{call: newRecordedCall(env, rpc)}
+	r, err := Call(
+		&port.CallParams{RPCName: tc.RPC, Timeout: timeout},
+		&capturingOutputPort{OutputPort: outputPort, capture: c},
+		&replayInputter{requests: tc.Requests, builder: builder},
+		grpcClient,
+		builder,
+		env,
+	)
+	if err == nil {
+		_, err = io.Copy(ioutil.Discard, r)
+	}
+	if err != nil && !c.finishedWithStatus() {
+		result.Failures = []string{fmt.Sprintf("failed to call the RPC: %s", err)}
+		return result
+	}
+
+	result.Failures = assertCall(tc.Expect, c.call)
+	return result
+}
+
+// assertCall checks the status and responses of actual.
+func assertCall(expect *port.TestExpectation, actual *port.RecordedCall) []string {
+	if expect == nil {
+		expect = &port.TestExpectation{}
+	}
+	var failures []string
+
+	code, msg := codes.OK.String(), ""
+	if actual.Status != nil {
+		code, msg = actual.Status.Code, actual.Status.Message
+	}
+	expectedCode := expect.Code
+	if expectedCode == "" {
+		expectedCode = codes.OK.String()
+	}
+	if code != expectedCode {
+		failures = append(failures, fmt.Sprintf("status code: expected %s, but got %s (%q)", expectedCode, code, msg))
+	}
+	if expect.Message != "" && msg != expect.Message {
+		failures = append(failures, fmt.Sprintf("status message: expected %q, but got %q", expect.Message, msg))
+	}
+
+	responses := make([]interface{}, len(actual.Responses))
+	for i, r := range actual.Responses {
+		if err := json.Unmarshal(r, &responses[i]); err != nil {
+			logger.Printf("failed to unmarshal the response: %s", err)
+		}
+	}
+
+	for i, r := range expect.Responses {
+		path := fmt.Sprintf("responses[%d]", i)
+		if i >= len(responses) {
+			failures = append(failures, fmt.Sprintf("%s: expected %s, but no response was received", path, r))
+			continue
+		}
+		var expected interface{}
+		if err := json.Unmarshal(r, &expected); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: invalid expected response: %s", path, err))
+			continue
+		}
+		failures = append(failures, matchSubset(path, expected, responses[i])...)
+	}
+
+	for _, m := range expect.Fields {
+		if f := matchField(m, responses); f != "" {
+			failures = append(failures, f)
+		}
+	}
+
+	return failures
+}
+
+// matchSubset checks whether expected is a subset of actual.
+// Objects may have extra keys in actual. Arrays must have the same length.
+func matchSubset(path string, expected, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, but got %s", path, formatJSON(actual))}
+		}
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var failures []string
+		for _, k := range keys {
+			failures = append(failures, matchSubset(path+"."+k, e[k], a[k])...)
+		}
+		return failures
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %s, but got %s", path, formatJSON(expected), formatJSON(actual))}
+		}
+		var failures []string
+		for i := range e {
+			failures = append(failures, matchSubset(fmt.Sprintf("%s[%d]", path, i), e[i], a[i])...)
+		}
+		return failures
+	default:
+		if !equalScalar(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %s, but got %s", path, formatJSON(expected), formatJSON(actual))}
+		}
+		return nil
+	}
+}
+
+// equalScalar compares JSON scalar values.
+// Numbers and strings are regarded as equal if they have the same representation
+// because 64-bit integers are encoded as strings.
+func equalScalar(expected, actual interface{}) bool {
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+	switch e := expected.(type) {
+	case float64:
+		a, ok := actual.(string)
+		return ok && a == strconv.FormatFloat(e, 'f', -1, 64)
+	case string:
+		a, ok := actual.(float64)
+		return ok && e == strconv.FormatFloat(a, 'f', -1, 64)
+	}
+	return false
+}
+
+// matchField returns a failure message if the field doesn't satisfy m.
+func matchField(m *port.FieldMatcher, responses []interface{}) string {
+	path := fmt.Sprintf("responses[%d].%s", m.Index, m.Path)
+	if m.Index < 0 || m.Index >= len(responses) {
+		return fmt.Sprintf("%s: no response was received", path)
+	}
+	v, ok := lookupField(responses[m.Index], m.Path)
+	if !ok {
+		return fmt.Sprintf("%s: the field is missing", path)
+	}
+
+	if m.Equals != nil {
+		var expected interface{}
+		if err := json.Unmarshal(m.Equals, &expected); err != nil {
+			return fmt.Sprintf("%s: invalid expected value: %s", path, err)
+		}
+		if failures := matchSubset(path, expected, v); len(failures) != 0 {
+			return failures[0]
+		}
+	}
+
+	if m.Regex != "" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return fmt.Sprintf("%s: invalid regex: %s", path, err)
+		}
+		s, ok := v.(string)
+		if !ok {
+			s = formatJSON(v)
+		}
+		if !re.MatchString(s) {
+			return fmt.Sprintf("%s: %q doesn't match with /%s/", path, s, m.Regex)
+		}
+	}
+	return ""
+}
+
+// lookupField finds the value which is pointed by a dot-separated path.
+// Array elements are specified by their indices like "items.0".
+func lookupField(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, k := range strings.Split(path, ".") {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			v, ok = o[k]
+			if !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(o) {
+				return nil, false
+			}
+			v = o[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func formatJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
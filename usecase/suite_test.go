@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/ktr0731/evans/entity"
+	"github.com/ktr0731/evans/entity/testentity"
+	"github.com/ktr0731/evans/tests/mock/usecase/mockport"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+)
+
+type testReporterMock struct {
+	reports []*port.TestReport
+}
+
+func (r *testReporterMock) Report(report *port.TestReport) error {
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func TestRunTests(t *testing.T) {
+	rpc := testentity.NewRPC()
+	builder := &mockport.DynamicBuilderMock{
+		NewMessageFunc: func(entity.Message) proto.Message { return &wrappers.StringValue{} },
+	}
+	newCase := func(req string, expect *port.TestExpectation) *port.TestCase {
+		return &port.TestCase{
+			Name:     "case",
+			Package:  "api",
+			Service:  "Example",
+			RPC:      rpc.Name(),
+			Requests: []json.RawMessage{json.RawMessage(req)},
+			Expect:   expect,
+		}
+	}
+
+	t.Run("passed", func(t *testing.T) {
+		reporter := &testReporterMock{}
+		params := &port.TestParams{
+			Suite: &port.TestSuite{
+				Cases: []*port.TestCase{
+					newCase(`"maho"`, &port.TestExpectation{
+						Responses: []json.RawMessage{json.RawMessage(`"maho"`)},
+						Fields:    []*port.FieldMatcher{{Regex: "^ma"}},
+					}),
+					newCase(`""`, &port.TestExpectation{Code: "InvalidArgument", Message: "value is required"}),
+				},
+			},
+			Reporters: []port.TestReporter{reporter},
+		}
+		_, err := RunTests(params, usecasetest.NewPresenter(), newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.NoError(t, err)
+		require.Len(t, reporter.reports, 1)
+		assert.Equal(t, 2, reporter.reports[0].Passed)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		params := &port.TestParams{
+			Suite: &port.TestSuite{
+				Cases: []*port.TestCase{
+					newCase(`"maho"`, &port.TestExpectation{Responses: []json.RawMessage{json.RawMessage(`"kurisu"`)}}),
+					newCase(`""`, nil),
+				},
+			},
+		}
+		_, err := RunTests(params, usecasetest.NewPresenter(), newSessionGRPCClient(t), builder, newSessionEnv(t, rpc))
+		require.Error(t, err)
+		terr, ok := err.(*TestError)
+		require.True(t, ok)
+		assert.Equal(t, 2, terr.Report.Failed)
+		assert.Equal(t, []string{`responses[0]: expected "kurisu", but got "maho"`}, terr.Report.Results[0].Failures)
+		assert.Equal(t, []string{`status code: expected OK, but got InvalidArgument ("value is required")`}, terr.Report.Results[1].Failures)
+	})
+}
+
+func Test_assertCall(t *testing.T) {
+	actual := &port.RecordedCall{
+		Responses: []json.RawMessage{
+			json.RawMessage(`{"id":"1234567890123","user":{"name":"maho","age":21},"items":[{"name":"a"},{"name":"b"}]}`),
+		},
+	}
+
+	cases := map[string]struct {
+		expect   *port.TestExpectation
+		failures int
+	}{
+		"subset": {expect: &port.TestExpectation{
+			Responses: []json.RawMessage{json.RawMessage(`{"id":1234567890123,"user":{"name":"maho"}}`)},
+		}},
+		"subset mismatch": {expect: &port.TestExpectation{
+			Responses: []json.RawMessage{json.RawMessage(`{"user":{"name":"kurisu","age":22},"items":[{"name":"a"}]}`)},
+		}, failures: 3},
+		"missing response": {expect: &port.TestExpectation{
+			Responses: []json.RawMessage{json.RawMessage(`{}`), json.RawMessage(`{}`)},
+		}, failures: 1},
+		"fields": {expect: &port.TestExpectation{
+			Fields: []*port.FieldMatcher{
+				{Path: "items.1.name", Equals: json.RawMessage(`"b"`)},
+				{Path: "user.age", Regex: `^2\d$`},
+				{Path: "user", Equals: json.RawMessage(`{"name":"maho"}`)},
+			},
+		}},
+		"fields mismatch": {expect: &port.TestExpectation{
+			Fields: []*port.FieldMatcher{
+				{Path: "items.2.name", Equals: json.RawMessage(`"c"`)},
+				{Path: "user.name", Regex: `^k`},
+				{Index: 1, Path: "id", Regex: `.*`},
+				{Path: "id", Regex: `(`},
+			},
+		}, failures: 4},
+		"unexpected code": {expect: &port.TestExpectation{Code: "NotFound"}, failures: 1},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			failures := assertCall(c.expect, actual)
+			assert.Len(t, failures, c.failures, "%v", failures)
+		})
+	}
+}
+
+func Test_assertCall_zeroValues(t *testing.T) {
+	res, err := marshalMessage(&spb.Status{})
+	require.NoError(t, err)
+	actual := &port.RecordedCall{Responses: []json.RawMessage{res}}
+
+	expect := &port.TestExpectation{
+		Responses: []json.RawMessage{json.RawMessage(`{"message":""}`)},
+		Fields: []*port.FieldMatcher{
+			{Path: "code", Equals: json.RawMessage(`0`)},
+			{Path: "details", Equals: json.RawMessage(`[]`)},
+		},
+	}
+	failures := assertCall(expect, actual)
+	assert.Empty(t, failures)
+}
+
+func Test_matchSubset_order(t *testing.T) {
+	var expected, actual interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"c":1,"a":{"z":1,"y":1},"b":1}`), &expected))
+	require.NoError(t, json.Unmarshal([]byte(`{"a":{}}`), &actual))
+
+	failures := matchSubset("$", expected, actual)
+	require.Len(t, failures, 4)
+	for i, prefix := range []string{"$.a.y:", "$.a.z:", "$.b:", "$.c:"} {
+		assert.True(t, strings.HasPrefix(failures[i], prefix), "%v", failures)
+	}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/logger"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/pkg/errors"
+)
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Save saves the last response as a variable which has the passed name.
+// The saved response can be referenced like the last response, e.g. $name.user.id.
+func Save(
+	params *port.SaveParams,
+	outputPort port.OutputPort,
+	e env.Environment,
+) (io.Reader, error) {
+	if !variableNamePattern.MatchString(params.Name) {
+		return nil, errors.Errorf("invalid variable name '%s': it must consist of letters, digits and '_'", params.Name)
+	}
+	if params.Name == env.LastResponseVariable {
+		return nil, errors.Errorf("'%s' is reserved for the last response", env.LastResponseVariable)
+	}
+	v, ok := e.Variable(env.LastResponseVariable)
+	if !ok {
+		return nil, errors.New("no response to save")
+	}
+	e.SetVariable(params.Name, v)
+	return outputPort.Save()
+}
+
+// variableOutputPort sets each response to the variable of the last response.
+type variableOutputPort struct {
+	port.OutputPort
+	env       env.Environment
+	marshaler *jsonpb.Marshaler
+}
+
+func (p *variableOutputPort) Call(res proto.Message) (io.Reader, error) {
+	if v, err := decodeMessage(res, p.marshaler); err != nil {
+		logger.Printf("failed to keep the last response: %s", err)
+	} else {
+		p.env.SetVariable(env.LastResponseVariable, v)
+	}
+	return p.OutputPort.Call(res)
+}
+
+// decodeMessage converts m to a decoded JSON value.
+// m is marshaled by marshaler so that field names and values are the same as formatted responses.
+// If marshaler is nil, the default options are used. See marshalMessage.
+// Numbers are decoded as json.Number to keep 64-bit integers.
+func decodeMessage(m proto.Message, marshaler *jsonpb.Marshaler) (interface{}, error) {
+	var (
+		b   json.RawMessage
+		err error
+	)
+	if marshaler != nil {
+		var s string
+		s, err = marshaler.MarshalToString(m)
+		b = json.RawMessage(s)
+	} else {
+		b, err = marshalMessage(m)
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
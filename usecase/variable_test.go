@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/ktr0731/evans/entity/env"
+	"github.com/ktr0731/evans/usecase/internal/usecasetest"
+	"github.com/ktr0731/evans/usecase/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave(t *testing.T) {
+	presenter := usecasetest.NewPresenter()
+
+	t.Run("normal", func(t *testing.T) {
+		e := env.New(nil, nil)
+		e.SetVariable(env.LastResponseVariable, map[string]interface{}{"id": "foo"})
+
+		_, err := Save(&port.SaveParams{Name: "user"}, presenter, e)
+		require.NoError(t, err)
+
+		v, ok := e.Variable("user")
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"id": "foo"}, v)
+	})
+
+	cases := map[string]string{
+		"no response":   "user",
+		"reserved name": env.LastResponseVariable,
+		"invalid name":  "foo.bar",
+	}
+	for name, varName := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := env.New(nil, nil)
+			if name != "no response" {
+				e.SetVariable(env.LastResponseVariable, "foo")
+			}
+			_, err := Save(&port.SaveParams{Name: varName}, presenter, e)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_variableOutputPort(t *testing.T) {
+	e := env.New(nil, nil)
+	p := &variableOutputPort{OutputPort: usecasetest.NewPresenter(), env: e}
+
+	_, err := p.Call(&wrappers.StringValue{Value: "foo"})
+	require.NoError(t, err)
+
+	v, ok := e.Variable(env.LastResponseVariable)
+	require.True(t, ok)
+	assert.Equal(t, "foo", v)
+}